@@ -85,10 +85,23 @@ func showHelp() {
 	fmt.Println("  process     Process input data")
 	fmt.Println("  analyze     Analyze data")
 	fmt.Println("  generate    Generate output")
+	fmt.Println("  conflicts   Report categories that compete for the same input")
+	fmt.Println("  test        Run declarative conversation tests against a bot")
+	fmt.Println("  diff        Replay recorded inputs through two bots and report changes")
+	fmt.Println("  intents     Evaluate the intent classifier (eval)")
+	fmt.Println("  schedule    List scheduled messages (list)")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  golem interactive                    # Start interactive mode")
 	fmt.Println("  golem load data/sample.aiml         # Load AIML file")
+	fmt.Println("  golem load --report data/           # Load directory and show duplicate categories")
+	fmt.Println("  golem load --duplicates error data/ # Fail on duplicate categories (first-wins, last-wins, error)")
+	fmt.Println("  golem load --languages bot/         # One knowledge base per language subdirectory (en/, es/, de/)")
+	fmt.Println("  golem conflicts --json data/        # Report conflicting categories with example inputs")
+	fmt.Println("  golem test bot/ tests/              # Run conversation tests (--junit out.xml for CI, --languages for multilingual bots)")
+	fmt.Println("  golem diff --old v1/ --new v2/ --inputs log.jsonl # Compare responses to recorded inputs")
+	fmt.Println("  golem intents eval bot/             # Cross-validated accuracy of intents/*.json (--folds 5)")
+	fmt.Println("  golem schedule list --store sessions/ # Pending scheduled messages of the saved sessions")
 	fmt.Println("  golem chat hello                    # Chat (requires loaded AIML)")
 	fmt.Println("  golem chat '<oob>SYSTEM INFO</oob>'  # Send OOB message")
 	fmt.Println("  golem session create                # Create session")
@@ -159,6 +172,14 @@ func runInteractiveMode(verbose bool) {
 func showInteractiveHelp() {
 	fmt.Println("Interactive Mode Commands:")
 	fmt.Println("  load <file>           Load AIML file")
+	fmt.Println("  load --report <dir>   Load and show merge report")
+	fmt.Println("  load --languages <dir> Load one knowledge base per language")
+	fmt.Println("  conflicts <dir>       Report conflicting categories")
+	fmt.Println("  test <bot> <tests>    Run conversation tests")
+	fmt.Println("  test --coverage <bot> <tests> Run tests and list unused categories")
+	fmt.Println("  diff --old <dir> --new <dir> --inputs <file> Compare two bots")
+	fmt.Println("  intents eval <dir>    Cross-validate the intent classifier")
+	fmt.Println("  schedule list [--store <dir>] List scheduled messages")
 	fmt.Println("  chat <message>        Chat with bot")
 	fmt.Println("  chat <oob>msg</oob>   Send OOB message")
 	fmt.Println("  session create [id]   Create new session")
@@ -19,6 +19,7 @@ import (
 type AIML struct {
 	Version    string
 	Categories []Category
+	Forms      []Form
 }
 
 // Category represents an AIML category (pattern-template pair)
@@ -28,6 +29,10 @@ type Category struct {
 	That      string
 	ThatIndex int // Index for that context (1-based, 0 means last response)
 	Topic     string
+	// SourceFile is the file the category was loaded from (empty for strings and learned categories)
+	SourceFile string
+	// SourceLine is the line of the <category> tag in the parsed source (0 when unknown)
+	SourceLine int
 }
 
 // SetCollection represents an ordered set (maintains insertion order while ensuring uniqueness)
@@ -58,6 +63,13 @@ type AIMLKnowledgeBase struct {
 	Arrays         map[string][]string                   // Arrays: arrayName -> []values
 	SetCollections map[string]*SetCollection             // SetCollections: setName -> ordered unique values
 	Substitutions  map[string]map[string]string          // Substitutions: substitutionName -> pattern -> replacement
+	Synonyms       map[string][]string                   // Synonyms: word -> synonyms, from .synonyms files
+	Antonyms       map[string][]string                   // Antonyms: word -> antonyms, from .antonyms files
+	Domains        map[string][]string                   // Domains: domainName -> words, from .domain files
+	Intents        []Intent                              // Intents: example utterances and srai targets, from intents/*.json
+	FAQ            []FAQPassage                          // FAQ: passages of the Markdown articles in faq/*.md
+	Forms          []Form                                // Forms: slot-filling forms, from forms/*.json and <form> declarations
+	MergeReport    *MergeReport                          // MergeReport: duplicate categories resolved while loading
 }
 
 // NewAIMLKnowledgeBase creates a new knowledge base
@@ -74,6 +86,9 @@ func NewAIMLKnowledgeBase() *AIMLKnowledgeBase {
 		Arrays:         make(map[string][]string),
 		SetCollections: make(map[string]*SetCollection),
 		Substitutions:  make(map[string]map[string]string),
+		Synonyms:       make(map[string][]string),
+		Antonyms:       make(map[string][]string),
+		Domains:        make(map[string][]string),
 	}
 }
 
@@ -128,23 +143,15 @@ func (g *Golem) aimlToKnowledgeBase(aiml *AIML) *AIMLKnowledgeBase {
 		Arrays:         make(map[string][]string),
 		SetCollections: make(map[string]*SetCollection),
 		Substitutions:  make(map[string]map[string]string),
+		Synonyms:       make(map[string][]string),
+		Antonyms:       make(map[string][]string),
+		Domains:        make(map[string][]string),
+		Forms:          aiml.Forms,
 	}
 
-	// Build pattern index
+	// Build pattern index (a unique key that includes pattern, that, topic, and that index)
 	for i := range kb.Categories {
-		pattern := NormalizePattern(kb.Categories[i].Pattern)
-		// Create a unique key that includes pattern, that, topic, and that index
-		key := pattern
-		if kb.Categories[i].That != "" {
-			key += "|THAT:" + NormalizePattern(kb.Categories[i].That)
-			if kb.Categories[i].ThatIndex != 0 {
-				key += fmt.Sprintf("|THATINDEX:%d", kb.Categories[i].ThatIndex)
-			}
-		}
-		if kb.Categories[i].Topic != "" {
-			key += "|TOPIC:" + strings.ToUpper(kb.Categories[i].Topic)
-		}
-		kb.Patterns[key] = &kb.Categories[i]
+		kb.Patterns[CategoryKey(kb.Categories[i])] = &kb.Categories[i]
 	}
 
 	return kb
@@ -164,13 +171,48 @@ func (g *Golem) mergeKnowledgeBases(kb1, kb2 *AIMLKnowledgeBase) (*AIMLKnowledge
 		Arrays:         make(map[string][]string),
 		SetCollections: make(map[string]*SetCollection),
 		Substitutions:  make(map[string]map[string]string),
+		Synonyms:       make(map[string][]string),
+		Antonyms:       make(map[string][]string),
+		Domains:        make(map[string][]string),
 	}
 
-	// Copy from first knowledge base
-	mergedKB.Categories = append(mergedKB.Categories, kb1.Categories...)
+	// Copy patterns from first knowledge base, then fold in the second one.
+	// Categories that share a key are resolved with the duplicate policy.
+	report := NewMergeReport(g.duplicatePolicy)
 	for pattern, category := range kb1.Patterns {
 		mergedKB.Patterns[pattern] = category
 	}
+	dropped := make(map[*Category]bool)
+	for pattern, category := range kb2.Patterns {
+		if existing, exists := mergedKB.Patterns[pattern]; exists && existing != category {
+			kept, lost, err := g.resolveDuplicateCategory(pattern, existing, category, report)
+			if err != nil {
+				return nil, err
+			}
+			dropped[lost] = true
+			mergedKB.Patterns[pattern] = kept
+			continue
+		}
+		mergedKB.Patterns[pattern] = category
+	}
+	report.sortOverrides()
+	// The reports of both sides come first, in load order
+	var earlier []CategoryOverride
+	for _, side := range []*AIMLKnowledgeBase{kb1, kb2} {
+		if side.MergeReport != nil {
+			report.Files = append(report.Files, side.MergeReport.Files...)
+			earlier = append(earlier, side.MergeReport.Overrides...)
+		}
+	}
+	report.Overrides = append(earlier, report.Overrides...)
+	mergedKB.MergeReport = report
+
+	// Copy from first knowledge base
+	for i := range kb1.Categories {
+		if !dropped[&kb1.Categories[i]] {
+			mergedKB.Categories = append(mergedKB.Categories, kb1.Categories[i])
+		}
+	}
 	for setName, members := range kb1.Sets {
 		mergedKB.Sets[setName] = members
 	}
@@ -198,11 +240,22 @@ func (g *Golem) mergeKnowledgeBases(kb1, kb2 *AIMLKnowledgeBase) (*AIMLKnowledge
 	for subName, subData := range kb1.Substitutions {
 		mergedKB.Substitutions[subName] = subData
 	}
+	mergeWordRelations(mergedKB.Synonyms, kb1.Synonyms)
+	mergeWordRelations(mergedKB.Antonyms, kb1.Antonyms)
+	mergeWordRelations(mergedKB.Domains, kb1.Domains)
+	mergedKB.Intents = mergeIntents(nil, kb1.Intents)
+	mergedKB.FAQ = append([]FAQPassage(nil), kb1.FAQ...)
+	mergedKB.Forms = mergeForms(nil, kb1.Forms)
 
 	// Merge from second knowledge base
-	mergedKB.Categories = append(mergedKB.Categories, kb2.Categories...)
-	for pattern, category := range kb2.Patterns {
-		mergedKB.Patterns[pattern] = category
+	for i := range kb2.Categories {
+		if !dropped[&kb2.Categories[i]] {
+			mergedKB.Categories = append(mergedKB.Categories, kb2.Categories[i])
+		}
+	}
+	// Index the merged copies, so the next merge recognises the categories it drops
+	for i := range mergedKB.Categories {
+		mergedKB.Patterns[CategoryKey(mergedKB.Categories[i])] = &mergedKB.Categories[i]
 	}
 	for setName, members := range kb2.Sets {
 		if mergedKB.Sets[setName] == nil {
@@ -262,6 +315,12 @@ func (g *Golem) mergeKnowledgeBases(kb1, kb2 *AIMLKnowledgeBase) (*AIMLKnowledge
 			mergedKB.Substitutions[subName][pattern] = replacement
 		}
 	}
+	mergeWordRelations(mergedKB.Synonyms, kb2.Synonyms)
+	mergeWordRelations(mergedKB.Antonyms, kb2.Antonyms)
+	mergeWordRelations(mergedKB.Domains, kb2.Domains)
+	mergedKB.Intents = mergeIntents(mergedKB.Intents, kb2.Intents)
+	mergedKB.FAQ = append(mergedKB.FAQ, kb2.FAQ...)
+	mergedKB.Forms = mergeForms(mergedKB.Forms, kb2.Forms)
 
 	return mergedKB, nil
 }
@@ -290,6 +349,7 @@ func (g *Golem) LoadAIML(filename string) (*AIMLKnowledgeBase, error) {
 	// Create knowledge base
 	kb := NewAIMLKnowledgeBase()
 	kb.Categories = aiml.Categories
+	kb.Forms = aiml.Forms
 
 	// Load default properties
 	err = g.loadDefaultProperties(kb)
@@ -297,12 +357,11 @@ func (g *Golem) LoadAIML(filename string) (*AIMLKnowledgeBase, error) {
 		return nil, fmt.Errorf("failed to load default properties: %v", err)
 	}
 
-	// Index patterns for fast lookup
+	// Index patterns for fast lookup, remembering where each category came from
 	for i := range aiml.Categories {
 		category := &aiml.Categories[i]
-		// Normalize pattern for storage
-		pattern := NormalizePattern(category.Pattern)
-		kb.Patterns[pattern] = category
+		category.SourceFile = filename
+		kb.Patterns[CategoryKey(*category)] = category
 	}
 
 	g.LogInfo("Loaded %d AIML categories", len(aiml.Categories))
@@ -349,6 +408,10 @@ func (g *Golem) LoadAIMLFromDirectory(dirPath string) (*AIMLKnowledgeBase, error
 
 	g.LogInfo("Found %d AIML files in directory", len(aimlFiles))
 
+	// Categories are merged in file order so the duplicate policy sees them in load order
+	report := NewMergeReport(g.duplicatePolicy)
+	merger := newCategoryMerger(g, report)
+
 	// Load each AIML file and merge into the knowledge base
 	for _, aimlFile := range aimlFiles {
 		g.LogInfo("Loading AIML file: %s", aimlFile)
@@ -360,16 +423,13 @@ func (g *Golem) LoadAIMLFromDirectory(dirPath string) (*AIMLKnowledgeBase, error
 			g.LogInfo("Warning: failed to load %s: %v", aimlFile, err)
 			continue
 		}
+		report.Files = append(report.Files, aimlFile)
 
 		// Merge the categories from this file into the merged knowledge base
-		for i := range kb.Categories {
-			category := &kb.Categories[i]
-			// Normalize pattern for storage
-			pattern := NormalizePattern(category.Pattern)
-
-			// Add category to merged knowledge base
-			mergedKB.Categories = append(mergedKB.Categories, *category)
-			mergedKB.Patterns[pattern] = category
+		for _, category := range kb.Categories {
+			if err := merger.add(category); err != nil {
+				return nil, err
+			}
 		}
 
 		// Merge sets
@@ -396,6 +456,15 @@ func (g *Golem) LoadAIMLFromDirectory(dirPath string) (*AIMLKnowledgeBase, error
 		for propName, propValue := range kb.Properties {
 			mergedKB.Properties[propName] = propValue
 		}
+
+		// Merge form declarations
+		mergedKB.Forms = mergeForms(mergedKB.Forms, kb.Forms)
+	}
+
+	merger.apply(mergedKB)
+	mergedKB.MergeReport = report
+	if report.HasOverrides() {
+		g.LogInfo("Resolved %d duplicate categories using %s policy", len(report.Overrides), report.Policy)
 	}
 
 	// Load map files from the same directory
@@ -434,6 +503,50 @@ func (g *Golem) LoadAIMLFromDirectory(dirPath string) (*AIMLKnowledgeBase, error
 		}
 	}
 
+	// Load synonym, antonym and domain files from the same directory
+	synonyms, err := g.LoadSynonymsFromDirectory(dirPath)
+	if err != nil {
+		g.LogInfo("Warning: failed to load synonyms from directory: %v", err)
+	} else {
+		mergeWordRelations(mergedKB.Synonyms, synonyms)
+	}
+	antonyms, err := g.LoadAntonymsFromDirectory(dirPath)
+	if err != nil {
+		g.LogInfo("Warning: failed to load antonyms from directory: %v", err)
+	} else {
+		mergeWordRelations(mergedKB.Antonyms, antonyms)
+	}
+	domains, err := g.LoadDomainsFromDirectory(dirPath)
+	if err != nil {
+		g.LogInfo("Warning: failed to load domains from directory: %v", err)
+	} else {
+		mergeWordRelations(mergedKB.Domains, domains)
+	}
+
+	// Load intent files from an intents subdirectory
+	intents, err := g.LoadIntentsFromDirectory(dirPath)
+	if err != nil {
+		g.LogInfo("Warning: failed to load intents from directory: %v", err)
+	} else {
+		mergedKB.Intents = mergeIntents(mergedKB.Intents, intents)
+	}
+
+	// Load Markdown FAQ articles from a faq subdirectory
+	passages, err := g.LoadFAQFromDirectory(dirPath)
+	if err != nil {
+		g.LogInfo("Warning: failed to load FAQ articles from directory: %v", err)
+	} else {
+		mergedKB.FAQ = append(mergedKB.FAQ, passages...)
+	}
+
+	// Load form files from a forms subdirectory
+	forms, err := g.LoadFormsFromDirectory(dirPath)
+	if err != nil {
+		g.LogInfo("Warning: failed to load forms from directory: %v", err)
+	} else {
+		mergedKB.Forms = mergeForms(mergedKB.Forms, forms)
+	}
+
 	// Load properties files from the same directory
 	properties, err := g.LoadPropertiesFromDirectory(dirPath)
 	if err != nil {
@@ -486,6 +599,16 @@ func (g *Golem) LoadMapFromFile(filename string) (map[string]string, error) {
 		return nil, fmt.Errorf("failed to read map file %s: %v", filename, err)
 	}
 
+	// Text, CSV and TSV maps hold key:value lines or key,value rows
+	if format := detectResourceFormat(filename, content); format != ResourceFormatJSON {
+		result, err := parseResourcePairs(filename, content, format, ":")
+		if err != nil {
+			return nil, err
+		}
+		g.LogInfo("Loaded %d map entries from %s (%s)", len(result), filename, format)
+		return result, nil
+	}
+
 	// Parse JSON array
 	var mapEntries []map[string]string
 	err = json.Unmarshal(content, &mapEntries)
@@ -527,7 +650,7 @@ func (g *Golem) LoadMapsFromDirectory(dirPath string) (map[string]map[string]str
 		}
 
 		// Check if it's a .map file
-		if !d.IsDir() && strings.HasSuffix(strings.ToLower(path), ".map") {
+		if !d.IsDir() && isResourceFile(path, ".map") {
 			mapFiles = append(mapFiles, path)
 		}
 
@@ -553,12 +676,12 @@ func (g *Golem) LoadMapsFromDirectory(dirPath string) (map[string]map[string]str
 		mapData, err := g.LoadMapFromFile(mapFile)
 		if err != nil {
 			// Log the error but continue with other files
-			g.LogInfo("Warning: failed to load %s: %v", mapFile, err)
+			g.LogWarn("Failed to load %s: %v", mapFile, err)
 			continue
 		}
 
-		// Use the filename (without extension) as the map name
-		mapName := strings.TrimSuffix(filepath.Base(mapFile), filepath.Ext(mapFile))
+		// Use the filename (without resource and format extensions) as the map name
+		mapName, _ := resourceName(mapFile, ".map")
 		allMaps[mapName] = mapData
 	}
 
@@ -577,6 +700,16 @@ func (g *Golem) LoadSetFromFile(filename string) ([]string, error) {
 		return nil, fmt.Errorf("failed to read set file %s: %v", filename, err)
 	}
 
+	// Text, CSV and TSV sets hold one member per line
+	if format := detectResourceFormat(filename, content); format != ResourceFormatJSON {
+		setMembers, err := parseResourceMembers(filename, content, format)
+		if err != nil {
+			return nil, err
+		}
+		g.LogInfo("Loaded %d set members from %s (%s)", len(setMembers), filename, format)
+		return setMembers, nil
+	}
+
 	// Parse JSON array
 	var setMembers []string
 	err = json.Unmarshal(content, &setMembers)
@@ -604,7 +737,7 @@ func (g *Golem) LoadSetsFromDirectory(dirPath string) (map[string][]string, erro
 		}
 
 		// Check if it's a .set file
-		if !d.IsDir() && strings.HasSuffix(strings.ToLower(path), ".set") {
+		if !d.IsDir() && isResourceFile(path, ".set") {
 			setFiles = append(setFiles, path)
 		}
 
@@ -630,12 +763,12 @@ func (g *Golem) LoadSetsFromDirectory(dirPath string) (map[string][]string, erro
 		setMembers, err := g.LoadSetFromFile(setFile)
 		if err != nil {
 			// Log the error but continue with other files
-			g.LogInfo("Warning: failed to load %s: %v", setFile, err)
+			g.LogWarn("Failed to load %s: %v", setFile, err)
 			continue
 		}
 
-		// Use the filename (without extension) as the set name
-		setName := strings.TrimSuffix(filepath.Base(setFile), filepath.Ext(setFile))
+		// Use the filename (without resource and format extensions) as the set name
+		setName, _ := resourceName(setFile, ".set")
 		allSets[setName] = setMembers
 	}
 
@@ -654,6 +787,17 @@ func (g *Golem) LoadSubstitutionFromFile(filename string) (map[string]string, er
 		return nil, fmt.Errorf("failed to read substitution file %s: %v", filename, err)
 	}
 
+	// Text, CSV and TSV substitutions hold pattern:replacement lines or pattern,replacement rows
+	if format := detectResourceFormat(filename, content); format != ResourceFormatJSON {
+		result, err := parseResourcePairs(filename, content, format, ":")
+		if err != nil {
+			return nil, err
+		}
+		result = wholeWordSubstitutions(result)
+		g.LogInfo("Loaded %d substitution rules from %s (%s)", len(result), filename, format)
+		return result, nil
+	}
+
 	// Parse JSON array of [pattern, replacement] pairs
 	var substitutionPairs [][]string
 	err = json.Unmarshal(content, &substitutionPairs)
@@ -700,7 +844,7 @@ func (g *Golem) LoadSubstitutionsFromDirectory(dirPath string) (map[string]map[s
 		}
 
 		// Check if it's a .substitution file
-		if !d.IsDir() && strings.HasSuffix(strings.ToLower(path), ".substitution") {
+		if !d.IsDir() && isResourceFile(path, ".substitution") {
 			substitutionFiles = append(substitutionFiles, path)
 		}
 
@@ -726,12 +870,12 @@ func (g *Golem) LoadSubstitutionsFromDirectory(dirPath string) (map[string]map[s
 		substitutionData, err := g.LoadSubstitutionFromFile(substitutionFile)
 		if err != nil {
 			// Log the error but continue with other files
-			g.LogInfo("Warning: failed to load %s: %v", substitutionFile, err)
+			g.LogWarn("Failed to load %s: %v", substitutionFile, err)
 			continue
 		}
 
-		// Use the filename (without extension) as the substitution name
-		substitutionName := strings.TrimSuffix(filepath.Base(substitutionFile), filepath.Ext(substitutionFile))
+		// Use the filename (without resource and format extensions) as the substitution name
+		substitutionName, _ := resourceName(substitutionFile, ".substitution")
 		allSubstitutions[substitutionName] = substitutionData
 	}
 
@@ -750,6 +894,16 @@ func (g *Golem) LoadPropertiesFromFile(filename string) (map[string]string, erro
 		return nil, fmt.Errorf("failed to read properties file %s: %v", filename, err)
 	}
 
+	// Text, CSV and TSV properties hold key:value (or key=value) lines or key,value rows
+	if format := detectResourceFormat(filename, content); format != ResourceFormatJSON {
+		result, err := parseResourcePairs(filename, content, format, ":=")
+		if err != nil {
+			return nil, err
+		}
+		g.LogInfo("Loaded %d properties from %s (%s)", len(result), filename, format)
+		return result, nil
+	}
+
 	// Parse JSON array of [key, value] pairs
 	var propertyPairs [][]string
 	err = json.Unmarshal(content, &propertyPairs)
@@ -796,7 +950,7 @@ func (g *Golem) LoadPropertiesFromDirectory(dirPath string) (map[string]map[stri
 		}
 
 		// Check if it's a .properties file
-		if !d.IsDir() && strings.HasSuffix(strings.ToLower(path), ".properties") {
+		if !d.IsDir() && isResourceFile(path, ".properties") {
 			propertiesFiles = append(propertiesFiles, path)
 		}
 
@@ -822,12 +976,12 @@ func (g *Golem) LoadPropertiesFromDirectory(dirPath string) (map[string]map[stri
 		propertiesData, err := g.LoadPropertiesFromFile(propertiesFile)
 		if err != nil {
 			// Log the error but continue with other files
-			g.LogInfo("Warning: failed to load %s: %v", propertiesFile, err)
+			g.LogWarn("Failed to load %s: %v", propertiesFile, err)
 			continue
 		}
 
-		// Use the filename (without extension) as the properties name
-		propertiesName := strings.TrimSuffix(filepath.Base(propertiesFile), filepath.Ext(propertiesFile))
+		// Use the filename (without resource and format extensions) as the properties name
+		propertiesName, _ := resourceName(propertiesFile, ".properties")
 		allProperties[propertiesName] = propertiesData
 	}
 
@@ -951,22 +1105,41 @@ func (g *Golem) parseAIML(content string) (*AIML, error) {
 	}
 
 	// Find all categories using tag-aware parsing
-	categoryContents := g.extractAllTagContents(content, "category")
+	// Comments and the declaration are removed within their lines, so offsets still map to source lines
+	categoryContents, categoryOffsets := g.extractAllTagContentsWithOffsets(content, "category")
 
-	for _, categoryContent := range categoryContents {
+	for i, categoryContent := range categoryContents {
 		category, err := g.parseCategory(categoryContent)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse category: %v", err)
 		}
+		category.SourceLine = strings.Count(content[:categoryOffsets[i]], "\n") + 1
+		// Patterns and topics go through the same Unicode stage and tokenizer as input
+		category.Pattern = g.segmentPattern(g.normalizeUnicode(category.Pattern))
+		category.Topic = g.segmentPattern(g.normalizeUnicode(category.Topic))
 		aiml.Categories = append(aiml.Categories, category)
 	}
 
+	// Form declarations sit beside the categories
+	forms, err := g.parseForms(content)
+	if err != nil {
+		return nil, err
+	}
+	aiml.Forms = forms
+
 	return aiml, nil
 }
 
 // extractAllTagContents extracts all occurrences of a tag using stack-based parsing
 func (g *Golem) extractAllTagContents(input string, tagName string) []string {
+	results, _ := g.extractAllTagContentsWithOffsets(input, tagName)
+	return results
+}
+
+// extractAllTagContentsWithOffsets extracts all occurrences of a tag along with the offset of each opening tag
+func (g *Golem) extractAllTagContentsWithOffsets(input string, tagName string) ([]string, []int) {
 	var results []string
+	var offsets []int
 	openPattern := fmt.Sprintf("<%s", tagName)
 	closePattern := fmt.Sprintf("</%s>", tagName)
 
@@ -1015,6 +1188,7 @@ func (g *Golem) extractAllTagContents(input string, tagName string) []string {
 					// Found the matching closing tag
 					content := input[contentStart:i]
 					results = append(results, content)
+					offsets = append(offsets, openIdx)
 					i += len(closePattern)
 					break
 				}
@@ -1026,7 +1200,7 @@ func (g *Golem) extractAllTagContents(input string, tagName string) []string {
 		}
 	}
 
-	return results
+	return results, offsets
 }
 
 // parseCategory parses a single category using tag-aware parsing
@@ -1045,7 +1219,8 @@ func (g *Golem) parseCategory(content string) (Category, error) {
 
 	// Extract that (optional) with index support using tag-aware parsing
 	if thatContent, found := g.extractTagContentWithAttributes(content, "that"); found {
-		category.That = strings.TrimSpace(thatContent.Content)
+		// The that pattern goes through the Unicode stage and tokenizer before it is validated
+		category.That = g.segmentPattern(g.normalizeUnicode(strings.TrimSpace(thatContent.Content)))
 
 		// Parse index attribute if provided
 		if indexStr, hasIndex := thatContent.Attributes["index"]; hasIndex {
@@ -1438,34 +1613,7 @@ func (kb *AIMLKnowledgeBase) MatchPatternWithTopicAndThatIndexOriginalCached(g *
 		// Try enhanced matching with sets first
 		matched, _ := matchPatternWithWildcardsAndSetsCasePreservingCached(g, input, originalInput, basePattern, kb)
 		if matched && thatMatched {
-			priority := calculatePatternPriority(basePattern)
-
-			// Boost priority for patterns with that context
-			if category.That != "" {
-				// Calculate that pattern priority
-				thatPriority := calculateThatPatternPriority(category.That)
-				priority.Priority += thatPriority
-
-				// Additional boost for exact that matches
-				if normalizedThat != "" && category.That == normalizedThat {
-					priority.Priority += 100 // Extra boost for exact that match
-				}
-				// Additional boost for that patterns with wildcards (more specific)
-				if strings.Contains(category.That, "*") || strings.Contains(category.That, "_") ||
-					strings.Contains(category.That, "^") || strings.Contains(category.That, "#") ||
-					strings.Contains(category.That, "$") {
-					priority.Priority += 50 // Boost for wildcard that patterns
-				}
-				// Additional boost for patterns with specific indices (more specific than index 0)
-				if category.ThatIndex != 0 {
-					priority.Priority += 200 // Extra boost for specific index patterns
-				}
-			}
-
-			// Boost priority for patterns with topic context
-			if category.Topic != "" {
-				priority.Priority += 100 // Medium boost for topic context
-			}
+			priority := categoryMatchPriority(category, basePattern, normalizedThat)
 
 			matchingPatterns = append(matchingPatterns, PatternPriority{
 				Pattern:          basePattern,
@@ -1493,6 +1641,11 @@ func (kb *AIMLKnowledgeBase) MatchPatternWithTopicAndThatIndexOriginalCached(g *
 		// Also normalize the pattern to lowercase for case-insensitive matching
 		normalizedPattern := strings.ToLower(bestMatch.Pattern)
 		_, inputWildcards := matchPatternWithWildcardsAndSetsCasePreservingCached(g, casePreservingInput, originalInput, normalizedPattern, kb)
+		if inputWildcards == nil {
+			// Input that only matches after Unicode normalization, like "café" for CAFE *,
+			// takes its captures from the normalized match
+			_, inputWildcards = matchPatternWithWildcardsAndSetsCasePreservingCached(g, input, originalInput, bestMatch.Pattern, kb)
+		}
 		if inputWildcards == nil {
 			_, inputWildcards = matchPatternWithWildcards(casePreservingInput, normalizedPattern)
 		}
@@ -1549,6 +1702,40 @@ func (kb *AIMLKnowledgeBase) MatchPatternWithTopic(input string, topic string) (
 	return kb.MatchPatternWithTopicAndThat(input, topic, "")
 }
 
+// categoryMatchPriority calculates the priority of a matched category, including that and topic boosts
+func categoryMatchPriority(category *Category, basePattern string, normalizedThat string) PatternPriorityInfo {
+	priority := calculatePatternPriority(basePattern)
+
+	// Boost priority for patterns with that context
+	if category.That != "" {
+		// Calculate that pattern priority
+		thatPriority := calculateThatPatternPriority(category.That)
+		priority.Priority += thatPriority
+
+		// Additional boost for exact that matches
+		if normalizedThat != "" && category.That == normalizedThat {
+			priority.Priority += 100 // Extra boost for exact that match
+		}
+		// Additional boost for that patterns with wildcards (more specific)
+		if strings.Contains(category.That, "*") || strings.Contains(category.That, "_") ||
+			strings.Contains(category.That, "^") || strings.Contains(category.That, "#") ||
+			strings.Contains(category.That, "$") {
+			priority.Priority += 50 // Boost for wildcard that patterns
+		}
+		// Additional boost for patterns with specific indices (more specific than index 0)
+		if category.ThatIndex != 0 {
+			priority.Priority += 200 // Extra boost for specific index patterns
+		}
+	}
+
+	// Boost priority for patterns with topic context
+	if category.Topic != "" {
+		priority.Priority += 100 // Medium boost for topic context
+	}
+
+	return priority
+}
+
 // PatternPriorityInfo contains calculated priority information
 type PatternPriorityInfo struct {
 	Priority         int
@@ -1716,9 +1903,20 @@ func matchPatternWithWildcardsAndSetsCasePreserving(normalizedInput, originalInp
 
 // matchPatternWithWildcardsAndSetsCasePreservingCached matches input against a pattern with wildcards and sets with caching support
 func matchPatternWithWildcardsAndSetsCasePreservingCached(g *Golem, normalizedInput, originalInput, pattern string, kb *AIMLKnowledgeBase) (bool, map[string]string) {
+	// Provider sets change without the knowledge base changing, so their matches are not cached
+	if g.usesSetProvider(pattern) {
+		return matchPatternWithWildcardsAndSetsCasePreservingInternal(g, normalizedInput, originalInput, pattern, kb)
+	}
+
+	// Captures come from the original input, so it is part of the cache key
+	cacheInput := normalizedInput
+	if originalInput != normalizedInput {
+		cacheInput += "\x00" + originalInput
+	}
+
 	// Check cache first
 	if g != nil && g.patternMatchingCache != nil {
-		if result, found := g.patternMatchingCache.GetWildcardMatch(normalizedInput, pattern); found {
+		if result, found := g.patternMatchingCache.GetWildcardMatch(cacheInput, pattern); found {
 			return result.Matched, result.Wildcards
 		}
 	}
@@ -1734,7 +1932,7 @@ func matchPatternWithWildcardsAndSetsCasePreservingCached(g *Golem, normalizedIn
 			Pattern:   pattern,
 			Input:     normalizedInput,
 		}
-		g.patternMatchingCache.SetWildcardMatch(normalizedInput, pattern, result)
+		g.patternMatchingCache.SetWildcardMatch(cacheInput, pattern, result)
 	}
 
 	return matched, wildcards
@@ -1763,6 +1961,9 @@ func matchPatternWithWildcardsAndSetsCasePreservingInternal(g *Golem, normalized
 	if matches == nil {
 		return false, nil
 	}
+	if !g.checkProviderSetGroups(re, matches) {
+		return false, nil
+	}
 
 	// First extract wildcards from normalized input (fallback/default behavior)
 	starIndex := 1
@@ -1812,6 +2013,9 @@ func matchPatternWithWildcardsAndSetsCasePreservingInternal(g *Golem, normalized
 				}
 			}
 		}
+
+		// Set members and wildcards the passes above could not map back are taken from the user's text
+		restoreCaptureText(re, normalizedInput, originalInput, wildcards, g)
 	}
 	return true, wildcards
 }
@@ -1890,45 +2094,9 @@ func patternToRegexWithSetsCached(g *Golem, pattern string, kb *AIMLKnowledgeBas
 		}
 		setName := strings.ToUpper(strings.TrimSpace(matches[1]))
 
-		// Check cache first
-		if g != nil && g.patternMatchingCache != nil {
-			if regex, found := g.patternMatchingCache.GetSetRegex(setName, kb.Sets[setName]); found {
-				return regex
-			}
-		}
-
-		if len(kb.Sets[setName]) > 0 {
-			// Create regex alternation for set members
-			var alternatives []string
-			for _, member := range kb.Sets[setName] {
-				// Escape only specific regex characters, not the pipe
-				upperMember := strings.ToUpper(member)
-				// Escape characters that have special meaning in regex, but not |
-				escaped := strings.ReplaceAll(upperMember, "(", "\\(")
-				escaped = strings.ReplaceAll(escaped, ")", "\\)")
-				escaped = strings.ReplaceAll(escaped, "[", "\\[")
-				escaped = strings.ReplaceAll(escaped, "]", "\\]")
-				escaped = strings.ReplaceAll(escaped, "{", "\\{")
-				escaped = strings.ReplaceAll(escaped, "}", "\\}")
-				escaped = strings.ReplaceAll(escaped, "^", "\\^")
-				escaped = strings.ReplaceAll(escaped, "$", "\\$")
-				escaped = strings.ReplaceAll(escaped, ".", "\\.")
-				escaped = strings.ReplaceAll(escaped, "+", "\\+")
-				escaped = strings.ReplaceAll(escaped, "?", "\\?")
-				escaped = strings.ReplaceAll(escaped, "*", "\\*")
-				escaped = strings.ReplaceAll(escaped, "-", "\\-")
-				escaped = strings.ReplaceAll(escaped, "@", "\\@")
-				// Don't escape | as it's needed for alternation
-				alternatives = append(alternatives, escaped)
-			}
-			regex := "(" + strings.Join(alternatives, "|") + ")"
-
-			// Cache the result
-			if g != nil && g.patternMatchingCache != nil {
-				g.patternMatchingCache.SetSetRegex(setName, kb.Sets[setName], regex)
-			}
-
-			return regex
+		// Provider, loaded and built-in sets are expanded once the rest of the pattern is escaped
+		if g.hasSet(kb, setName) {
+			return setPlaceholder(setName)
 		}
 		// Fallback to wildcard if set not found
 		return "([^\\s]*)"
@@ -2001,7 +2169,7 @@ func patternToRegexWithSetsCached(g *Golem, pattern string, kb *AIMLKnowledgeBas
 		}
 	}
 
-	return "^" + result.String() + "$"
+	return "^" + g.expandSetPlaceholders(result.String(), kb) + "$"
 }
 
 // findMatchingParen finds the matching closing parenthesis for an opening parenthesis
@@ -2219,6 +2387,17 @@ func (g *Golem) processPerson2TagsWithContext(template string, ctx *VariableCont
 
 // SubstitutePronouns performs pronoun substitution for person tags
 func (g *Golem) SubstitutePronouns(text string) string {
+	// A loaded person.substitution replaces the built-in table
+	if substitutions := g.loadedSubstitution(SubstitutionPerson); substitutions != nil {
+		result := applyWordSubstitutions(text, substitutions)
+		g.LogInfo("Person substitution: '%s' -> '%s'", text, result)
+		return result
+	}
+	// Languages other than English have their own built-in table
+	if table := builtinPronounTable(g.language, SubstitutionPerson); table != nil {
+		return applyWordSubstitutions(text, table)
+	}
+
 	// Comprehensive pronoun mapping for first/second person substitution
 	pronounMap := map[string]string{
 		// First person to second person
@@ -2341,6 +2520,17 @@ func (g *Golem) fixVerbAgreement(text string) string {
 
 // SubstitutePronouns2 performs first-to-third person pronoun substitution for person2 tags
 func (g *Golem) SubstitutePronouns2(text string) string {
+	// A loaded person2.substitution replaces the built-in table
+	if substitutions := g.loadedSubstitution(SubstitutionPerson2); substitutions != nil {
+		result := applyWordSubstitutions(text, substitutions)
+		g.LogInfo("Person2 substitution: '%s' -> '%s'", text, result)
+		return result
+	}
+	// Languages other than English have their own built-in table
+	if table := builtinPronounTable(g.language, SubstitutionPerson2); table != nil {
+		return applyWordSubstitutions(text, table)
+	}
+
 	// Comprehensive pronoun mapping for first-to-third person substitution
 	pronounMap := map[string]string{
 		// First person to third person (neutral/they)
@@ -2441,6 +2631,17 @@ func (g *Golem) fixVerbAgreement2(text string) string {
 
 // SubstituteGenderPronouns performs gender-based pronoun substitution for gender tags
 func (g *Golem) SubstituteGenderPronouns(text string) string {
+	// A loaded gender.substitution replaces the built-in table
+	if substitutions := g.loadedSubstitution(SubstitutionGender); substitutions != nil {
+		result := applyWordSubstitutions(text, substitutions)
+		g.LogInfo("Gender substitution: '%s' -> '%s'", text, result)
+		return result
+	}
+	// Languages other than English have their own built-in table
+	if table := builtinPronounTable(g.language, SubstitutionGender); table != nil {
+		return applyWordSubstitutions(text, table)
+	}
+
 	// Split text into words for more precise substitution
 	words := strings.Fields(text)
 	result := make([]string, len(words))
@@ -2551,6 +2752,8 @@ func (g *Golem) processSRAITagsWithContext(template string, ctx *VariableContext
 				category, wildcards, err := g.aimlKB.MatchPattern(sraiContent)
 				g.LogInfo("SRAI pattern match: content='%s', err=%v, category=%v, wildcards=%v", sraiContent, err, category != nil, wildcards)
 				if err == nil && category != nil {
+					g.recordCategoryHit(category)
+
 					// Create a new context with incremented recursion depth
 					newCtx := &VariableContext{
 						LocalVars:      ctx.LocalVars,
@@ -3307,6 +3510,133 @@ func (g *Golem) pluralizeText(input string) string {
 	return strings.Join(pluralizedWords, " ")
 }
 
+// irregularPlurals maps singular words to plurals that do not follow the suffix rules
+var irregularPlurals = map[string]string{
+	"child":       "children",
+	"person":      "people",
+	"man":         "men",
+	"woman":       "women",
+	"foot":        "feet",
+	"tooth":       "teeth",
+	"mouse":       "mice",
+	"goose":       "geese",
+	"ox":          "oxen",
+	"sheep":       "sheep",
+	"deer":        "deer",
+	"fish":        "fish",
+	"moose":       "moose",
+	"series":      "series",
+	"species":     "species",
+	"crisis":      "crises",
+	"thesis":      "theses",
+	"analysis":    "analyses",
+	"basis":       "bases",
+	"diagnosis":   "diagnoses",
+	"oasis":       "oases",
+	"parenthesis": "parentheses",
+	"synopsis":    "synopses",
+	"cactus":      "cacti",
+	"fungus":      "fungi",
+	"nucleus":     "nuclei",
+	"stimulus":    "stimuli",
+	"syllabus":    "syllabi",
+	"alumnus":     "alumni",
+	"radius":      "radii",
+	"focus":       "foci",
+	"appendix":    "appendices",
+	"index":       "indices",
+	"matrix":      "matrices",
+	"vertex":      "vertices",
+	"vortex":      "vortices",
+	"corpus":      "corpora",
+	"genus":       "genera",
+	"opus":        "opera",
+	"stratum":     "strata",
+	"datum":       "data",
+	"medium":      "media",
+	"memorandum":  "memoranda",
+	"referendum":  "referenda",
+	"agenda":      "agenda",
+	"curriculum":  "curricula",
+	"maximum":     "maxima",
+	"minimum":     "minima",
+	"optimum":     "optima",
+	"quantum":     "quanta",
+	"spectrum":    "spectra",
+	"forum":       "fora",
+	"stadium":     "stadia",
+	"aquarium":    "aquaria",
+	"planetarium": "planetaria",
+	"sanitarium":  "sanitaria",
+	"solarium":    "solaria",
+	"terrarium":   "terraria",
+	"vivarium":    "vivaria",
+	"atrium":      "atria",
+	"auditorium":  "auditoria",
+	"gymnasium":   "gymnasia",
+	"emporium":    "emporia",
+	"crematorium": "crematoria",
+	"laboratory":  "laboratories",
+	"library":     "libraries",
+	"factory":     "factories",
+	"story":       "stories",
+	"country":     "countries",
+	"city":        "cities",
+	"baby":        "babies",
+	"lady":        "ladies",
+	"party":       "parties",
+	"company":     "companies",
+	"family":      "families",
+	"army":        "armies",
+	"enemy":       "enemies",
+	"monkey":      "monkeys",
+	"key":         "keys",
+	"toy":         "toys",
+	"boy":         "boys",
+	"day":         "days",
+	"way":         "ways",
+	"play":        "plays",
+	"stay":        "stays",
+	"say":         "says",
+	"buy":         "buys",
+	"guy":         "guys",
+	"cry":         "cries",
+	"fly":         "flies",
+	"try":         "tries",
+	"spy":         "spies",
+	"sky":         "skies",
+	"dry":         "dries",
+	"shy":         "shies",
+	"worry":       "worries",
+	"hurry":       "hurries",
+	"carry":       "carries",
+	"marry":       "marries",
+	"study":       "studies",
+	"apply":       "applies",
+	"reply":       "replies",
+	"supply":      "supplies",
+	"multiply":    "multiplies",
+	"identify":    "identifies",
+	"classify":    "classifies",
+	"justify":     "justifies",
+	"purify":      "purifies",
+	"amplify":     "amplifies",
+	"simplify":    "simplifies",
+	"beautify":    "beautifies",
+	"diversify":   "diversifies",
+	"intensify":   "intensifies",
+	"magnify":     "magnifies",
+	"modify":      "modifies",
+	"notify":      "notifies",
+	"qualify":     "qualifies",
+	"ratify":      "ratifies",
+	"rectify":     "rectifies",
+	"satisfy":     "satisfies",
+	"specify":     "specifies",
+	"testify":     "testifies",
+	"verify":      "verifies",
+}
+
 // pluralizeWord converts a single word to its plural form
 func (g *Golem) pluralizeWord(word string) string {
 	if len(word) == 0 {
@@ -3321,133 +3651,6 @@ func (g *Golem) pluralizeWord(word string) string {
 		return word // Return original word with preserved case
 	}
 
-	// Handle irregular plurals
-	irregularPlurals := map[string]string{
-		"child":       "children",
-		"person":      "people",
-		"man":         "men",
-		"woman":       "women",
-		"foot":        "feet",
-		"tooth":       "teeth",
-		"mouse":       "mice",
-		"goose":       "geese",
-		"ox":          "oxen",
-		"sheep":       "sheep",
-		"deer":        "deer",
-		"fish":        "fish",
-		"moose":       "moose",
-		"series":      "series",
-		"species":     "species",
-		"crisis":      "crises",
-		"thesis":      "theses",
-		"analysis":    "analyses",
-		"basis":       "bases",
-		"diagnosis":   "diagnoses",
-		"oasis":       "oases",
-		"parenthesis": "parentheses",
-		"synopsis":    "synopses",
-		"cactus":      "cacti",
-		"fungus":      "fungi",
-		"nucleus":     "nuclei",
-		"stimulus":    "stimuli",
-		"syllabus":    "syllabi",
-		"alumnus":     "alumni",
-		"radius":      "radii",
-		"focus":       "foci",
-		"appendix":    "appendices",
-		"index":       "indices",
-		"matrix":      "matrices",
-		"vertex":      "vertices",
-		"vortex":      "vortices",
-		"corpus":      "corpora",
-		"genus":       "genera",
-		"opus":        "opera",
-		"stratum":     "strata",
-		"datum":       "data",
-		"medium":      "media",
-		"memorandum":  "memoranda",
-		"referendum":  "referenda",
-		"agenda":      "agenda",
-		"curriculum":  "curricula",
-		"maximum":     "maxima",
-		"minimum":     "minima",
-		"optimum":     "optima",
-		"quantum":     "quanta",
-		"spectrum":    "spectra",
-		"forum":       "fora",
-		"stadium":     "stadia",
-		"aquarium":    "aquaria",
-		"planetarium": "planetaria",
-		"sanitarium":  "sanitaria",
-		"solarium":    "solaria",
-		"terrarium":   "terraria",
-		"vivarium":    "vivaria",
-		"atrium":      "atria",
-		"auditorium":  "auditoria",
-		"gymnasium":   "gymnasia",
-		"emporium":    "emporia",
-		"crematorium": "crematoria",
-		"laboratory":  "laboratories",
-		"library":     "libraries",
-		"factory":     "factories",
-		"story":       "stories",
-		"country":     "countries",
-		"city":        "cities",
-		"baby":        "babies",
-		"lady":        "ladies",
-		"party":       "parties",
-		"company":     "companies",
-		"family":      "families",
-		"army":        "armies",
-		"enemy":       "enemies",
-		"monkey":      "monkeys",
-		"key":         "keys",
-		"toy":         "toys",
-		"boy":         "boys",
-		"day":         "days",
-		"way":         "ways",
-		"play":        "plays",
-		"stay":        "stays",
-		"say":         "says",
-		"buy":         "buys",
-		"guy":         "guys",
-		"cry":         "cries",
-		"fly":         "flies",
-		"try":         "tries",
-		"spy":         "spies",
-		"sky":         "skies",
-		"dry":         "dries",
-		"shy":         "shies",
-		"worry":       "worries",
-		"hurry":       "hurries",
-		"carry":       "carries",
-		"marry":       "marries",
-		"study":       "studies",
-		"apply":       "applies",
-		"reply":       "replies",
-		"supply":      "supplies",
-		"multiply":    "multiplies",
-		"identify":    "identifies",
-		"classify":    "classifies",
-		"justify":     "justifies",
-		"purify":      "purifies",
-		"amplify":     "amplifies",
-		"simplify":    "simplifies",
-		"beautify":    "beautifies",
-		"diversify":   "diversifies",
-		"intensify":   "intensifies",
-		"magnify":     "magnifies",
-		"modify":      "modifies",
-		"notify":      "notifies",
-		"qualify":     "qualifies",
-		"ratify":      "ratifies",
-		"rectify":     "rectifies",
-		"satisfy":     "satisfies",
-		"specify":     "specifies",
-		"testify":     "testifies",
-		"verify":      "verifies",
-	}
-
 	// Check for irregular plurals
 	if plural, exists := irregularPlurals[lowerWord]; exists {
 		// Preserve original case
@@ -3593,11 +3796,11 @@ func (g *Golem) processShuffleTagsWithContext(template string, ctx *VariableCont
 				if cached, found := g.templateTagProcessingCache.GetProcessedTag("shuffle", content, ctx); found {
 					processedContent = cached
 				} else {
-					processedContent = g.shuffleText(content)
+					processedContent = g.shuffleText(content, ctx)
 					g.templateTagProcessingCache.SetProcessedTag("shuffle", content, processedContent, ctx)
 				}
 			} else {
-				processedContent = g.shuffleText(content)
+				processedContent = g.shuffleText(content, ctx)
 			}
 
 			g.LogDebug("Shuffle tag: '%s' -> '%s'", match[1], processedContent)
@@ -3611,7 +3814,7 @@ func (g *Golem) processShuffleTagsWithContext(template string, ctx *VariableCont
 }
 
 // shuffleText randomly shuffles the order of words in the text
-func (g *Golem) shuffleText(input string) string {
+func (g *Golem) shuffleText(input string, ctx *VariableContext) string {
 	// Split into words
 	words := strings.Fields(input)
 	if len(words) <= 1 {
@@ -3625,7 +3828,7 @@ func (g *Golem) shuffleText(input string) string {
 	// Shuffle the words using Fisher-Yates algorithm
 	for i := len(shuffledWords) - 1; i > 0; i-- {
 		// Generate a random index between 0 and i (inclusive)
-		j := g.randomInt(i + 1)
+		j := g.randomIntForContext(ctx, i+1)
 		// Swap words at positions i and j
 		shuffledWords[i], shuffledWords[j] = shuffledWords[j], shuffledWords[i]
 	}
@@ -4216,6 +4419,19 @@ func (g *Golem) uniqueText(content string, delimiter string) string {
 	return strings.Join(uniqueElements, delimiter)
 }
 
+// randomIntForContext generates a random integer between 0 and max (exclusive), preferring the
+// session or instance random source and falling back to the deterministic generator
+func (g *Golem) randomIntForContext(ctx *VariableContext, max int) int {
+	var session *ChatSession
+	if ctx != nil {
+		session = ctx.Session
+	}
+	if source := g.randomSourceFor(session); source != nil {
+		return source.Intn(max)
+	}
+	return g.randomInt(max)
+}
+
 // randomInt generates a random integer between 0 and max (exclusive)
 func (g *Golem) randomInt(max int) int {
 	// Use a simple linear congruential generator for deterministic randomness
@@ -4304,6 +4520,11 @@ func (g *Golem) normalizeTextForOutput(input string) string {
 	// Convert to uppercase
 	text = strings.ToUpper(text)
 
+	// Apply normal.substitution when loaded
+	if normal := g.loadedSubstitution(SubstitutionNormal); normal != nil {
+		text = g.applySubstitutionRules(text, substitutionRules(strings.ToUpper, normal))
+	}
+
 	// Normalize whitespace
 	text = regexp.MustCompile(`\s+`).ReplaceAllString(text, " ")
 
@@ -4351,6 +4572,11 @@ func (g *Golem) denormalizeText(input string) string {
 	// Normalize whitespace
 	text = regexp.MustCompile(`\s+`).ReplaceAllString(text, " ")
 
+	// Apply denormal.substitution when loaded
+	if denormal := g.loadedSubstitution(SubstitutionDenormal); denormal != nil {
+		text = g.applySubstitutionRules(text, substitutionRules(strings.ToLower, denormal))
+	}
+
 	// Capitalize first letter of each sentence
 	text = g.capitalizeSentences(text)
 
@@ -4680,7 +4906,7 @@ func (g *Golem) processThinkTagsWithContext(template string, ctx *VariableContex
 // processThinkContentWithContext processes the content inside <think> tags with variable context
 func (g *Golem) processThinkContentWithContext(content string, ctx *VariableContext) {
 	// Process date/time tags first
-	content = g.processDateTimeTags(content)
+	content = g.processDateTimeTagsWithSession(content, ctx.Session)
 
 	// Find all <set> tags
 	setRegex := regexp.MustCompile(`<set name="([^"]+)">(.*?)</set>`)
@@ -5283,6 +5509,7 @@ func (g *Golem) processSRAITags(template string, session *ChatSession) string {
 				g.LogInfo("SRAI no match for: '%s'", sraiInput)
 				continue
 			}
+			g.recordCategoryHit(category)
 
 			// Process the matched template
 			var sraiResponse string
@@ -5326,7 +5553,7 @@ func (g *Golem) processThinkTags(template string, session *ChatSession) string {
 // processThinkContent processes the content inside <think> tags
 func (g *Golem) processThinkContent(content string, session *ChatSession) {
 	// Process date/time tags in think content first
-	content = g.processDateTimeTags(content)
+	content = g.processDateTimeTagsWithSession(content, session)
 
 	// Process <set> tags for variable setting
 	setRegex := regexp.MustCompile(`<set name="([^"]+)">([^<]*)</set>`)
@@ -5700,47 +5927,47 @@ func (g *Golem) setVariable(varName, varValue string, scope VariableScope, ctx *
 
 // processDateTimeTags processes <date> and <time> tags
 func (g *Golem) processDateTimeTags(template string) string {
+	return g.processDateTimeTagsWithSession(template, nil)
+}
+
+// processDateTimeTagsWithSession processes <date> and <time> tags using the session's
+// timezone and locale predicates as defaults
+func (g *Golem) processDateTimeTagsWithSession(template string, session *ChatSession) string {
 	// Process <date> tags
-	template = g.processDateTags(template)
+	template = g.processDateTagsWithSession(template, session)
 
 	// Process <time> tags
-	template = g.processTimeTags(template)
+	template = g.processTimeTagsWithSession(template, session)
 
 	return template
 }
 
 // processDateTags processes <date> tags with various formats
 func (g *Golem) processDateTags(template string) string {
-	// Enhanced regex to match <date> tags with format and jformat attributes
-	// Supports: <date format="..." jformat="..."/>
-	dateRegex := regexp.MustCompile(`<date(?:\s+format="([^"]*)"|\s+format=\\"([^"]*)\\"|\s+jformat="([^"]*)"|\s+jformat=\\"([^"]*)\\")*/>`)
+	return g.processDateTagsWithSession(template, nil)
+}
+
+// processDateTagsWithSession processes <date> tags with various formats
+func (g *Golem) processDateTagsWithSession(template string, session *ChatSession) string {
+	// Supports: <date format="..." jformat="..." timezone="..." locale="..."/>
+	dateRegex := regexp.MustCompile(`<date((?:\s+[\w-]+\s*=\s*\\?"[^"\\]*\\?")*)\s*/>`)
 	matches := dateRegex.FindAllStringSubmatch(template, -1)
 
 	for _, match := range matches {
-		format := ""
-		jformat := ""
-
-		// Extract format attribute
-		if len(match) > 1 && match[1] != "" {
-			format = match[1]
-		} else if len(match) > 2 && match[2] != "" {
-			format = match[2]
-		}
-
-		// Extract jformat attribute
-		if len(match) > 3 && match[3] != "" {
-			jformat = match[3]
-		} else if len(match) > 4 && match[4] != "" {
-			jformat = match[4]
-		}
+		attributes := parseDateTagAttributes(match[1])
+		format := attributes["format"]
+		jformat := attributes["jformat"]
 
 		g.LogInfo("Processing date tag with format: '%s', jformat: '%s'", format, jformat)
 
 		// Handle special cases that need direct calculation
 		var dateStr string
-		now := time.Now()
+		now, locale := g.localizedNow(attributes, session)
 
-		if format != "" {
+		if jformat != "" {
+			// Java SimpleDateFormat takes precedence
+			dateStr = formatJavaDate(now, jformat, locale)
+		} else if format != "" {
 			switch format {
 			case "quarter":
 				month := int(now.Month())
@@ -5772,20 +5999,12 @@ func (g *Golem) processDateTags(template string) string {
 			case "unixnano":
 				dateStr = fmt.Sprintf("%d", now.UnixNano())
 			default:
-				// Determine which format to use (jformat takes precedence)
-				var finalFormat string
-				if jformat != "" {
-					// Convert Java SimpleDateFormat to Go time format
-					finalFormat = g.convertJavaToGoTimeFormat(jformat)
-				} else {
-					// Use the format attribute as-is (already supports C-style formats)
-					finalFormat = g.convertToGoTimeFormat(format)
-				}
-				dateStr = now.Format(finalFormat)
+				// Use the format attribute as-is (already supports C-style formats)
+				dateStr = formatLocalizedLayout(now, g.convertToGoTimeFormat(format), locale)
 			}
 		} else {
 			// Default format
-			dateStr = now.Format("January 2, 2006")
+			dateStr = formatLocalizedLayout(now, "January 2, 2006", locale)
 		}
 
 		// Replace the date tag with the formatted date
@@ -5797,22 +6016,29 @@ func (g *Golem) processDateTags(template string) string {
 
 // processTimeTags processes <time> tags with various formats
 func (g *Golem) processTimeTags(template string) string {
-	// Find all <time> tags
-	timeRegex := regexp.MustCompile(`<time(?: format="([^"]*)"| format=\\"([^"]*)\\")?/>`)
+	return g.processTimeTagsWithSession(template, nil)
+}
+
+// processTimeTagsWithSession processes <time> tags with various formats
+func (g *Golem) processTimeTagsWithSession(template string, session *ChatSession) string {
+	// Supports: <time format="..." jformat="..." timezone="..." locale="..."/>
+	timeRegex := regexp.MustCompile(`<time((?:\s+[\w-]+\s*=\s*\\?"[^"\\]*\\?")*)\s*/>`)
 	matches := timeRegex.FindAllStringSubmatch(template, -1)
 
 	for _, match := range matches {
-		format := ""
-		if len(match) > 1 && match[1] != "" {
-			format = match[1]
-		} else if len(match) > 2 && match[2] != "" {
-			format = match[2]
-		}
+		attributes := parseDateTagAttributes(match[1])
+		format := attributes["format"]
 
 		g.LogInfo("Processing time tag with format: '%s'", format)
 
 		// Get current time and format it
-		timeStr := g.formatTime(format)
+		now, locale := g.localizedNow(attributes, session)
+		var timeStr string
+		if jformat := attributes["jformat"]; jformat != "" {
+			timeStr = formatJavaDate(now, jformat, locale)
+		} else {
+			timeStr = g.formatTimeAt(now, format, locale)
+		}
 
 		// Replace the time tag with the formatted time
 		template = strings.ReplaceAll(template, match[0], timeStr)
@@ -5895,7 +6121,7 @@ func (g *Golem) processResponseTags(template string, ctx *VariableContext) strin
 
 // formatDate formats the current date according to the specified format
 func (g *Golem) formatDate(format string) string {
-	now := time.Now()
+	now := g.now()
 
 	switch format {
 	case "short":
@@ -5948,11 +6174,14 @@ func (g *Golem) formatDate(format string) string {
 
 // formatTime formats the current time according to the specified format
 func (g *Golem) formatTime(format string) string {
-	now := time.Now()
+	return g.formatTimeAt(g.now(), format, nil)
+}
 
+// formatTimeAt formats the given time according to the specified format and locale
+func (g *Golem) formatTimeAt(now time.Time, format string, locale *dateLocale) string {
 	switch format {
 	case "12":
-		return now.Format("3:04 PM")
+		return formatLocalizedLayout(now, "3:04 PM", locale)
 	case "24":
 		return now.Format("15:04")
 	case "iso":
@@ -5997,10 +6226,10 @@ func (g *Golem) formatTime(format string) string {
 		if g.isCustomTimeFormat(format) {
 			// Convert C-style format strings to Go format strings
 			goFormat := g.convertToGoTimeFormat(format)
-			return now.Format(goFormat)
+			return formatLocalizedLayout(now, goFormat, locale)
 		}
 		// Default format: "3:04 PM"
-		return now.Format("3:04 PM")
+		return formatLocalizedLayout(now, "3:04 PM", locale)
 	}
 }
 
@@ -6148,106 +6377,89 @@ func (g *Golem) convertToGoTimeFormat(format string) string {
 	return result
 }
 
-// convertJavaToGoTimeFormat converts Java SimpleDateFormat patterns to Go time format
+// convertJavaToGoTimeFormat converts Java SimpleDateFormat patterns to Go time format.
+// Fields Go layouts cannot express (week numbers, day of week in month, ISO day number)
+// are kept as pattern letters; use formatJavaDate to render them.
 func (g *Golem) convertJavaToGoTimeFormat(javaFormat string) string {
-	// Handle literal text in single quotes first
-	literalRegex := regexp.MustCompile(`'([^']*)'`)
-	result := literalRegex.ReplaceAllStringFunc(javaFormat, func(match string) string {
-		// Remove quotes and return the literal text
-		return match[1 : len(match)-1]
+	var result strings.Builder
+	forEachJavaDateToken(javaFormat, func(letter rune, count int) {
+		result.WriteString(javaFieldToGoLayout(letter, count))
+	}, func(text string) {
+		result.WriteString(text)
 	})
+	return result.String()
+}
 
-	// Java SimpleDateFormat to Go time format conversions
-	// Order matters - longer patterns must be processed first
-	conversions := []struct {
-		java  string
-		gofmt string
-	}{
-		// Era
-		{"G", "AD"}, // Era designator (AD/BC)
-
-		// Year - longest first
-		{"yyyy", "2006"}, // Year (4 digits)
-		{"yyy", "2006"},  // Year (3+ digits)
-		{"yy", "06"},     // Year (2 digits)
-		{"y", "2006"},    // Year (4 digits)
-
-		// Month - longest first
-		{"MMMMM", "J"},      // Month (first letter)
-		{"MMMM", "January"}, // Month (full name)
-		{"MMM", "Jan"},      // Month (abbreviated)
-		{"MM", "01"},        // Month (01-12)
-		{"M", "1"},          // Month (1-12)
-
-		// Day - longest first
-		{"ddd", "002"}, // Day of year (001-366)
-		{"dd", "02"},   // Day (01-31)
-		{"d", "2"},     // Day (1-31)
-
-		// Hour - longest first
-		{"HH", "15"}, // Hour (00-23)
-		{"H", "15"},  // Hour (0-23)
-		{"kk", "15"}, // Hour (01-24)
-		{"k", "15"},  // Hour (1-24)
-		{"KK", "03"}, // Hour (00-11)
-		{"K", "3"},   // Hour (0-11)
-		{"hh", "03"}, // Hour (01-12)
-		{"h", "3"},   // Hour (1-12)
-
-		// Minute - longest first
-		{"mm", "04"}, // Minute (00-59)
-		{"m", "4"},   // Minute (0-59)
-
-		// Second - longest first
-		{"SSS", "000"}, // Millisecond (3 digits)
-		{"SS", "00"},   // Millisecond (2 digits)
-		{"S", "0"},     // Millisecond
-		{"ss", "05"},   // Second (00-59)
-		{"s", "5"},     // Second (0-59)
-
-		// Week - longest first
-		{"ww", "02"}, // Week of year (2 digits)
-		{"w", "2"},   // Week of year
-		{"W", "1"},   // Week of month
-
-		// Day of week - longest first
-		{"EEEEE", "M"},     // Day of week (first letter)
-		{"EEEE", "Monday"}, // Day of week (full name)
-		{"EEE", "Mon"},     // Day of week (abbreviated)
-		{"EE", "Mon"},      // Day of week (abbreviated)
-		{"E", "Mon"},       // Day of week (abbreviated)
-		{"u", "1"},         // Day of week (1-7, Monday=1)
-		{"F", "1"},         // Day of week in month
-
-		// AM/PM
-		{"a", "PM"}, // AM/PM marker
-
-		// Timezone - longest first
-		{"XXXXX", "-07:00"}, // Timezone offset (hours:minutes)
-		{"XXXX", "-0700"},   // Timezone offset (hours:minutes)
-		{"XXX", "-07:00"},   // Timezone offset (hours:minutes)
-		{"XX", "-0700"},     // Timezone offset (hours:minutes)
-		{"X", "-07"},        // Timezone offset (hours)
-		{"Z", "-0700"},      // Timezone offset
-		{"zzzz", "MST"},     // Timezone (full name)
-		{"zzz", "MST"},      // Timezone (abbreviated)
-		{"zz", "MST"},       // Timezone (abbreviated)
-		{"z", "MST"},        // Timezone (abbreviated)
-	}
-
-	// Apply conversions in order (longest patterns first)
-	// This ensures that "MMMM" is processed before "M" to avoid partial matches
-	for _, conv := range conversions {
-		// Replace all occurrences of the Java pattern with the Go pattern
-		result = strings.ReplaceAll(result, conv.java, conv.gofmt)
-	}
-
-	// If no conversions were made and it looks like a Go format, return as-is
-	if result == javaFormat && g.looksLikeGoTimeFormat(javaFormat) {
-		return javaFormat
+// javaFieldToGoLayout maps a single SimpleDateFormat field to its Go layout element
+func javaFieldToGoLayout(letter rune, count int) string {
+	switch letter {
+	case 'G':
+		return "AD"
+	case 'y', 'Y':
+		if count == 2 {
+			return "06"
+		}
+		return "2006"
+	case 'M', 'L':
+		switch {
+		case count >= 4:
+			return "January"
+		case count == 3:
+			return "Jan"
+		case count == 2:
+			return "01"
+		default:
+			return "1"
+		}
+	case 'd':
+		if count >= 2 {
+			return "02"
+		}
+		return "2"
+	case 'D':
+		return "002"
+	case 'E':
+		if count >= 4 {
+			return "Monday"
+		}
+		return "Mon"
+	case 'a':
+		return "PM"
+	case 'H', 'k':
+		return "15"
+	case 'h', 'K':
+		if count >= 2 {
+			return "03"
+		}
+		return "3"
+	case 'm':
+		if count >= 2 {
+			return "04"
+		}
+		return "4"
+	case 's':
+		if count >= 2 {
+			return "05"
+		}
+		return "5"
+	case 'S':
+		return strings.Repeat("0", count)
+	case 'z':
+		return "MST"
+	case 'Z':
+		return "-0700"
+	case 'X':
+		switch count {
+		case 1:
+			return "Z07"
+		case 2:
+			return "Z0700"
+		default:
+			return "Z07:00"
+		}
+	default:
+		return strings.Repeat(string(letter), count)
 	}
-
-	return result
 }
 
 // looksLikeGoTimeFormat checks if the format string looks like a Go time format
@@ -6267,8 +6479,8 @@ func (g *Golem) looksLikeGoTimeFormat(format string) bool {
 	return false
 }
 
-// processRandomTags processes <random> tags and selects a random <li> element
-func (g *Golem) processRandomTags(template string) string {
+// processRandomTags processes <random> tags and selects a random <li> element with the session's random source
+func (g *Golem) processRandomTags(template string, session *ChatSession) string {
 	// Find all <random> tags
 	randomRegex := regexp.MustCompile(`(?s)<random>(.*?)</random>`)
 	matches := randomRegex.FindAllStringSubmatch(template, -1)
@@ -6292,7 +6504,7 @@ func (g *Golem) processRandomTags(template string) string {
 			selectedIndex := 0
 			if len(liMatches) > 1 {
 				// Use proper random selection
-				selectedIndex = int(time.Now().UnixNano()) % len(liMatches)
+				selectedIndex = g.randomIntTree(session, len(liMatches))
 			}
 
 			selectedContent := strings.TrimSpace(liMatches[selectedIndex][1])
@@ -6458,11 +6670,26 @@ func (kb *AIMLKnowledgeBase) GetSetMembers(setName string) []string {
 	return kb.Sets[setName]
 }
 
-// IsSetMember checks if a word is a member of a set
+// IsSetMember checks if a word is a member of a set. Built-in date sets read dates without a year
+// in the current year; Golem.IsSetMember uses the bot's clock instead.
 func (kb *AIMLKnowledgeBase) IsSetMember(setName, word string) bool {
+	return kb.isSetMember(setName, word, time.Now())
+}
+
+// IsSetMember checks if a word is a member of a loaded or built-in set, reading dates without a
+// year by the bot's clock
+func (g *Golem) IsSetMember(setName, word string) bool {
+	if g.aimlKB == nil {
+		return isBuiltinSetMember(setName, word, g.GetClock().Now())
+	}
+	return g.aimlKB.isSetMember(setName, word, g.GetClock().Now())
+}
+
+// isSetMember checks if a word is a member of a set at the time now
+func (kb *AIMLKnowledgeBase) isSetMember(setName, word string, now time.Time) bool {
 	setName = strings.ToUpper(setName)
-	if kb.Sets[setName] == nil {
-		return false
+	if len(kb.Sets[setName]) == 0 {
+		return isBuiltinSetMember(setName, word, now)
 	}
 	upperWord := strings.ToUpper(word)
 	for _, member := range kb.Sets[setName] {
@@ -7361,7 +7588,7 @@ func (g *Golem) processMapTagsWithContext(template string, ctx *VariableContext)
 				// Check if map contains key
 				contains := false
 				if key != "" {
-					_, contains = ctx.KnowledgeBase.Maps[mapName][key]
+					_, contains = g.lookupMapValue(ctx.KnowledgeBase, mapName, key)
 				}
 				result := "false"
 				if contains {
@@ -7406,7 +7633,7 @@ func (g *Golem) processMapTagsWithContext(template string, ctx *VariableContext)
 			case "get", "":
 				// Get value by key (original functionality)
 				if key != "" {
-					if value, exists := ctx.KnowledgeBase.Maps[mapName][key]; exists {
+					if value, exists := g.lookupMapValue(ctx.KnowledgeBase, mapName, key); exists {
 						template = strings.ReplaceAll(template, match[0], value)
 						g.LogInfo("Mapped '%s' -> '%s'", key, value)
 					} else {
@@ -7422,7 +7649,7 @@ func (g *Golem) processMapTagsWithContext(template string, ctx *VariableContext)
 			default:
 				// Unknown operation, treat as get
 				if key != "" {
-					if value, exists := ctx.KnowledgeBase.Maps[mapName][key]; exists {
+					if value, exists := g.lookupMapValue(ctx.KnowledgeBase, mapName, key); exists {
 						template = strings.ReplaceAll(template, match[0], value)
 					} else {
 						template = strings.ReplaceAll(template, match[0], key)
@@ -8216,87 +8443,20 @@ func (g *Golem) normalizeForMatchingWithSubstitutions(input string) string {
 	return text
 }
 
-// applyLoadedSubstitutions applies loaded substitution rules to text
-// Substitutions are applied iteratively until no more changes occur, with longer patterns taking precedence
+// applyLoadedSubstitutions applies the loaded input substitutions (normal.substitution and any
+// custom files) to text; person, person2, gender and denormal only apply to their tags
 func (g *Golem) applyLoadedSubstitutions(text string) string {
 	if g.aimlKB == nil || len(g.aimlKB.Substitutions) == 0 {
 		return text
 	}
 
-	originalText := text
-
-	// Collect all substitution patterns and sort by length (longest first)
-	type substitution struct {
-		pattern     string
-		replacement string
-	}
-	var allSubstitutions []substitution
-
-	for _, substitutionMap := range g.aimlKB.Substitutions {
-		for pattern, replacement := range substitutionMap {
-			allSubstitutions = append(allSubstitutions, substitution{
-				pattern:     strings.ToUpper(pattern),
-				replacement: strings.ToUpper(replacement),
-			})
+	var maps []map[string]string
+	for name, substitutionMap := range g.aimlKB.Substitutions {
+		if !isTagSubstitution(name) {
+			maps = append(maps, substitutionMap)
 		}
 	}
-
-	// Sort by pattern length (longest first)
-	for i := 0; i < len(allSubstitutions)-1; i++ {
-		for j := i + 1; j < len(allSubstitutions); j++ {
-			if len(allSubstitutions[i].pattern) < len(allSubstitutions[j].pattern) {
-				allSubstitutions[i], allSubstitutions[j] = allSubstitutions[j], allSubstitutions[i]
-			}
-		}
-	}
-
-	// Apply substitutions iteratively until no more changes occur
-	maxIterations := 10 // Prevent infinite loops
-	iteration := 0
-
-	for iteration < maxIterations {
-		iteration++
-		prevText := text
-		result := strings.Builder{}
-		result.Grow(len(text))
-
-		// Apply substitutions in a single pass
-		i := 0
-		for i < len(text) {
-			matched := false
-
-			// Try to match the longest pattern first
-			for _, sub := range allSubstitutions {
-				if i+len(sub.pattern) <= len(text) && text[i:i+len(sub.pattern)] == sub.pattern {
-					// Found a match, apply substitution
-					result.WriteString(sub.replacement)
-					i += len(sub.pattern)
-					matched = true
-					g.LogDebug("Applied substitution: '%s' -> '%s'", sub.pattern, sub.replacement)
-					break
-				}
-			}
-
-			if !matched {
-				// No match, copy the character as-is
-				result.WriteByte(text[i])
-				i++
-			}
-		}
-
-		text = result.String()
-
-		// If no changes were made, we're done
-		if text == prevText {
-			break
-		}
-	}
-
-	if originalText != text {
-		g.LogDebug("Substitutions applied: '%s' -> '%s'", originalText, text)
-	}
-
-	return text
+	return g.applySubstitutionRules(text, substitutionRules(strings.ToUpper, maps...))
 }
 
 // NormalizePattern normalizes AIML patterns for matching
@@ -8494,6 +8654,8 @@ type WordBoundaryDetector struct {
 	separators map[rune]bool
 	// Characters that are considered punctuation
 	punctuation map[rune]bool
+	// Tokenizer that further splits words of languages written without spaces
+	tokenizer Tokenizer
 }
 
 // NewWordBoundaryDetector creates a new word boundary detector
@@ -8551,6 +8713,14 @@ func (wbd *WordBoundaryDetector) SplitWords(text string) []string {
 		words = append(words, current.String())
 	}
 
+	if wbd.tokenizer != nil {
+		var tokens []string
+		for _, word := range words {
+			tokens = append(tokens, wbd.tokenizer.Tokenize(word)...)
+		}
+		return tokens
+	}
+
 	return words
 }
 
@@ -8771,8 +8941,9 @@ func validateThatPattern(pattern string) error {
 		return fmt.Errorf("that pattern contains too many wildcards (max 9), got %d", totalWildcards)
 	}
 
-	// Check for valid characters (enhanced validation) - allow all AIML2 wildcards and punctuation
-	validChars := regexp.MustCompile(`^[A-Z0-9\s\*_^#$<>/'.!?,-]+$`)
+	// Check for valid characters (enhanced validation) - allow all AIML2 wildcards and punctuation,
+	// and upper-case or uncased letters in any script
+	validChars := regexp.MustCompile(`^[\p{Lu}\p{Lo}\p{M}\p{Nd}\s\*_^#$<>/'.!?,-]+$`)
 	if !validChars.MatchString(pattern) {
 		return fmt.Errorf("that pattern contains invalid characters")
 	}
@@ -8946,11 +9117,7 @@ func matchThatPatternWithEnhancedContext(g *Golem, thatContext, thatPattern stri
 	if g.fuzzyMatcher == nil {
 		g.fuzzyMatcher = NewFuzzyContextMatcher()
 	}
-	if g.semanticMatcher == nil {
-		g.semanticMatcher = NewSemanticContextMatcher()
-		g.semanticMatcher.InitializeSynonyms()
-		g.semanticMatcher.InitializeDomainMappings()
-	}
+	g.semanticContextMatcher()
 
 	// First try exact pattern matching with sets and topics
 	exactMatch, wildcards := matchThatPatternWithWildcardsWithGolem(g, thatContext, thatPattern)
@@ -9473,6 +9640,11 @@ func thatPatternToRegexWithSetsAndTopics(g *Golem, pattern string, kb *AIMLKnowl
 		}
 		setName := strings.ToUpper(strings.TrimSpace(matches[1]))
 
+		// Registered providers replace loaded sets
+		if regex, ok := g.providerSetRegex(setName); ok {
+			return regex
+		}
+
 		// Check cache first
 		if g != nil && g.patternMatchingCache != nil {
 			if regex, found := g.patternMatchingCache.GetSetRegex(setName, kb.Sets[setName]); found {
@@ -9513,6 +9685,10 @@ func thatPatternToRegexWithSetsAndTopics(g *Golem, pattern string, kb *AIMLKnowl
 
 			return regex
 		}
+		// Fall back to a built-in set such as NUMBER
+		if regex, exists := builtinSetRegex(setName); exists {
+			return regex
+		}
 		// Fallback to wildcard if set not found
 		return "([^\\s]*)"
 	})
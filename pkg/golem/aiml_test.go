@@ -952,7 +952,7 @@ func TestProcessRandomTags(t *testing.T) {
 
 	// Test single random option
 	template := "<random><li>Hello there!</li></random>"
-	result := g.processRandomTags(template)
+	result := g.processRandomTags(template, nil)
 	expected := "Hello there!"
 
 	if result != expected {
@@ -965,7 +965,7 @@ func TestProcessRandomTags(t *testing.T) {
 		<li>Option 2</li>
 		<li>Option 3</li>
 	</random>`
-	result = g.processRandomTags(template)
+	result = g.processRandomTags(template, nil)
 
 	// Should be one of the options
 	validOptions := []string{"Option 1", "Option 2", "Option 3"}
@@ -982,7 +982,7 @@ func TestProcessRandomTags(t *testing.T) {
 
 	// Test random tag with no <li> elements
 	template = "<random>Just some text</random>"
-	result = g.processRandomTags(template)
+	result = g.processRandomTags(template, nil)
 	expected = "Just some text"
 
 	if result != expected {
@@ -991,7 +991,7 @@ func TestProcessRandomTags(t *testing.T) {
 
 	// Test multiple random tags in one template
 	template = `<random><li>First</li></random> and <random><li>Second</li></random>`
-	result = g.processRandomTags(template)
+	result = g.processRandomTags(template, nil)
 	expected = "First and Second"
 
 	if result != expected {
@@ -1002,7 +1002,7 @@ func TestProcessRandomTags(t *testing.T) {
 	template = `<random>
 		<li>   Spaced   </li>
 	</random>`
-	result = g.processRandomTags(template)
+	result = g.processRandomTags(template, nil)
 	expected = "Spaced"
 
 	if result != expected {
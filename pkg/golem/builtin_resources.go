@@ -0,0 +1,148 @@
+package golem
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// builtinSet is a set that is available without a .set file
+type builtinSet struct {
+	regex    string
+	contains func(word string, now time.Time) bool
+}
+
+// builtinSets are the standard AIML 2.0 sets, keyed by upper-case name, and the entity sets
+// (EMAIL, URL, PHONE, DATE and MONEY, with NUMBER also accepting decimals, signs and thousands
+// separators). A loaded set with the same name replaces the built-in one.
+var builtinSets = func() map[string]builtinSet {
+	sets := make(map[string]builtinSet)
+	for name := range entityTypes {
+		name := name
+		sets[name] = builtinSet{
+			regex: entitySetRegex(name),
+			contains: func(word string, now time.Time) bool {
+				return isEntitySetMember(name, word, now)
+			},
+		}
+	}
+	sets["NUMBER"] = builtinSet{
+		regex: entitySetRegex("NUMBER"),
+		contains: func(word string, now time.Time) bool {
+			return isDigits(word) || isEntitySetMember("NUMBER", word, now)
+		},
+	}
+	return sets
+}()
+
+// builtinSetRegex returns the pattern regex of a built-in set
+func builtinSetRegex(name string) (string, bool) {
+	set, exists := builtinSets[strings.ToUpper(name)]
+	if !exists {
+		return "", false
+	}
+	return set.regex, true
+}
+
+// isBuiltinSetMember reports whether word belongs to a built-in set, reading dates without a year
+// in the year of now
+func isBuiltinSetMember(name, word string, now time.Time) bool {
+	set, exists := builtinSets[strings.ToUpper(name)]
+	return exists && set.contains(strings.TrimSpace(word), now)
+}
+
+// builtinMapLookup looks up key in one of the standard AIML 2.0 maps (successor, predecessor,
+// plural and singular) or in the semantic tables (synonym, antonym and domain). Entries of a loaded map with the same name take precedence.
+func (g *Golem) builtinMapLookup(name, key string) (string, bool) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", false
+	}
+
+	switch strings.ToLower(name) {
+	case "successor", "predecessor":
+		n, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			return "", false
+		}
+		if strings.EqualFold(name, "successor") {
+			return strconv.FormatInt(n+1, 10), true
+		}
+		return strconv.FormatInt(n-1, 10), true
+	case "plural":
+		return matchWordCase(key, g.pluralizeWord(strings.ToLower(key))), true
+	case "singular":
+		return matchWordCase(key, singularizeWord(strings.ToLower(key))), true
+	case "synonym", "antonym", "domain":
+		return g.semanticMapLookup(name, key)
+	}
+	return "", false
+}
+
+// matchWordCase gives word the case of original: all caps or a capitalized first letter
+func matchWordCase(original, word string) string {
+	if original == strings.ToUpper(original) && original != strings.ToLower(original) {
+		return strings.ToUpper(word)
+	}
+	runes := []rune(original)
+	if len(runes) > 0 && unicode.IsUpper(runes[0]) {
+		wordRunes := []rune(word)
+		if len(wordRunes) > 0 {
+			wordRunes[0] = unicode.ToUpper(wordRunes[0])
+			return string(wordRunes)
+		}
+	}
+	return word
+}
+
+// fvesSingulars maps common -ves plurals back to their -f and -fe singulars
+var fvesSingulars = map[string]string{
+	"calves":  "calf",
+	"elves":   "elf",
+	"halves":  "half",
+	"knives":  "knife",
+	"leaves":  "leaf",
+	"lives":   "life",
+	"loaves":  "loaf",
+	"selves":  "self",
+	"shelves": "shelf",
+	"thieves": "thief",
+	"wives":   "wife",
+	"wolves":  "wolf",
+}
+
+// singularizeWord converts a single lower-case word to its singular form
+func singularizeWord(word string) string {
+	for singular, plural := range irregularPlurals {
+		if plural == word {
+			return singular
+		}
+	}
+
+	switch {
+	case len(word) < 3:
+		return word
+	case strings.HasSuffix(word, "ies") && len(word) > 4 && !isVowel(rune(word[len(word)-4])):
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "ves"):
+		if singular, exists := fvesSingulars[word]; exists {
+			return singular
+		}
+		return word[:len(word)-1]
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "shes"), strings.HasSuffix(word, "ches"),
+		strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "zes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "oes"):
+		switch word {
+		case "shoes", "toes", "hoes", "foes", "canoes", "oboes":
+			return word[:len(word)-1]
+		}
+		return word[:len(word)-2]
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"), strings.HasSuffix(word, "is"):
+		return word
+	case strings.HasSuffix(word, "s"):
+		return word[:len(word)-1]
+	}
+	return word
+}
@@ -0,0 +1,90 @@
+package golem
+
+import "testing"
+
+func TestBuiltinSetsAndMaps(t *testing.T) {
+	dir := writeReplayBot(t, `<category><pattern>I AM <set>number</set> YEARS OLD</pattern><template>Next year you will be <map name="successor"><star/></map>.</template></category>
+<category><pattern>I AM * YEARS OLD</pattern><template>That is not a number.</template></category>
+<category><pattern>BEFORE *</pattern><template><map name="predecessor"><star/></map></template></category>
+<category><pattern>PLURAL OF *</pattern><template><map name="plural"><star/></map></template></category>
+<category><pattern>SINGULAR OF *</pattern><template><map name="singular"><star/></map></template></category>
+<category><pattern>IS * A NUMBER</pattern><template><map name="successor" operation="contains"><star/></map></template></category>`)
+	g := loadReplayBot(t, dir)
+	session := g.CreateSession("builtin")
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"I am 41 years old", "Next year you will be 42."},
+		{"I am forty years old", "That is not a number."},
+		{"before 0", "-1"},
+		{"before ten", "ten"},
+		{"plural of box", "boxes"},
+		{"plural of Child", "Children"},
+		{"singular of cities", "city"},
+		{"singular of knives", "knife"},
+		{"singular of people", "person"},
+		{"is 7 a number", "true"},
+		{"is seven a number", "false"},
+	}
+	for _, tc := range testCases {
+		if got := askN(t, g, session, tc.input, 1)[0]; got != tc.expected {
+			t.Errorf("%q: got %q, expected %q", tc.input, got, tc.expected)
+		}
+	}
+
+	if !g.aimlKB.IsSetMember("number", "2024") || g.aimlKB.IsSetMember("number", "12a") {
+		t.Error("Expected IsSetMember to use the built-in number set")
+	}
+}
+
+func TestLoadedResourcesOverrideBuiltins(t *testing.T) {
+	dir := writeReplayBot(t, `<category><pattern>PICK <set>number</set></pattern><template>Picked <star/></template></category>
+<category><pattern>PICK *</pattern><template>No pick</template></category>
+<category><pattern>PLURAL OF *</pattern><template><map name="plural"><star/></map></template></category>`)
+	writeResourceFile(t, dir, "number.set", "one\ntwo\nthree\n")
+	writeResourceFile(t, dir, "plural.map", "octopus:octopodes\n")
+	g := loadReplayBot(t, dir)
+	session := g.CreateSession("override")
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"pick two", "Picked two"},
+		{"pick 2", "No pick"},
+		// Loaded entries win and missing keys still use the built-in rules
+		{"plural of octopus", "octopodes"},
+		{"plural of cat", "cats"},
+	}
+	for _, tc := range testCases {
+		if got := askN(t, g, session, tc.input, 1)[0]; got != tc.expected {
+			t.Errorf("%q: got %q, expected %q", tc.input, got, tc.expected)
+		}
+	}
+}
+
+func TestSingularizeWord(t *testing.T) {
+	testCases := map[string]string{
+		"cats":     "cat",
+		"boxes":    "box",
+		"churches": "church",
+		"classes":  "class",
+		"ladies":   "lady",
+		"days":     "day",
+		"wolves":   "wolf",
+		"caves":    "cave",
+		"heroes":   "hero",
+		"shoes":    "shoe",
+		"mice":     "mouse",
+		"analyses": "analysis",
+		"bus":      "bus",
+		"glass":    "glass",
+	}
+	for plural, expected := range testCases {
+		if got := singularizeWord(plural); got != expected {
+			t.Errorf("singularizeWord(%q) = %q, expected %q", plural, got, expected)
+		}
+	}
+}
@@ -0,0 +1,640 @@
+package golem
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// conflictFillerWord stands in for "any other word" when building example inputs
+const conflictFillerWord = "SOMETHING"
+
+// CategoryRef identifies a category in a conflict report
+type CategoryRef struct {
+	Key        string `json:"key"`
+	Pattern    string `json:"pattern"`
+	That       string `json:"that,omitempty"`
+	Topic      string `json:"topic,omitempty"`
+	SourceFile string `json:"source_file,omitempty"`
+}
+
+// ConflictExample is an input (with that and topic context) that triggers a conflict
+type ConflictExample struct {
+	Input string `json:"input"`
+	That  string `json:"that,omitempty"`
+	Topic string `json:"topic,omitempty"`
+}
+
+// CategoryConflict describes two categories whose pattern, that and topic can match the same input
+type CategoryConflict struct {
+	Type        string            `json:"type"`     // duplicate, shadowing, ambiguity or overlap
+	Severity    string            `json:"severity"` // low, medium, high or critical
+	Category1   CategoryRef       `json:"category1"`
+	Category2   CategoryRef       `json:"category2"`
+	Winner      int               `json:"winner"` // 1 or 2 for the category the matcher picks, 0 when it is not deterministic
+	Description string            `json:"description"`
+	Suggestions []string          `json:"suggestions"`
+	Examples    []ConflictExample `json:"examples"`
+}
+
+// CategoryConflictReport holds the result of analyzing a whole knowledge base
+type CategoryConflictReport struct {
+	CategoryCount int                `json:"category_count"`
+	Conflicts     []CategoryConflict `json:"conflicts"`
+	BySeverity    map[string]int     `json:"by_severity"`
+	ByType        map[string]int     `json:"by_type"`
+}
+
+// conflictSeverityRank orders severities from most to least severe
+var conflictSeverityRank = map[string]int{
+	"critical": 4,
+	"high":     3,
+	"medium":   2,
+	"low":      1,
+}
+
+// ConflictSeverityAtLeast reports whether severity is at least as severe as minimum
+func ConflictSeverityAtLeast(severity, minimum string) bool {
+	return conflictSeverityRank[strings.ToLower(severity)] >= conflictSeverityRank[strings.ToLower(minimum)]
+}
+
+// Filter returns a copy of the report containing only conflicts at or above the given severity
+func (r *CategoryConflictReport) Filter(minSeverity string) *CategoryConflictReport {
+	filtered := newCategoryConflictReport(r.CategoryCount)
+	for _, conflict := range r.Conflicts {
+		if ConflictSeverityAtLeast(conflict.Severity, minSeverity) {
+			filtered.add(conflict)
+		}
+	}
+	return filtered
+}
+
+// newCategoryConflictReport creates an empty report
+func newCategoryConflictReport(categoryCount int) *CategoryConflictReport {
+	return &CategoryConflictReport{
+		CategoryCount: categoryCount,
+		Conflicts:     make([]CategoryConflict, 0),
+		BySeverity:    make(map[string]int),
+		ByType:        make(map[string]int),
+	}
+}
+
+// add appends a conflict and updates the counters
+func (r *CategoryConflictReport) add(conflict CategoryConflict) {
+	r.Conflicts = append(r.Conflicts, conflict)
+	r.BySeverity[conflict.Severity]++
+	r.ByType[conflict.Type]++
+}
+
+// DetectCategoryConflicts analyzes every category of the knowledge base, using pattern, that and topic together.
+// Each conflict carries an example input, verified against the matcher, and a severity.
+func (cd *ConflictDetection) DetectCategoryConflicts(kb *AIMLKnowledgeBase) *CategoryConflictReport {
+	if kb == nil {
+		return newCategoryConflictReport(0)
+	}
+
+	report := newCategoryConflictReport(len(kb.Categories))
+	analyzer := newCategoryConflictAnalyzer(cd.golem, kb)
+
+	for _, pair := range analyzer.candidatePairs() {
+		if conflict, found := analyzer.analyzePair(pair[0], pair[1]); found {
+			report.add(conflict)
+		}
+	}
+
+	sort.SliceStable(report.Conflicts, func(i, j int) bool {
+		ri := conflictSeverityRank[report.Conflicts[i].Severity]
+		rj := conflictSeverityRank[report.Conflicts[j].Severity]
+		if ri != rj {
+			return ri > rj
+		}
+		return report.Conflicts[i].Category1.Key < report.Conflicts[j].Category1.Key
+	})
+
+	return report
+}
+
+// DetectCategoryConflicts analyzes the loaded knowledge base for category conflicts
+func (g *Golem) DetectCategoryConflicts() (*CategoryConflictReport, error) {
+	if g.aimlKB == nil {
+		return nil, fmt.Errorf("no AIML knowledge base loaded")
+	}
+	return NewConflictDetection(g).DetectCategoryConflicts(g.aimlKB), nil
+}
+
+// categoryConflictAnalyzer holds the compiled automata for every category of a knowledge base
+type categoryConflictAnalyzer struct {
+	golem      *Golem
+	kb         *AIMLKnowledgeBase
+	categories []*Category
+	patterns   []*patternNFA
+	thats      []*patternNFA
+	topics     []*patternNFA
+}
+
+// newCategoryConflictAnalyzer compiles pattern, that and topic automata for each category
+func newCategoryConflictAnalyzer(g *Golem, kb *AIMLKnowledgeBase) *categoryConflictAnalyzer {
+	a := &categoryConflictAnalyzer{golem: g, kb: kb}
+	for i := range kb.Categories {
+		category := &kb.Categories[i]
+		a.categories = append(a.categories, category)
+		a.patterns = append(a.patterns, compilePatternNFA(NormalizePattern(category.Pattern), kb))
+		a.thats = append(a.thats, compileContextNFA(category.That, kb))
+		a.topics = append(a.topics, compileContextNFA(category.Topic, kb))
+	}
+	return a
+}
+
+// candidatePairs returns the category pairs worth analyzing.
+// Patterns that start with different literal words can never match the same input, so
+// categories are bucketed by their first word and only wildcard-led patterns are compared across buckets.
+func (a *categoryConflictAnalyzer) candidatePairs() [][2]int {
+	buckets := make(map[string][]int)
+	var open []int
+	for i, nfa := range a.patterns {
+		if word, ok := nfa.leadingWord(); ok {
+			buckets[word] = append(buckets[word], i)
+		} else {
+			open = append(open, i)
+		}
+	}
+
+	var pairs [][2]int
+	addPair := func(i, j int) {
+		if i > j {
+			i, j = j, i
+		}
+		pairs = append(pairs, [2]int{i, j})
+	}
+
+	bucketNames := make([]string, 0, len(buckets))
+	for name := range buckets {
+		bucketNames = append(bucketNames, name)
+	}
+	sort.Strings(bucketNames)
+	for _, name := range bucketNames {
+		members := buckets[name]
+		for x := 0; x < len(members); x++ {
+			for y := x + 1; y < len(members); y++ {
+				addPair(members[x], members[y])
+			}
+		}
+	}
+	for x, i := range open {
+		for y := x + 1; y < len(open); y++ {
+			addPair(i, open[y])
+		}
+		for j := range a.patterns {
+			if _, ok := a.patterns[j].leadingWord(); ok {
+				addPair(i, j)
+			}
+		}
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	return pairs
+}
+
+// analyzePair checks two categories for a conflict
+func (a *categoryConflictAnalyzer) analyzePair(i, j int) (CategoryConflict, bool) {
+	c1, c2 := a.categories[i], a.categories[j]
+
+	// Categories bound to different <that> history positions never compete
+	if c1.ThatIndex != c2.ThatIndex {
+		return CategoryConflict{}, false
+	}
+
+	input, ok := intersectNFAs(a.patterns[i], a.patterns[j], true)
+	if !ok {
+		return CategoryConflict{}, false
+	}
+	that, ok := intersectNFAs(a.thats[i], a.thats[j], false)
+	if !ok {
+		return CategoryConflict{}, false
+	}
+	topic, ok := intersectNFAs(a.topics[i], a.topics[j], false)
+	if !ok {
+		return CategoryConflict{}, false
+	}
+
+	example := ConflictExample{Input: input, That: that, Topic: topic}
+	conflict := CategoryConflict{
+		Category1: categoryRef(c1),
+		Category2: categoryRef(c2),
+		Examples:  []ConflictExample{example},
+	}
+
+	if conflict.Category1.Key == conflict.Category2.Key {
+		conflict.Type = "duplicate"
+		conflict.Severity = "critical"
+		conflict.Description = fmt.Sprintf("Category %s is defined twice; only one definition can ever be used", conflict.Category1.Key)
+		conflict.Suggestions = []string{
+			"Remove one of the definitions",
+			"Choose a duplicate policy when loading (first-wins, last-wins or error)",
+		}
+		return conflict, true
+	}
+
+	// Equal priority means the matcher's choice depends on map iteration order
+	normalizedThat := NormalizeThatPattern(that)
+	p1 := categoryMatchPriority(c1, NormalizePattern(c1.Pattern), normalizedThat).Priority
+	p2 := categoryMatchPriority(c2, NormalizePattern(c2.Pattern), normalizedThat).Priority
+
+	covers12 := a.covers(i, j) // every input of category 2 also matches category 1
+	covers21 := a.covers(j, i)
+
+	if p1 == p2 {
+		conflict.Type = "ambiguity"
+		conflict.Severity = "high"
+		if covers12 && covers21 {
+			conflict.Severity = "critical"
+		}
+		conflict.Description = fmt.Sprintf("Categories %s and %s have the same priority; the response for %q is chosen arbitrarily",
+			conflict.Category1.Key, conflict.Category2.Key, input)
+		conflict.Suggestions = []string{
+			"Make one pattern more specific with an extra word or a narrower wildcard (_ or #)",
+			"Add a <that> or <topic> to separate the two categories",
+		}
+		return conflict, true
+	}
+
+	winner := a.verifyWinner(c1, c2, example)
+	if winner == 0 {
+		// The example did not reproduce with the real matcher, so the pair is not reported
+		return CategoryConflict{}, false
+	}
+	conflict.Winner = winner
+
+	winnerRef, loserRef := conflict.Category1, conflict.Category2
+	loserCovered, winnerCovered := covers12, covers21
+	if winner == 2 {
+		winnerRef, loserRef = conflict.Category2, conflict.Category1
+		loserCovered, winnerCovered = covers21, covers12
+	}
+
+	switch {
+	case loserCovered:
+		conflict.Type = "shadowing"
+		conflict.Severity = "critical"
+		conflict.Description = fmt.Sprintf("Category %s can never match: every input it accepts is taken by higher-priority category %s",
+			loserRef.Key, winnerRef.Key)
+		conflict.Suggestions = []string{
+			fmt.Sprintf("Remove %s or make it higher priority than %s", loserRef.Pattern, winnerRef.Pattern),
+			"Use a higher-priority wildcard (# or _) or a $ word in the more specific pattern",
+		}
+	case winnerCovered:
+		// The more specific category wins on its own inputs; this is the intended AIML layering
+		return CategoryConflict{}, false
+	default:
+		conflict.Type = "overlap"
+		conflict.Severity = "low"
+		if CountWildcards(loserRef.Pattern) < CountWildcards(winnerRef.Pattern) {
+			conflict.Severity = "medium"
+		}
+		conflict.Description = fmt.Sprintf("Categories %s and %s both match %q; %s wins",
+			conflict.Category1.Key, conflict.Category2.Key, input, winnerRef.Key)
+		conflict.Suggestions = []string{
+			"Check that the winning category is the intended response for the example input",
+			"Add a more specific category for the overlapping inputs",
+		}
+	}
+
+	return conflict, true
+}
+
+// covers reports whether every input/that/topic accepted by category j is also accepted by category i
+func (a *categoryConflictAnalyzer) covers(i, j int) bool {
+	return nfaIncludes(a.patterns[i], a.patterns[j]) &&
+		nfaIncludes(a.thats[i], a.thats[j]) &&
+		nfaIncludes(a.topics[i], a.topics[j])
+}
+
+// verifyWinner runs the real matcher on a knowledge base holding only the two categories.
+// It returns 1 or 2 for the category that answered, or 0 if neither matched.
+func (a *categoryConflictAnalyzer) verifyWinner(c1, c2 *Category, example ConflictExample) int {
+	pairKB := NewAIMLKnowledgeBase()
+	pairKB.Sets = a.kb.Sets
+	pairKB.Categories = []Category{*c1, *c2}
+	for i := range pairKB.Categories {
+		pairKB.Patterns[CategoryKey(pairKB.Categories[i])] = &pairKB.Categories[i]
+	}
+
+	that := ""
+	if example.That != "" {
+		that = NormalizeThatPattern(example.That)
+	}
+	matched, _, err := pairKB.MatchPatternWithTopicAndThatIndexOriginalCached(nil, NormalizePattern(example.Input), example.Input, example.Topic, that, c1.ThatIndex)
+	if err != nil || matched == nil {
+		return 0
+	}
+	switch matched {
+	case &pairKB.Categories[0]:
+		return 1
+	case &pairKB.Categories[1]:
+		return 2
+	}
+	return 0
+}
+
+// categoryRef builds a reference to a category for reports
+func categoryRef(category *Category) CategoryRef {
+	return CategoryRef{
+		Key:        CategoryKey(*category),
+		Pattern:    category.Pattern,
+		That:       category.That,
+		Topic:      category.Topic,
+		SourceFile: category.SourceFile,
+	}
+}
+
+// patternNFA is a nondeterministic automaton over words for a pattern, that or topic.
+// Wildcards follow the matcher: *, ^ and # match zero or more words, _ matches exactly one word,
+// and <set> matches any (possibly multi-word) member of the set.
+type patternNFA struct {
+	edges [][]nfaEdge
+	eps   [][]int
+	final int
+}
+
+// nfaEdge is a transition that consumes one word; any edges consume every word
+type nfaEdge struct {
+	word string
+	any  bool
+	to   int
+}
+
+var conflictSetTagRegex = regexp.MustCompile(`^<set>([^<]+)</set>$`)
+var conflictTagTokenRegex = regexp.MustCompile(`<set>[^<]+</set>|<topic>[^<]+</topic>|\S+`)
+
+// newState adds a state to the automaton and returns its index
+func (n *patternNFA) newState() int {
+	n.edges = append(n.edges, nil)
+	n.eps = append(n.eps, nil)
+	return len(n.edges) - 1
+}
+
+// compileContextNFA compiles a that or topic pattern; an empty pattern matches any context
+func compileContextNFA(pattern string, kb *AIMLKnowledgeBase) *patternNFA {
+	if strings.TrimSpace(pattern) == "" {
+		return compilePatternNFA("^", kb)
+	}
+	return compilePatternNFA(NormalizePattern(pattern), kb)
+}
+
+// compilePatternNFA compiles a normalized pattern into an automaton
+func compilePatternNFA(pattern string, kb *AIMLKnowledgeBase) *patternNFA {
+	n := &patternNFA{}
+	cur := n.newState()
+
+	for _, token := range conflictTagTokenRegex.FindAllString(pattern, -1) {
+		switch {
+		case token == "*" || token == "^" || token == "#":
+			n.edges[cur] = append(n.edges[cur], nfaEdge{any: true, to: cur})
+		case token == "_":
+			next := n.newState()
+			n.edges[cur] = append(n.edges[cur], nfaEdge{any: true, to: next})
+			cur = next
+		case strings.HasPrefix(token, "<topic>"):
+			// The matcher treats a pattern-side <topic> as a single optional word
+			next := n.newState()
+			n.edges[cur] = append(n.edges[cur], nfaEdge{any: true, to: next})
+			n.eps[cur] = append(n.eps[cur], next)
+			cur = next
+		case conflictSetTagRegex.MatchString(token):
+			setName := strings.ToUpper(strings.TrimSpace(conflictSetTagRegex.FindStringSubmatch(token)[1]))
+			next := n.newState()
+			var members []string
+			if kb != nil {
+				members = kb.Sets[setName]
+			}
+			if len(members) == 0 {
+				// Unknown sets fall back to a single optional word, like the matcher
+				n.edges[cur] = append(n.edges[cur], nfaEdge{any: true, to: next})
+				n.eps[cur] = append(n.eps[cur], next)
+			}
+			for _, member := range members {
+				words := strings.Fields(strings.ToUpper(member))
+				if len(words) == 0 {
+					continue
+				}
+				from := cur
+				for k, word := range words {
+					to := next
+					if k < len(words)-1 {
+						to = n.newState()
+					}
+					n.edges[from] = append(n.edges[from], nfaEdge{word: word, to: to})
+					from = to
+				}
+			}
+			cur = next
+		default:
+			next := n.newState()
+			n.edges[cur] = append(n.edges[cur], nfaEdge{word: strings.TrimPrefix(token, "$"), to: next})
+			cur = next
+		}
+	}
+
+	n.final = cur
+	return n
+}
+
+// leadingWord returns the literal word every match must start with, if there is one
+func (n *patternNFA) leadingWord() (string, bool) {
+	if len(n.eps[0]) > 0 || len(n.edges[0]) != 1 || n.edges[0][0].any || n.final == 0 {
+		return "", false
+	}
+	return n.edges[0][0].word, true
+}
+
+// closure returns the states reachable from the given states through epsilon moves
+func (n *patternNFA) closure(states []int) []int {
+	seen := make(map[int]bool)
+	stack := append([]int{}, states...)
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		stack = append(stack, n.eps[s]...)
+	}
+	result := make([]int, 0, len(seen))
+	for s := range seen {
+		result = append(result, s)
+	}
+	sort.Ints(result)
+	return result
+}
+
+// step returns the epsilon-closed states reached from states by consuming word
+func (n *patternNFA) step(states []int, word string) []int {
+	var next []int
+	for _, s := range states {
+		for _, e := range n.edges[s] {
+			if e.any || e.word == word {
+				next = append(next, e.to)
+			}
+		}
+	}
+	return n.closure(next)
+}
+
+// accepts reports whether the final state is among the given states
+func (n *patternNFA) accepts(states []int) bool {
+	for _, s := range states {
+		if s == n.final {
+			return true
+		}
+	}
+	return false
+}
+
+// alphabet returns the literal words used on the automaton's edges
+func (n *patternNFA) alphabet() []string {
+	var words []string
+	for _, edges := range n.edges {
+		for _, e := range edges {
+			if !e.any {
+				words = append(words, e.word)
+			}
+		}
+	}
+	return words
+}
+
+// intersectNFAs finds the shortest word sequence accepted by both automata.
+// When nonEmpty is set the sequence must contain at least one word.
+func intersectNFAs(n1, n2 *patternNFA, nonEmpty bool) (string, bool) {
+	type node struct {
+		s1, s2   []int
+		words    []string
+		consumed bool
+	}
+	keyOf := func(s1, s2 []int, consumed bool) string {
+		return fmt.Sprint(s1, s2, consumed)
+	}
+
+	start := node{s1: n1.closure([]int{0}), s2: n2.closure([]int{0})}
+	seen := map[string]bool{keyOf(start.s1, start.s2, false): true}
+	queue := []node{start}
+
+	symbols := append(n1.alphabet(), n2.alphabet()...)
+	symbols = append(symbols, conflictFillerWord)
+	symbols = uniqueStrings(symbols)
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if n1.accepts(current.s1) && n2.accepts(current.s2) && (current.consumed || !nonEmpty) {
+			return strings.Join(current.words, " "), true
+		}
+
+		for _, word := range symbols {
+			next1 := n1.step(current.s1, word)
+			if len(next1) == 0 {
+				continue
+			}
+			next2 := n2.step(current.s2, word)
+			if len(next2) == 0 {
+				continue
+			}
+			key := keyOf(next1, next2, true)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			words := append(append([]string{}, current.words...), word)
+			queue = append(queue, node{s1: next1, s2: next2, words: words, consumed: true})
+		}
+	}
+
+	return "", false
+}
+
+// nfaIncludes reports whether every word sequence accepted by inner is accepted by outer
+func nfaIncludes(outer, inner *patternNFA) bool {
+	symbols := append(outer.alphabet(), inner.alphabet()...)
+	symbols = append(symbols, conflictFillerWord)
+	symbols = uniqueStrings(symbols)
+
+	type node struct{ in, out []int }
+	start := node{in: inner.closure([]int{0}), out: outer.closure([]int{0})}
+	seen := map[string]bool{fmt.Sprint(start.in, start.out): true}
+	queue := []node{start}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if inner.accepts(current.in) && !outer.accepts(current.out) {
+			return false
+		}
+
+		for _, word := range symbols {
+			nextIn := inner.step(current.in, word)
+			if len(nextIn) == 0 {
+				continue
+			}
+			nextOut := outer.step(current.out, word)
+			key := fmt.Sprint(nextIn, nextOut)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			queue = append(queue, node{in: nextIn, out: nextOut})
+		}
+	}
+
+	return true
+}
+
+// uniqueStrings removes duplicates while keeping the first occurrence order
+func uniqueStrings(items []string) []string {
+	seen := make(map[string]bool, len(items))
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// String formats the report for display
+func (r *CategoryConflictReport) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Analyzed %d categories, found %d conflicts\n", r.CategoryCount, len(r.Conflicts)))
+	for _, severity := range []string{"critical", "high", "medium", "low"} {
+		if count := r.BySeverity[severity]; count > 0 {
+			sb.WriteString(fmt.Sprintf("  %-8s %d\n", severity+":", count))
+		}
+	}
+
+	for i, conflict := range r.Conflicts {
+		sb.WriteString(fmt.Sprintf("\n%d. [%s] %s\n", i+1, conflict.Severity, conflict.Type))
+		sb.WriteString(fmt.Sprintf("   %s\n", conflict.Description))
+		sb.WriteString(fmt.Sprintf("   1: %s (%s)\n", conflict.Category1.Key, displaySourceFile(conflict.Category1.SourceFile)))
+		sb.WriteString(fmt.Sprintf("   2: %s (%s)\n", conflict.Category2.Key, displaySourceFile(conflict.Category2.SourceFile)))
+		for _, example := range conflict.Examples {
+			sb.WriteString(fmt.Sprintf("   example: input=%q", example.Input))
+			if example.That != "" {
+				sb.WriteString(fmt.Sprintf(" that=%q", example.That))
+			}
+			if example.Topic != "" {
+				sb.WriteString(fmt.Sprintf(" topic=%q", example.Topic))
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
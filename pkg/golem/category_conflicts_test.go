@@ -0,0 +1,172 @@
+package golem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// loadConflictKB loads AIML categories from a string into a fresh knowledge base
+func loadConflictKB(t *testing.T, aiml string) (*Golem, *AIMLKnowledgeBase) {
+	t.Helper()
+	g := New(false)
+	if err := g.LoadAIMLFromString(aiml); err != nil {
+		t.Fatalf("LoadAIMLFromString failed: %v", err)
+	}
+	return g, g.GetKnowledgeBase()
+}
+
+// findConflict returns the first conflict between the two patterns, if any
+func findConflict(report *CategoryConflictReport, pattern1, pattern2 string) *CategoryConflict {
+	for i := range report.Conflicts {
+		c := &report.Conflicts[i]
+		if (c.Category1.Pattern == pattern1 && c.Category2.Pattern == pattern2) ||
+			(c.Category1.Pattern == pattern2 && c.Category2.Pattern == pattern1) {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestCategoryConflictsShadowing(t *testing.T) {
+	g, kb := loadConflictKB(t, `<aiml version="2.0">
+<category><pattern># WEATHER</pattern><template>Weather anywhere</template></category>
+<category><pattern>* WEATHER</pattern><template>Never used</template></category>
+</aiml>`)
+
+	report := NewConflictDetection(g).DetectCategoryConflicts(kb)
+	conflict := findConflict(report, "# WEATHER", "* WEATHER")
+	if conflict == nil {
+		t.Fatalf("Expected a conflict, got %+v", report.Conflicts)
+	}
+	if conflict.Type != "shadowing" || conflict.Severity != "critical" {
+		t.Errorf("Expected critical shadowing, got %s/%s", conflict.Severity, conflict.Type)
+	}
+	if len(conflict.Examples) == 0 || conflict.Examples[0].Input == "" {
+		t.Fatalf("Expected an example input, got %+v", conflict.Examples)
+	}
+
+	// The example must reproduce with the real engine
+	g.SetKnowledgeBase(kb)
+	session := g.CreateSession("shadowing")
+	response, err := g.ProcessInput(conflict.Examples[0].Input, session)
+	if err != nil {
+		t.Fatalf("ProcessInput failed: %v", err)
+	}
+	if response != "Weather anywhere" {
+		t.Errorf("Expected shadowing category to win for %q, got %q", conflict.Examples[0].Input, response)
+	}
+}
+
+func TestCategoryConflictsIntendedLayering(t *testing.T) {
+	g, kb := loadConflictKB(t, `<aiml version="2.0">
+<category><pattern>HELLO *</pattern><template>Hello someone</template></category>
+<category><pattern>HELLO WORLD</pattern><template>Hello world</template></category>
+<category><pattern>GOODBYE</pattern><template>Bye</template></category>
+</aiml>`)
+
+	report := NewConflictDetection(g).DetectCategoryConflicts(kb)
+	if len(report.Conflicts) != 0 {
+		t.Errorf("Expected no conflicts for a specific pattern under a wildcard, got %+v", report.Conflicts)
+	}
+	if report.CategoryCount != 3 {
+		t.Errorf("Expected 3 categories analyzed, got %d", report.CategoryCount)
+	}
+}
+
+func TestCategoryConflictsOverlapWithSets(t *testing.T) {
+	g, kb := loadConflictKB(t, `<aiml version="2.0">
+<category><pattern>I LIKE <set>color</set></pattern><template>Nice color</template></category>
+<category><pattern>I LIKE _</pattern><template>Nice thing</template></category>
+</aiml>`)
+	kb.AddSetMember("COLOR", "RED")
+	kb.AddSetMember("COLOR", "LIGHT BLUE")
+
+	report := NewConflictDetection(g).DetectCategoryConflicts(kb)
+	conflict := findConflict(report, "I LIKE <set>color</set>", "I LIKE _")
+	if conflict == nil {
+		t.Fatalf("Expected a conflict between set and wildcard patterns, got %+v", report.Conflicts)
+	}
+	if conflict.Type != "overlap" {
+		t.Errorf("Expected overlap, got %s", conflict.Type)
+	}
+	if conflict.Examples[0].Input != "I LIKE RED" {
+		t.Errorf("Expected example 'I LIKE RED', got %q", conflict.Examples[0].Input)
+	}
+}
+
+func TestCategoryConflictsThatAndTopic(t *testing.T) {
+	g, kb := loadConflictKB(t, `<aiml version="2.0">
+<category><pattern>YES</pattern><that>DO YOU LIKE CATS</that><template>Cats</template></category>
+<category><pattern>YES</pattern><that>DO YOU LIKE DOGS</that><template>Dogs</template></category>
+<category><pattern>YES</pattern><that>DO YOU LIKE *</that><topic>SPORTS</topic><template>Sports</template></category>
+</aiml>`)
+
+	report := NewConflictDetection(g).DetectCategoryConflicts(kb)
+	for _, c := range report.Conflicts {
+		if c.Category1.That == "DO YOU LIKE CATS" && c.Category2.That == "DO YOU LIKE DOGS" ||
+			c.Category1.That == "DO YOU LIKE DOGS" && c.Category2.That == "DO YOU LIKE CATS" {
+			t.Errorf("Categories with disjoint <that> must not conflict: %+v", c)
+		}
+	}
+
+	found := false
+	for _, c := range report.Conflicts {
+		if c.Category1.Topic == "SPORTS" || c.Category2.Topic == "SPORTS" {
+			found = true
+			if c.Examples[0].Topic != "SPORTS" {
+				t.Errorf("Expected example topic SPORTS, got %+v", c.Examples[0])
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected the topic category to overlap the that categories, got %+v", report.Conflicts)
+	}
+}
+
+func TestCategoryConflictsAmbiguity(t *testing.T) {
+	g, kb := loadConflictKB(t, `<aiml version="2.0">
+<category><pattern>* LOVE</pattern><template>one</template></category>
+<category><pattern>I *</pattern><template>two</template></category>
+</aiml>`)
+
+	report := NewConflictDetection(g).DetectCategoryConflicts(kb)
+	conflict := findConflict(report, "* LOVE", "I *")
+	if conflict == nil {
+		t.Fatalf("Expected a conflict, got %+v", report.Conflicts)
+	}
+	if conflict.Examples[0].Input != "I LOVE" {
+		t.Errorf("Expected example 'I LOVE', got %q", conflict.Examples[0].Input)
+	}
+}
+
+func TestCategoryConflictsCommand(t *testing.T) {
+	dir := t.TempDir()
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<aiml version="2.0">
+    <category><pattern>^ HELP</pattern><template>Help</template></category>
+    <category><pattern>* HELP</pattern><template>Unreachable</template></category>
+</aiml>`
+	if err := os.WriteFile(filepath.Join(dir, "help.aiml"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write AIML file: %v", err)
+	}
+
+	g := New(false)
+	if err := g.Execute("conflicts", []string{dir, "--min-severity", "critical"}); err != nil {
+		t.Fatalf("conflicts command failed: %v", err)
+	}
+	if err := g.Execute("conflicts", []string{}); err == nil {
+		t.Error("Expected error when no directory is given")
+	}
+
+	report, err := g.DetectCategoryConflicts()
+	if err != nil {
+		t.Fatalf("DetectCategoryConflicts failed: %v", err)
+	}
+	if report.BySeverity["critical"] == 0 {
+		t.Errorf("Expected a critical conflict, got %+v", report)
+	}
+	if filtered := report.Filter("critical"); len(filtered.Conflicts) != report.BySeverity["critical"] {
+		t.Errorf("Filter kept %d conflicts, expected %d", len(filtered.Conflicts), report.BySeverity["critical"])
+	}
+}
@@ -0,0 +1,117 @@
+package golem
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Clock supplies the current time to <date>, <time> and other time-dependent tags
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock reads the wall clock
+type systemClock struct{}
+
+// Now returns the wall-clock time
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock returns a clock that reads the wall clock (the default)
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+// FixedClock is a clock that always returns the same instant
+type FixedClock struct {
+	Time time.Time
+}
+
+// Now returns the fixed instant
+func (c FixedClock) Now() time.Time {
+	return c.Time
+}
+
+// NewFixedClock creates a clock frozen at t
+func NewFixedClock(t time.Time) *FixedClock {
+	return &FixedClock{Time: t}
+}
+
+// Option configures a Golem instance when passed to New
+type Option func(*Golem)
+
+// WithClock makes time-dependent tags read the given clock
+func WithClock(clock Clock) Option {
+	return func(g *Golem) {
+		g.SetClock(clock)
+	}
+}
+
+// WithRand makes random tags (<random>, <shuffle>) draw from the given source.
+// Sessions with their own seed (see ChatSession.SetRandomSeed) use that instead.
+func WithRand(source *rand.Rand) Option {
+	return func(g *Golem) {
+		g.SetRand(source)
+	}
+}
+
+// SetClock sets the clock read by time-dependent tags; nil restores the wall clock
+func (g *Golem) SetClock(clock Clock) {
+	g.clock = clock
+}
+
+// GetClock returns the clock read by time-dependent tags
+func (g *Golem) GetClock() Clock {
+	if g.clock == nil {
+		return SystemClock()
+	}
+	return g.clock
+}
+
+// SetRand sets the random source used by random tags; nil restores the default selection
+func (g *Golem) SetRand(source *rand.Rand) {
+	g.randomSource = source
+}
+
+// now returns the current time from the configured clock
+func (g *Golem) now() time.Time {
+	if g == nil || g.clock == nil {
+		return time.Now()
+	}
+	return g.clock.Now()
+}
+
+// randomSourceFor returns the session's random source, the instance source, or nil when neither is set
+func (g *Golem) randomSourceFor(session *ChatSession) *rand.Rand {
+	if session != nil && session.random != nil {
+		return session.random
+	}
+	if g == nil {
+		return nil
+	}
+	return g.randomSource
+}
+
+// randomIntn returns a number in [0, n) from the session or instance source, else the global source
+func (g *Golem) randomIntn(session *ChatSession, n int) int {
+	if source := g.randomSourceFor(session); source != nil {
+		return source.Intn(n)
+	}
+	return rand.Intn(n)
+}
+
+// randomInt63n returns a number in [0, n) from the instance source, else the global source
+func (g *Golem) randomInt63n(n int64) int64 {
+	if source := g.randomSourceFor(nil); source != nil {
+		return source.Int63n(n)
+	}
+	return rand.Int63n(n)
+}
+
+// SetRandomSeed gives the session its own random source so replaying the same inputs
+// produces the same <random> and <shuffle> choices regardless of other sessions
+func (session *ChatSession) SetRandomSeed(seed int64) {
+	session.RandomSeed = seed
+	session.random = rand.New(rand.NewSource(seed))
+}
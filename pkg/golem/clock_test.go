@@ -0,0 +1,177 @@
+package golem
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+const clockTestAIML = `<aiml version="2.0">
+<category><pattern>DATE</pattern><template><date format="%Y-%m-%d"/></template></category>
+<category><pattern>TIME</pattern><template><time format="%H:%M"/></template></category>
+<category><pattern>PICK</pattern><template><random><li>a</li><li>b</li><li>c</li><li>d</li><li>e</li></random></template></category>
+<category><pattern>MIX</pattern><template><shuffle>one two three four five six</shuffle></template></category>
+</aiml>`
+
+// newTestBot creates a bot with the given options and loads aiml into it
+func newTestBot(t *testing.T, aiml string, options ...Option) *Golem {
+	t.Helper()
+	g := New(false, options...)
+	if err := g.LoadAIMLFromString(aiml); err != nil {
+		t.Fatalf("LoadAIMLFromString failed: %v", err)
+	}
+	return g
+}
+
+// newTestBotFromDirectory creates a bot with the given options and loads a bot directory into it
+func newTestBotFromDirectory(t *testing.T, dir string, options ...Option) *Golem {
+	t.Helper()
+	g := New(false, options...)
+	kb, err := g.LoadAIMLFromDirectory(dir)
+	if err != nil {
+		t.Fatalf("LoadAIMLFromDirectory failed: %v", err)
+	}
+	g.SetKnowledgeBase(kb)
+	return g
+}
+
+// askN sends the same input n times and collects the responses
+func askN(t *testing.T, g *Golem, session *ChatSession, input string, n int) []string {
+	t.Helper()
+	var responses []string
+	for i := 0; i < n; i++ {
+		response, err := g.ProcessInput(input, session)
+		if err != nil {
+			t.Fatalf("ProcessInput failed: %v", err)
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+func TestWithClock(t *testing.T) {
+	clock := NewFixedClock(time.Date(2030, 7, 14, 9, 5, 0, 0, time.UTC))
+	g := newTestBot(t, clockTestAIML, WithClock(clock))
+	session := g.CreateSession("clock")
+
+	if got := askN(t, g, session, "date", 1)[0]; got != "2030-07-14" {
+		t.Errorf("Expected fixed date, got %q", got)
+	}
+	if got := askN(t, g, session, "time", 1)[0]; got != "09:05" {
+		t.Errorf("Expected fixed time, got %q", got)
+	}
+
+	// Moving the clock is visible to the next response
+	clock.Time = clock.Time.Add(24 * time.Hour)
+	if got := askN(t, g, session, "date", 1)[0]; got != "2030-07-15" {
+		t.Errorf("Expected advanced date, got %q", got)
+	}
+
+	g.SetClock(nil)
+	if _, ok := g.GetClock().(systemClock); !ok {
+		t.Error("Expected the system clock after SetClock(nil)")
+	}
+}
+
+func TestWithRandIsReproducible(t *testing.T) {
+	run := func() []string {
+		g := newTestBot(t, clockTestAIML, WithRand(rand.New(rand.NewSource(42))))
+		session := g.CreateSession("rand")
+		return askN(t, g, session, "pick", 10)
+	}
+
+	first, second := run(), run()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("Expected identical sequences for the same seed, got %v and %v", first, second)
+		}
+	}
+}
+
+func TestSessionRandomSeed(t *testing.T) {
+	g := newTestBot(t, clockTestAIML, WithRand(rand.New(rand.NewSource(1))))
+
+	// Two sessions with the same seed give the same choices even when interleaved
+	a := g.CreateSession("a")
+	b := g.CreateSession("b")
+	a.SetRandomSeed(7)
+	b.SetRandomSeed(7)
+
+	var fromA, fromB []string
+	for i := 0; i < 8; i++ {
+		fromA = append(fromA, askN(t, g, a, "pick", 1)[0])
+		fromB = append(fromB, askN(t, g, b, "pick", 1)[0])
+	}
+	for i := range fromA {
+		if fromA[i] != fromB[i] {
+			t.Fatalf("Expected sessions with the same seed to match, got %v and %v", fromA, fromB)
+		}
+	}
+	if a.RandomSeed != 7 {
+		t.Errorf("Expected RandomSeed to be recorded, got %d", a.RandomSeed)
+	}
+}
+
+func TestShuffleUsesSessionSeed(t *testing.T) {
+	shuffleWith := func(seed int64) string {
+		g := newTestBot(t, clockTestAIML)
+		session := g.CreateSession("shuffle")
+		session.SetRandomSeed(seed)
+		return askN(t, g, session, "mix", 1)[0]
+	}
+
+	if first, second := shuffleWith(5), shuffleWith(5); first != second {
+		t.Errorf("Expected the same shuffle for the same seed, got %q and %q", first, second)
+	}
+}
+
+func TestRegexRandomUsesSessionSeed(t *testing.T) {
+	pickWith := func(seed int64) []string {
+		g := newTestBot(t, clockTestAIML)
+		session := g.CreateSession("regex")
+		session.SetRandomSeed(seed)
+		ctx := &VariableContext{Session: session, KnowledgeBase: g.GetKnowledgeBase()}
+
+		var picks []string
+		for i := 0; i < 10; i++ {
+			response, err := g.GetConsolidatedProcessor().ProcessTemplate("<random><li>a</li><li>b</li><li>c</li><li>d</li><li>e</li></random>", nil, ctx)
+			if err != nil {
+				t.Fatalf("ProcessTemplate failed: %v", err)
+			}
+			picks = append(picks, response)
+		}
+		return picks
+	}
+
+	first, second := pickWith(9), pickWith(9)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("Expected the same choices for the same seed, got %v and %v", first, second)
+		}
+	}
+}
+
+func TestTextHelpersUseRandomSource(t *testing.T) {
+	shuffle := func() (string, int) {
+		g := New(false, WithRand(rand.New(rand.NewSource(3))))
+		return NewTextProcessing(g).ShuffleText("one two three four five six"), NewUtilities(g).RandomInt(1000)
+	}
+
+	firstText, firstInt := shuffle()
+	secondText, secondInt := shuffle()
+	if firstText != secondText || firstInt != secondInt {
+		t.Errorf("Expected the same results from the same source, got %q, %d and %q, %d", firstText, firstInt, secondText, secondInt)
+	}
+}
+
+func TestSessionTimesUseClock(t *testing.T) {
+	clock := NewFixedClock(time.Date(2030, 7, 14, 9, 5, 0, 0, time.UTC))
+	g := newTestBot(t, clockTestAIML, WithClock(clock))
+	session := g.CreateSession("times")
+	askN(t, g, session, "date", 1)
+
+	expected := clock.Time.Format(time.RFC3339)
+	if session.CreatedAt != expected || session.LastActivity != expected {
+		t.Errorf("Expected session times %s, got created %s and last active %s", expected, session.CreatedAt, session.LastActivity)
+	}
+}
@@ -478,6 +478,10 @@ func (p *ComprehensiveDataProcessor) Condition() ProcessorCondition {
 }
 func (p *ComprehensiveDataProcessor) Process(template string, wildcards map[string]string, ctx *VariableContext) (string, error) {
 	response := template
+	var session *ChatSession
+	if ctx != nil {
+		session = ctx.Session
+	}
 	maxIterations := 10
 	iteration := 0
 
@@ -487,10 +491,10 @@ func (p *ComprehensiveDataProcessor) Process(template string, wildcards map[stri
 		originalResponse := response
 
 		// Process date and time tags
-		response = p.golem.processDateTimeTags(response)
+		response = p.golem.processDateTimeTagsWithSession(response, session)
 
 		// Process random tags
-		response = p.golem.processRandomTags(response)
+		response = p.golem.processRandomTags(response, session)
 
 		// Process first tags
 		response = p.processFirstTags(response, ctx)
@@ -3,6 +3,7 @@ package golem
 import (
 	"math"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -569,6 +570,72 @@ func (s *SemanticContextMatcher) InitializeDomainMappings() {
 	}
 }
 
+// AddSynonyms records synonyms of word in both directions. Words are stored upper case, the form
+// MatchWithSemanticSimilarity compares.
+func (s *SemanticContextMatcher) AddSynonyms(word string, synonyms ...string) {
+	addWordRelations(s.Synonyms, word, synonyms)
+}
+
+// AddAntonyms records antonyms of word in both directions
+func (s *SemanticContextMatcher) AddAntonyms(word string, antonyms ...string) {
+	addWordRelations(s.Antonyms, word, antonyms)
+}
+
+// AddDomain adds words to a domain mapping
+func (s *SemanticContextMatcher) AddDomain(name string, words ...string) {
+	name = strings.ToUpper(name)
+	for _, word := range words {
+		s.DomainMappings[name] = appendRelatedWords(s.DomainMappings[name], []string{strings.ToUpper(word)})
+	}
+}
+
+// addWordRelations links word and each related word both ways in relations
+func addWordRelations(relations map[string][]string, word string, related []string) {
+	word = strings.ToUpper(strings.TrimSpace(word))
+	for _, other := range related {
+		other = strings.ToUpper(strings.TrimSpace(other))
+		if other == "" || other == word {
+			continue
+		}
+		relations[word] = appendRelatedWords(relations[word], []string{other})
+		relations[other] = appendRelatedWords(relations[other], []string{word})
+	}
+}
+
+// SynonymsOf returns the synonyms of word from both the built-in and the added tables
+func (s *SemanticContextMatcher) SynonymsOf(word string) []string {
+	return relatedWords(s.Synonyms, word)
+}
+
+// AntonymsOf returns the antonyms of word from both the built-in and the added tables
+func (s *SemanticContextMatcher) AntonymsOf(word string) []string {
+	return relatedWords(s.Antonyms, word)
+}
+
+// DomainOf returns the first domain, in name order, that word belongs to
+func (s *SemanticContextMatcher) DomainOf(word string) string {
+	word = strings.ToUpper(strings.TrimSpace(word))
+	names := make([]string, 0, len(s.DomainMappings))
+	for name := range s.DomainMappings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if containsString(s.DomainMappings[name], word) {
+			return name
+		}
+	}
+	return ""
+}
+
+// relatedWords looks word up under its upper-case form (added entries) and its lower-case form
+// (built-in entries)
+func relatedWords(relations map[string][]string, word string) []string {
+	word = strings.TrimSpace(word)
+	related := appendRelatedWords(nil, relations[strings.ToUpper(word)])
+	return appendRelatedWords(related, relations[strings.ToLower(word)])
+}
+
 // MatchWithSemanticSimilarity performs semantic similarity matching
 func (s *SemanticContextMatcher) MatchWithSemanticSimilarity(context, pattern string) (bool, float64) {
 	// Normalize both strings
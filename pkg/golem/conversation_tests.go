@@ -0,0 +1,499 @@
+package golem
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ConversationTestFile is a declarative set of conversations run against a bot.
+// Clock, seed, predicates and topic apply to every conversation unless overridden.
+type ConversationTestFile struct {
+	Name          string             `json:"name"`
+	Clock         string             `json:"clock,omitempty"` // RFC 3339 time returned by <date> and <time>
+	Seed          int64              `json:"seed,omitempty"`  // seed for <random> and other random choices
+	Predicates    map[string]string  `json:"predicates,omitempty"`
+	Topic         string             `json:"topic,omitempty"`
+	Conversations []ConversationTest `json:"conversations"`
+
+	path string
+}
+
+// ConversationTest is a single conversation, run in a fresh session
+type ConversationTest struct {
+	Name       string             `json:"name"`
+	Clock      string             `json:"clock,omitempty"`
+	Seed       *int64             `json:"seed,omitempty"`
+	Predicates map[string]string  `json:"predicates,omitempty"`
+	Topic      string             `json:"topic,omitempty"`
+	Turns      []ConversationTurn `json:"turns"`
+}
+
+// ConversationTurn is one input and the expected response.
+// Exactly one of Expect, Regex, Contains or OneOf must be set.
+type ConversationTurn struct {
+	Input    string   `json:"input"`
+	Expect   *string  `json:"expect,omitempty"`   // exact response
+	Regex    string   `json:"regex,omitempty"`    // regular expression the response must match
+	Contains string   `json:"contains,omitempty"` // substring the response must contain
+	OneOf    []string `json:"one_of,omitempty"`   // any of these exact responses, for <random>
+
+	regex *regexp.Regexp
+}
+
+// TurnResult is the outcome of a single turn
+type TurnResult struct {
+	Index    int    `json:"index"`
+	Input    string `json:"input"`
+	Mode     string `json:"mode"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+	Passed   bool   `json:"passed"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ConversationResult is the outcome of a conversation
+type ConversationResult struct {
+	Name     string        `json:"name"`
+	Passed   bool          `json:"passed"`
+	Turns    []TurnResult  `json:"turns"`
+	Duration time.Duration `json:"duration"`
+}
+
+// ConversationSuiteResult is the outcome of a test file
+type ConversationSuiteResult struct {
+	Name          string               `json:"name"`
+	File          string               `json:"file"`
+	Conversations []ConversationResult `json:"conversations"`
+	Duration      time.Duration        `json:"duration"`
+}
+
+// ConversationTestReport aggregates the results of one or more test files
+type ConversationTestReport struct {
+	Suites []ConversationSuiteResult `json:"suites"`
+}
+
+// LoadConversationTestFile reads and validates a JSON conversation test file
+func LoadConversationTestFile(path string) (*ConversationTestFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test file %s: %v", path, err)
+	}
+
+	var file ConversationTestFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse test file %s: %v", path, err)
+	}
+	file.path = path
+	if file.Name == "" {
+		file.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	if err := file.validate(); err != nil {
+		return nil, fmt.Errorf("invalid test file %s: %v", path, err)
+	}
+	return &file, nil
+}
+
+// validate checks clocks and expectations and compiles regular expressions
+func (f *ConversationTestFile) validate() error {
+	if _, err := parseTestClock(f.Clock); err != nil {
+		return err
+	}
+	for i := range f.Conversations {
+		conversation := &f.Conversations[i]
+		if conversation.Name == "" {
+			conversation.Name = fmt.Sprintf("conversation %d", i+1)
+		}
+		if _, err := parseTestClock(conversation.Clock); err != nil {
+			return fmt.Errorf("%s: %v", conversation.Name, err)
+		}
+		if len(conversation.Turns) == 0 {
+			return fmt.Errorf("%s: no turns", conversation.Name)
+		}
+		for j := range conversation.Turns {
+			if err := conversation.Turns[j].compile(); err != nil {
+				return fmt.Errorf("%s, turn %d: %v", conversation.Name, j+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// compile checks that the turn has exactly one expectation and compiles its regex
+func (t *ConversationTurn) compile() error {
+	count := 0
+	if t.Expect != nil {
+		count++
+	}
+	if t.Regex != "" {
+		count++
+	}
+	if t.Contains != "" {
+		count++
+	}
+	if len(t.OneOf) > 0 {
+		count++
+	}
+	if count != 1 {
+		return fmt.Errorf("expected exactly one of expect, regex, contains or one_of, got %d", count)
+	}
+
+	if t.Regex != "" {
+		compiled, err := regexp.Compile(t.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid regex %q: %v", t.Regex, err)
+		}
+		t.regex = compiled
+	}
+	return nil
+}
+
+// mode returns the matching mode of the turn
+func (t *ConversationTurn) mode() string {
+	switch {
+	case t.Expect != nil:
+		return "exact"
+	case t.Regex != "":
+		return "regex"
+	case t.Contains != "":
+		return "contains"
+	default:
+		return "one_of"
+	}
+}
+
+// expected returns the expectation as display text
+func (t *ConversationTurn) expected() string {
+	switch {
+	case t.Expect != nil:
+		return *t.Expect
+	case t.Regex != "":
+		return t.Regex
+	case t.Contains != "":
+		return t.Contains
+	default:
+		return strings.Join(t.OneOf, " | ")
+	}
+}
+
+// matches reports whether a response satisfies the turn
+func (t *ConversationTurn) matches(response string) bool {
+	response = strings.TrimSpace(response)
+	switch {
+	case t.Expect != nil:
+		return response == strings.TrimSpace(*t.Expect)
+	case t.regex != nil:
+		return t.regex.MatchString(response)
+	case t.Contains != "":
+		return strings.Contains(response, t.Contains)
+	default:
+		for _, option := range t.OneOf {
+			if response == strings.TrimSpace(option) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// parseTestClock parses an RFC 3339 clock; an empty string means the wall clock
+func parseTestClock(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	clock, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid clock %q (expected RFC 3339, e.g. 2024-01-02T15:04:05Z): %v", value, err)
+	}
+	return &clock, nil
+}
+
+// RunConversationTests runs every conversation of a test file against the loaded knowledge base
+func (g *Golem) RunConversationTests(file *ConversationTestFile) *ConversationSuiteResult {
+	start := time.Now()
+	suite := &ConversationSuiteResult{
+		Name:          file.Name,
+		File:          file.path,
+		Conversations: make([]ConversationResult, 0, len(file.Conversations)),
+	}
+
+	// The clock is restored so tests do not leak into later use
+	previousClock := g.clock
+	defer g.SetClock(previousClock)
+
+	for i := range file.Conversations {
+		g.SetClock(previousClock)
+		suite.Conversations = append(suite.Conversations, g.runConversationTest(file, &file.Conversations[i], i))
+	}
+
+	suite.Duration = time.Since(start)
+	return suite
+}
+
+// runConversationTest runs one conversation in a fresh session
+func (g *Golem) runConversationTest(file *ConversationTestFile, conversation *ConversationTest, index int) ConversationResult {
+	start := time.Now()
+	result := ConversationResult{Name: conversation.Name, Passed: true}
+
+	clockValue := file.Clock
+	if conversation.Clock != "" {
+		clockValue = conversation.Clock
+	}
+	if clock, _ := parseTestClock(clockValue); clock != nil {
+		g.SetClock(NewFixedClock(*clock))
+	}
+
+	seed := file.Seed
+	if conversation.Seed != nil {
+		seed = *conversation.Seed
+	}
+
+	session := g.createSession(fmt.Sprintf("test_%s_%d", file.Name, index))
+	defer g.discardSession(session.ID)
+	session.SetRandomSeed(seed)
+	for key, value := range file.Predicates {
+		session.Variables[key] = value
+	}
+	for key, value := range conversation.Predicates {
+		session.Variables[key] = value
+	}
+	if conversation.Topic != "" {
+		session.SetSessionTopic(conversation.Topic)
+	} else if file.Topic != "" {
+		session.SetSessionTopic(file.Topic)
+	}
+
+	for i := range conversation.Turns {
+		turn := &conversation.Turns[i]
+		turnResult := TurnResult{
+			Index:    i + 1,
+			Input:    turn.Input,
+			Mode:     turn.mode(),
+			Expected: turn.expected(),
+		}
+
+		response, err := g.ProcessInput(turn.Input, session)
+		turnResult.Actual = strings.TrimSpace(response)
+		if err != nil {
+			turnResult.Error = err.Error()
+		} else {
+			turnResult.Passed = turn.matches(response)
+		}
+		if !turnResult.Passed {
+			result.Passed = false
+		}
+		result.Turns = append(result.Turns, turnResult)
+	}
+
+	result.Duration = time.Since(start)
+	return result
+}
+
+// discardSession removes a temporary session created by the test runner
+func (g *Golem) discardSession(sessionID string) {
+	g.sessionMutex.Lock()
+	defer g.sessionMutex.Unlock()
+	delete(g.sessions, sessionID)
+	if g.currentID == sessionID {
+		g.currentID = ""
+	}
+}
+
+// RunConversationTestPath runs a test file, or every .json test file in a directory
+func (g *Golem) RunConversationTestPath(path string) (*ConversationTestReport, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("test path does not exist: %s", path)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files, err = filepath.Glob(filepath.Join(path, "*.json"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list test files in %s: %v", path, err)
+		}
+		sort.Strings(files)
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no .json test files found in %s", path)
+		}
+	}
+
+	report := &ConversationTestReport{Suites: make([]ConversationSuiteResult, 0, len(files))}
+	for _, name := range files {
+		file, err := LoadConversationTestFile(name)
+		if err != nil {
+			return nil, err
+		}
+		report.Suites = append(report.Suites, *g.RunConversationTests(file))
+	}
+	return report, nil
+}
+
+// Counts returns the number of conversations and failed conversations in the report
+func (r *ConversationTestReport) Counts() (total int, failed int) {
+	for _, suite := range r.Suites {
+		for _, conversation := range suite.Conversations {
+			total++
+			if !conversation.Passed {
+				failed++
+			}
+		}
+	}
+	return total, failed
+}
+
+// Passed reports whether every conversation passed
+func (r *ConversationTestReport) Passed() bool {
+	_, failed := r.Counts()
+	return failed == 0
+}
+
+// String formats the report with a diff for each failing turn
+func (r *ConversationTestReport) String() string {
+	var sb strings.Builder
+	for _, suite := range r.Suites {
+		sb.WriteString(fmt.Sprintf("%s (%s)\n", suite.Name, suite.File))
+		for _, conversation := range suite.Conversations {
+			status := "PASS"
+			if !conversation.Passed {
+				status = "FAIL"
+			}
+			sb.WriteString(fmt.Sprintf("  %s  %s\n", status, conversation.Name))
+			for _, turn := range conversation.Turns {
+				if !turn.Passed {
+					sb.WriteString(indentLines(turn.Diff(), "        "))
+				}
+			}
+		}
+	}
+
+	total, failed := r.Counts()
+	sb.WriteString(fmt.Sprintf("\n%d conversations, %d passed, %d failed\n", total, total-failed, failed))
+	return sb.String()
+}
+
+// Diff describes how the actual response differs from the expectation
+func (t TurnResult) Diff() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("turn %d: %q\n", t.Index, t.Input))
+	if t.Error != "" {
+		sb.WriteString(fmt.Sprintf("  error: %s\n", t.Error))
+		return sb.String()
+	}
+	sb.WriteString(fmt.Sprintf("  - expected (%s): %s\n", t.Mode, t.Expected))
+	sb.WriteString(fmt.Sprintf("  + actual:           %s\n", t.Actual))
+	if t.Mode == "exact" {
+		column := firstDifference(t.Expected, t.Actual)
+		sb.WriteString(fmt.Sprintf("  first difference at column %d\n", column+1))
+	}
+	return sb.String()
+}
+
+// firstDifference returns the index of the first differing rune
+func firstDifference(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	i := 0
+	for i < len(ra) && i < len(rb) && ra[i] == rb[i] {
+		i++
+	}
+	return i
+}
+
+// indentLines prefixes every line of text with indent
+func indentLines(text, indent string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = indent + line
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML, one testcase per conversation
+func (r *ConversationTestReport) WriteJUnit(w io.Writer) error {
+	root := junitTestSuites{}
+	for _, suite := range r.Suites {
+		junitSuite := junitTestSuite{
+			Name: suite.Name,
+			Time: formatJUnitSeconds(suite.Duration),
+		}
+		for _, conversation := range suite.Conversations {
+			testCase := junitTestCase{
+				Name:      conversation.Name,
+				ClassName: suite.Name,
+				Time:      formatJUnitSeconds(conversation.Duration),
+			}
+			if !conversation.Passed {
+				var diffs []string
+				failedTurns := 0
+				for _, turn := range conversation.Turns {
+					if !turn.Passed {
+						failedTurns++
+						diffs = append(diffs, turn.Diff())
+					}
+				}
+				testCase.Failure = &junitFailure{
+					Message: fmt.Sprintf("%d of %d turns failed", failedTurns, len(conversation.Turns)),
+					Text:    strings.Join(diffs, "\n"),
+				}
+				junitSuite.Failures++
+			}
+			junitSuite.Tests++
+			junitSuite.Cases = append(junitSuite.Cases, testCase)
+		}
+		root.Tests += junitSuite.Tests
+		root.Failures += junitSuite.Failures
+		root.Suites = append(root.Suites, junitSuite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(root); err != nil {
+		return fmt.Errorf("failed to encode JUnit report: %v", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// formatJUnitSeconds formats a duration as JUnit seconds
+func formatJUnitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,228 @@
+package golem
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConversationTestBot writes a small bot and returns its directory
+func writeConversationTestBot(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<aiml version="2.0">
+    <category>
+        <pattern>HELLO</pattern>
+        <template>Hello, <get name="name"/>!</template>
+    </category>
+    <category>
+        <pattern>MY NAME IS *</pattern>
+        <template><think><set name="name"><star/></set></think>Nice to meet you, <star/>.</template>
+    </category>
+    <category>
+        <pattern>PICK</pattern>
+        <template><random><li>red</li><li>green</li><li>blue</li></random></template>
+    </category>
+    <category>
+        <pattern>WHAT YEAR IS IT</pattern>
+        <template>It is <date format="%Y"/>.</template>
+    </category>
+    <category>
+        <pattern>FAVORITE</pattern>
+        <topic>SPORTS</topic>
+        <template>Basketball</template>
+    </category>
+</aiml>`
+	if err := os.WriteFile(filepath.Join(dir, "bot.aiml"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write bot: %v", err)
+	}
+	return dir
+}
+
+// writeConversationTestFile writes a JSON test file and returns its path
+func writeConversationTestFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "greetings.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	return path
+}
+
+const conversationTestContent = `{
+  "name": "greetings",
+  "clock": "2031-05-06T10:00:00Z",
+  "seed": 7,
+  "predicates": {"name": "friend"},
+  "conversations": [
+    {
+      "name": "greets by name",
+      "turns": [
+        {"input": "hello", "expect": "Hello, friend!"},
+        {"input": "my name is Ada", "regex": "^Nice to meet you, [Aa]da\\.$"},
+        {"input": "hello", "contains": "Ada"}
+      ]
+    },
+    {
+      "name": "random and clock",
+      "turns": [
+        {"input": "pick", "one_of": ["red", "green", "blue"]},
+        {"input": "what year is it", "expect": "It is 2031."}
+      ]
+    },
+    {
+      "name": "topic preset",
+      "topic": "SPORTS",
+      "turns": [
+        {"input": "favorite", "expect": "Basketball"}
+      ]
+    }
+  ]
+}`
+
+func TestConversationTestsPass(t *testing.T) {
+	g := New(false)
+	if err := g.loadBotDirectory(writeConversationTestBot(t)); err != nil {
+		t.Fatalf("Failed to load bot: %v", err)
+	}
+
+	report, err := g.RunConversationTestPath(writeConversationTestFile(t, conversationTestContent))
+	if err != nil {
+		t.Fatalf("RunConversationTestPath failed: %v", err)
+	}
+	if !report.Passed() {
+		t.Errorf("Expected all conversations to pass:\n%s", report.String())
+	}
+	if total, _ := report.Counts(); total != 3 {
+		t.Errorf("Expected 3 conversations, got %d", total)
+	}
+
+	// The runner must not leave its clock or sessions behind
+	if g.clock != nil {
+		t.Error("Expected the clock to be restored")
+	}
+	if len(g.sessions) != 0 {
+		t.Errorf("Expected test sessions to be removed, got %d", len(g.sessions))
+	}
+}
+
+func TestConversationTestsSeedIsReproducible(t *testing.T) {
+	path := writeConversationTestFile(t, `{"seed": 3, "conversations": [{"name": "pick", "turns": [
+		{"input": "pick", "one_of": ["red", "green", "blue"]},
+		{"input": "pick", "one_of": ["red", "green", "blue"]},
+		{"input": "pick", "one_of": ["red", "green", "blue"]}
+	]}]}`)
+
+	var runs [][]string
+	for i := 0; i < 2; i++ {
+		g := New(false)
+		if err := g.loadBotDirectory(writeConversationTestBot(t)); err != nil {
+			t.Fatalf("Failed to load bot: %v", err)
+		}
+		report, err := g.RunConversationTestPath(path)
+		if err != nil {
+			t.Fatalf("RunConversationTestPath failed: %v", err)
+		}
+		var responses []string
+		for _, turn := range report.Suites[0].Conversations[0].Turns {
+			responses = append(responses, turn.Actual)
+		}
+		runs = append(runs, responses)
+	}
+
+	if strings.Join(runs[0], ",") != strings.Join(runs[1], ",") {
+		t.Errorf("Expected the same random choices for the same seed, got %v and %v", runs[0], runs[1])
+	}
+}
+
+func TestConversationTestsFailureDiffAndJUnit(t *testing.T) {
+	g := New(false)
+	if err := g.loadBotDirectory(writeConversationTestBot(t)); err != nil {
+		t.Fatalf("Failed to load bot: %v", err)
+	}
+
+	path := writeConversationTestFile(t, `{"name": "broken", "conversations": [
+		{"name": "wrong greeting", "predicates": {"name": "Bob"}, "turns": [
+			{"input": "hello", "expect": "Hello, Bill!"},
+			{"input": "hello", "regex": "^Hello"}
+		]}
+	]}`)
+	report, err := g.RunConversationTestPath(path)
+	if err != nil {
+		t.Fatalf("RunConversationTestPath failed: %v", err)
+	}
+	if report.Passed() {
+		t.Fatal("Expected the conversation to fail")
+	}
+
+	turns := report.Suites[0].Conversations[0].Turns
+	if turns[0].Passed || !turns[1].Passed {
+		t.Errorf("Expected only the first turn to fail: %+v", turns)
+	}
+	diff := turns[0].Diff()
+	for _, want := range []string{"- expected (exact): Hello, Bill!", "+ actual:", "Hello, Bob!", "column 9"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("Expected diff to contain %q, got:\n%s", want, diff)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJUnit(&buf); err != nil {
+		t.Fatalf("WriteJUnit failed: %v", err)
+	}
+	var parsed junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &parsed); err != nil {
+		t.Fatalf("JUnit output is not valid XML: %v\n%s", err, buf.String())
+	}
+	if parsed.Tests != 1 || parsed.Failures != 1 {
+		t.Errorf("Expected 1 test and 1 failure, got %d/%d", parsed.Tests, parsed.Failures)
+	}
+	if parsed.Suites[0].Cases[0].Failure == nil || !strings.Contains(parsed.Suites[0].Cases[0].Failure.Text, "Hello, Bill!") {
+		t.Errorf("Expected failure text with the diff, got %+v", parsed.Suites[0].Cases[0])
+	}
+}
+
+func TestConversationTestFileValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"no expectation", `{"conversations": [{"turns": [{"input": "hi"}]}]}`, "exactly one of"},
+		{"two expectations", `{"conversations": [{"turns": [{"input": "hi", "expect": "a", "contains": "a"}]}]}`, "exactly one of"},
+		{"bad regex", `{"conversations": [{"turns": [{"input": "hi", "regex": "("}]}]}`, "invalid regex"},
+		{"bad clock", `{"clock": "tomorrow", "conversations": [{"turns": [{"input": "hi", "expect": ""}]}]}`, "invalid clock"},
+		{"no turns", `{"conversations": [{"name": "empty"}]}`, "no turns"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadConversationTestFile(writeConversationTestFile(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestTestCommand(t *testing.T) {
+	bot := writeConversationTestBot(t)
+	tests := writeConversationTestFile(t, conversationTestContent)
+	junit := filepath.Join(t.TempDir(), "report.xml")
+
+	g := New(false)
+	if err := g.Execute("test", []string{bot, tests, "--junit", junit}); err != nil {
+		t.Fatalf("test command failed: %v", err)
+	}
+	if _, err := os.Stat(junit); err != nil {
+		t.Errorf("Expected JUnit report to be written: %v", err)
+	}
+
+	if err := g.Execute("test", []string{bot}); err == nil {
+		t.Error("Expected error when no tests are given")
+	}
+}
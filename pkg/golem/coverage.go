@@ -0,0 +1,179 @@
+package golem
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// coverageTracker counts how often each category is matched, directly or through srai
+type coverageTracker struct {
+	mutex sync.Mutex
+	hits  map[string]int
+}
+
+// CategoryCoverage is the hit count of a single category
+type CategoryCoverage struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Pattern string `json:"pattern"`
+	That    string `json:"that,omitempty"`
+	Topic   string `json:"topic,omitempty"`
+	Hits    int    `json:"hits"`
+}
+
+// FileCoverage summarizes category coverage for one source file
+type FileCoverage struct {
+	File    string             `json:"file"`
+	Total   int                `json:"total"`
+	Covered int                `json:"covered"`
+	Percent float64            `json:"percent"`
+	Unused  []CategoryCoverage `json:"unused"`
+}
+
+// CoverageReport summarizes which categories were matched while coverage was enabled
+type CoverageReport struct {
+	Total   int            `json:"total"`
+	Covered int            `json:"covered"`
+	Percent float64        `json:"percent"`
+	Files   []FileCoverage `json:"files"`
+}
+
+// EnableCoverage starts counting category hits, discarding earlier counts
+func (g *Golem) EnableCoverage() {
+	g.coverage = &coverageTracker{hits: make(map[string]int)}
+}
+
+// DisableCoverage stops counting category hits
+func (g *Golem) DisableCoverage() {
+	g.coverage = nil
+}
+
+// IsCoverageEnabled returns whether category hits are being counted
+func (g *Golem) IsCoverageEnabled() bool {
+	return g.coverage != nil
+}
+
+// ResetCoverage clears the hit counts without disabling coverage
+func (g *Golem) ResetCoverage() {
+	if g.coverage == nil {
+		return
+	}
+	g.coverage.mutex.Lock()
+	defer g.coverage.mutex.Unlock()
+	g.coverage.hits = make(map[string]int)
+}
+
+// CategoryHits returns how often a category was matched since coverage was enabled
+func (g *Golem) CategoryHits(category Category) int {
+	if g.coverage == nil {
+		return 0
+	}
+	g.coverage.mutex.Lock()
+	defer g.coverage.mutex.Unlock()
+	return g.coverage.hits[coverageKey(&category)]
+}
+
+// recordCategoryHit counts a match of category when coverage is enabled
+func (g *Golem) recordCategoryHit(category *Category) {
+	if g.coverage == nil || category == nil {
+		return
+	}
+	g.coverage.mutex.Lock()
+	defer g.coverage.mutex.Unlock()
+	g.coverage.hits[coverageKey(category)]++
+}
+
+// coverageKey identifies a category by source position and key, so counts survive
+// knowledge base merges that copy categories
+func coverageKey(category *Category) string {
+	return fmt.Sprintf("%s:%d|%s", category.SourceFile, category.SourceLine, CategoryKey(*category))
+}
+
+// CoverageReport builds a coverage report for the loaded knowledge base, grouped by source file
+func (g *Golem) CoverageReport() *CoverageReport {
+	report := &CoverageReport{Files: make([]FileCoverage, 0)}
+	if g.aimlKB == nil {
+		return report
+	}
+
+	// A multilingual bot reports the categories of every language
+	var categories []*Category
+	for i := range g.aimlKB.Categories {
+		categories = append(categories, &g.aimlKB.Categories[i])
+	}
+	g.eachLanguageBot(func(bot *Golem) {
+		for i := range bot.aimlKB.Categories {
+			categories = append(categories, &bot.aimlKB.Categories[i])
+		}
+	})
+
+	files := make(map[string]*FileCoverage)
+	for _, category := range categories {
+		file := displaySourceFile(category.SourceFile)
+		fileCoverage, exists := files[file]
+		if !exists {
+			fileCoverage = &FileCoverage{File: file, Unused: make([]CategoryCoverage, 0)}
+			files[file] = fileCoverage
+		}
+
+		hits := g.CategoryHits(*category)
+		fileCoverage.Total++
+		report.Total++
+		if hits > 0 {
+			fileCoverage.Covered++
+			report.Covered++
+			continue
+		}
+		fileCoverage.Unused = append(fileCoverage.Unused, CategoryCoverage{
+			File:    file,
+			Line:    category.SourceLine,
+			Pattern: category.Pattern,
+			That:    category.That,
+			Topic:   category.Topic,
+		})
+	}
+
+	for _, fileCoverage := range files {
+		fileCoverage.Percent = coveragePercent(fileCoverage.Covered, fileCoverage.Total)
+		sort.SliceStable(fileCoverage.Unused, func(i, j int) bool {
+			return fileCoverage.Unused[i].Line < fileCoverage.Unused[j].Line
+		})
+		report.Files = append(report.Files, *fileCoverage)
+	}
+	sort.Slice(report.Files, func(i, j int) bool {
+		return report.Files[i].File < report.Files[j].File
+	})
+	report.Percent = coveragePercent(report.Covered, report.Total)
+
+	return report
+}
+
+// coveragePercent returns covered/total as a percentage rounded to one decimal
+func coveragePercent(covered, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(int(float64(covered)*1000/float64(total)+0.5)) / 10
+}
+
+// String formats the report, listing unused categories by file and line
+func (r *CoverageReport) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Category coverage: %d/%d (%.1f%%)\n", r.Covered, r.Total, r.Percent))
+	for _, file := range r.Files {
+		sb.WriteString(fmt.Sprintf("  %s: %d/%d (%.1f%%)\n", file.File, file.Covered, file.Total, file.Percent))
+		for _, unused := range file.Unused {
+			sb.WriteString(fmt.Sprintf("    unused %s:%d  %s", unused.File, unused.Line, unused.Pattern))
+			if unused.That != "" {
+				sb.WriteString(fmt.Sprintf("  that=%s", unused.That))
+			}
+			if unused.Topic != "" {
+				sb.WriteString(fmt.Sprintf("  topic=%s", unused.Topic))
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,140 @@
+package golem
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeCoverageBot writes a bot whose categories sit on known lines
+func writeCoverageBot(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	file := filepath.Join(dir, "bot.aiml")
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<aiml version="2.0">
+<!-- greetings -->
+<category>
+    <pattern>HELLO</pattern>
+    <template>Hi there</template>
+</category>
+<category>
+    <pattern>HI</pattern>
+    <template><srai>HELLO</srai></template>
+</category>
+<category>
+    <pattern>GOODBYE</pattern>
+    <template>Bye</template>
+</category>
+<category>
+    <pattern>SAY *</pattern>
+    <template><sr/></template>
+</category>
+</aiml>`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write bot: %v", err)
+	}
+	return dir, file
+}
+
+func TestCategorySourceLines(t *testing.T) {
+	g := New(false)
+	_, file := writeCoverageBot(t)
+
+	kb, err := g.LoadAIML(file)
+	if err != nil {
+		t.Fatalf("LoadAIML failed: %v", err)
+	}
+
+	expected := map[string]int{"HELLO": 4, "HI": 8, "GOODBYE": 12, "SAY *": 16}
+	for _, category := range kb.Categories {
+		if line := expected[category.Pattern]; category.SourceLine != line {
+			t.Errorf("Expected %s on line %d, got %d", category.Pattern, line, category.SourceLine)
+		}
+	}
+}
+
+func TestCoverageCountsDirectAndSraiHits(t *testing.T) {
+	g := New(false)
+	dir, file := writeCoverageBot(t)
+	if err := g.loadBotDirectory(dir); err != nil {
+		t.Fatalf("Failed to load bot: %v", err)
+	}
+
+	// Hits are only counted while coverage is enabled
+	session := g.CreateSession("coverage")
+	if _, err := g.ProcessInput("goodbye", session); err != nil {
+		t.Fatalf("ProcessInput failed: %v", err)
+	}
+
+	g.EnableCoverage()
+	for _, input := range []string{"hi", "hi", "say hello"} {
+		if _, err := g.ProcessInput(input, session); err != nil {
+			t.Fatalf("ProcessInput failed: %v", err)
+		}
+	}
+
+	kb := g.GetKnowledgeBase()
+	hits := make(map[string]int)
+	for _, category := range kb.Categories {
+		hits[category.Pattern] = g.CategoryHits(category)
+	}
+	// HELLO is reached twice through <srai> and once through <sr/>
+	if hits["HELLO"] != 3 || hits["HI"] != 2 || hits["SAY *"] != 1 || hits["GOODBYE"] != 0 {
+		t.Errorf("Unexpected hit counts: %v", hits)
+	}
+
+	report := g.CoverageReport()
+	if report.Total != 4 || report.Covered != 3 || report.Percent != 75 {
+		t.Errorf("Unexpected totals: %+v", report)
+	}
+	if len(report.Files) != 1 || report.Files[0].File != file {
+		t.Fatalf("Expected one file entry for %s, got %+v", file, report.Files)
+	}
+	unused := report.Files[0].Unused
+	if len(unused) != 1 || unused[0].Pattern != "GOODBYE" || unused[0].Line != 12 {
+		t.Errorf("Expected GOODBYE on line 12 to be unused, got %+v", unused)
+	}
+	if text := report.String(); !strings.Contains(text, file+":12") || !strings.Contains(text, "75.0%") {
+		t.Errorf("Unexpected text report:\n%s", text)
+	}
+
+	g.ResetCoverage()
+	if g.CoverageReport().Covered != 0 {
+		t.Error("Expected ResetCoverage to clear hits")
+	}
+	g.DisableCoverage()
+	if g.IsCoverageEnabled() {
+		t.Error("Expected coverage to be disabled")
+	}
+}
+
+func TestTestCommandCoverage(t *testing.T) {
+	dir, _ := writeCoverageBot(t)
+	tests := writeConversationTestFile(t, `{"conversations": [{"name": "greeting", "turns": [
+		{"input": "hi", "expect": "Hi there"}
+	]}]}`)
+	coverageFile := filepath.Join(t.TempDir(), "coverage.json")
+
+	g := New(false)
+	if err := g.Execute("test", []string{"--coverage", "--coverage-json", coverageFile, dir, tests}); err != nil {
+		t.Fatalf("test command failed: %v", err)
+	}
+
+	data, err := os.ReadFile(coverageFile)
+	if err != nil {
+		t.Fatalf("Expected coverage JSON to be written: %v", err)
+	}
+	var report CoverageReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Invalid coverage JSON: %v", err)
+	}
+	if report.Covered != 2 || report.Total != 4 || report.Files[0].Percent != 50 {
+		t.Errorf("Unexpected coverage: %+v", report)
+	}
+	if len(report.Files[0].Unused) != 2 {
+		t.Errorf("Expected 2 unused categories, got %+v", report.Files[0].Unused)
+	}
+}
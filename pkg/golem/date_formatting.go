@@ -0,0 +1,417 @@
+package golem
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	_ "time/tzdata" // IANA zones for the timezone attribute on hosts without zoneinfo
+)
+
+// dateLocale holds the localized names used when formatting dates
+type dateLocale struct {
+	months      [12]string
+	shortMonths [12]string
+	days        [7]string // indexed by time.Weekday (Sunday first)
+	shortDays   [7]string
+	am, pm      string
+}
+
+// dateLocales lists the supported locales by language code
+var dateLocales = map[string]*dateLocale{
+	"en": {
+		months:      [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		shortMonths: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		days:        [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		shortDays:   [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+		am:          "AM",
+		pm:          "PM",
+	},
+	"es": {
+		months:      [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		shortMonths: [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+		days:        [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		shortDays:   [7]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+		am:          "a. m.",
+		pm:          "p. m.",
+	},
+	"fr": {
+		months:      [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		shortMonths: [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		days:        [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		shortDays:   [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+		am:          "AM",
+		pm:          "PM",
+	},
+	"de": {
+		months:      [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		shortMonths: [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		days:        [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		shortDays:   [7]string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+		am:          "AM",
+		pm:          "PM",
+	},
+}
+
+// lookupDateLocale finds a locale by tag ("es", "es-ES", "es_MX"); unknown locales fall back to English
+func lookupDateLocale(name string) (*dateLocale, bool) {
+	language := strings.ToLower(strings.TrimSpace(name))
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+	if locale, exists := dateLocales[language]; exists {
+		return locale, true
+	}
+	return dateLocales["en"], false
+}
+
+// dateTagAttributeRegex matches name="value" attributes, including escaped quotes
+var dateTagAttributeRegex = regexp.MustCompile(`([\w-]+)\s*=\s*\\?"([^"\\]*)\\?"`)
+
+// parseDateTagAttributes extracts the attributes of a <date> or <time> tag
+func parseDateTagAttributes(attributes string) map[string]string {
+	result := make(map[string]string)
+	for _, match := range dateTagAttributeRegex.FindAllStringSubmatch(attributes, -1) {
+		result[match[1]] = match[2]
+	}
+	return result
+}
+
+// localizedNow returns the current time in the requested timezone together with the requested locale.
+// The timezone and locale attributes win over the session's "timezone" and "locale" predicates;
+// without either the clock's own zone and English are used.
+func (g *Golem) localizedNow(attributes map[string]string, session *ChatSession) (time.Time, *dateLocale) {
+	now := g.now()
+
+	zone := attributes["timezone"]
+	if zone == "" && session != nil {
+		zone = session.Variables["timezone"]
+	}
+	if zone != "" {
+		if location, err := time.LoadLocation(zone); err == nil {
+			now = now.In(location)
+		} else {
+			g.LogWarn("Unknown timezone '%s', using %s", zone, now.Location())
+		}
+	}
+
+	name := attributes["locale"]
+	if name == "" && session != nil {
+		name = session.Variables["locale"]
+	}
+	locale, known := lookupDateLocale(name)
+	if !known && name != "" {
+		g.LogWarn("Unsupported locale '%s', using English month and day names", name)
+	}
+	return now, locale
+}
+
+// forEachJavaDateToken splits a Java SimpleDateFormat pattern into fields (runs of the same
+// letter) and literal text. Text in single quotes is literal; two single quotes make one.
+func forEachJavaDateToken(pattern string, field func(letter rune, count int), literal func(text string)) {
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '\'':
+			if i+1 < len(runes) && runes[i+1] == '\'' {
+				literal("'")
+				i += 2
+				continue
+			}
+			var text strings.Builder
+			i++
+			for i < len(runes) {
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						text.WriteRune('\'')
+						i += 2
+						continue
+					}
+					break
+				}
+				text.WriteRune(runes[i])
+				i++
+			}
+			i++ // closing quote
+			literal(text.String())
+		case (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+			count := 1
+			for i+count < len(runes) && runes[i+count] == r {
+				count++
+			}
+			field(r, count)
+			i += count
+		default:
+			literal(string(r))
+			i++
+		}
+	}
+}
+
+// padNumber formats n with at least width digits
+func padNumber(n, width int) string {
+	return fmt.Sprintf("%0*d", width, n)
+}
+
+// formatJavaDate formats t with a Java SimpleDateFormat pattern using the locale's names
+func formatJavaDate(t time.Time, pattern string, locale *dateLocale) string {
+	if locale == nil {
+		locale = dateLocales["en"]
+	}
+	var sb strings.Builder
+	forEachJavaDateToken(pattern, func(letter rune, count int) {
+		sb.WriteString(formatJavaDateField(t, letter, count, locale))
+	}, func(text string) {
+		sb.WriteString(text)
+	})
+	return sb.String()
+}
+
+// formatJavaDateField formats a single SimpleDateFormat field
+func formatJavaDateField(t time.Time, letter rune, count int, locale *dateLocale) string {
+	hour := t.Hour()
+	switch letter {
+	case 'G':
+		if t.Year() <= 0 {
+			return "BC"
+		}
+		return "AD"
+	case 'y', 'Y':
+		year := t.Year()
+		if letter == 'Y' {
+			year, _ = t.ISOWeek()
+		}
+		if count == 2 {
+			return padNumber(year%100, 2)
+		}
+		return padNumber(year, count)
+	case 'M', 'L':
+		switch {
+		case count >= 4:
+			return locale.months[t.Month()-1]
+		case count == 3:
+			return locale.shortMonths[t.Month()-1]
+		default:
+			return padNumber(int(t.Month()), count)
+		}
+	case 'w':
+		_, week := t.ISOWeek()
+		return padNumber(week, count)
+	case 'W':
+		first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()).Weekday()
+		return padNumber((t.Day()+int(first)-1)/7+1, count)
+	case 'D':
+		return padNumber(t.YearDay(), count)
+	case 'd':
+		return padNumber(t.Day(), count)
+	case 'F':
+		return padNumber((t.Day()-1)/7+1, count)
+	case 'E':
+		if count >= 4 {
+			return locale.days[t.Weekday()]
+		}
+		return locale.shortDays[t.Weekday()]
+	case 'u':
+		day := int(t.Weekday())
+		if day == 0 {
+			day = 7
+		}
+		return padNumber(day, count)
+	case 'a':
+		if hour < 12 {
+			return locale.am
+		}
+		return locale.pm
+	case 'H':
+		return padNumber(hour, count)
+	case 'k':
+		if hour == 0 {
+			hour = 24
+		}
+		return padNumber(hour, count)
+	case 'K':
+		return padNumber(hour%12, count)
+	case 'h':
+		if hour%12 == 0 {
+			return padNumber(12, count)
+		}
+		return padNumber(hour%12, count)
+	case 'm':
+		return padNumber(t.Minute(), count)
+	case 's':
+		return padNumber(t.Second(), count)
+	case 'S':
+		return padNumber(t.Nanosecond()/1000000, count)
+	case 'z':
+		name, _ := t.Zone()
+		return name
+	case 'Z':
+		return t.Format("-0700")
+	case 'X':
+		switch count {
+		case 1:
+			return t.Format("Z07")
+		case 2:
+			return t.Format("Z0700")
+		default:
+			return t.Format("Z07:00")
+		}
+	default:
+		// Letters SimpleDateFormat does not define are kept as text
+		return strings.Repeat(string(letter), count)
+	}
+}
+
+// goLayoutNameTokens are the Go layout elements that produce names, longest first
+var goLayoutNameTokens = []string{"January", "Monday", "Jan", "Mon", "PM", "pm"}
+
+// formatLocalizedLayout formats t with a Go layout, replacing month names, day names and
+// AM/PM markers with the locale's
+func formatLocalizedLayout(t time.Time, layout string, locale *dateLocale) string {
+	if locale == nil || locale == dateLocales["en"] {
+		return t.Format(layout)
+	}
+
+	var sb strings.Builder
+	rest := layout
+	for rest != "" {
+		position, token := -1, ""
+		for _, candidate := range goLayoutNameTokens {
+			if i := strings.Index(rest, candidate); i >= 0 && (position < 0 || i < position) {
+				position, token = i, candidate
+			}
+		}
+		if position < 0 {
+			sb.WriteString(t.Format(rest))
+			break
+		}
+		sb.WriteString(t.Format(rest[:position]))
+		switch token {
+		case "January":
+			sb.WriteString(locale.months[t.Month()-1])
+		case "Jan":
+			sb.WriteString(locale.shortMonths[t.Month()-1])
+		case "Monday":
+			sb.WriteString(locale.days[t.Weekday()])
+		case "Mon":
+			sb.WriteString(locale.shortDays[t.Weekday()])
+		case "PM":
+			sb.WriteString(formatJavaDateField(t, 'a', 1, locale))
+		case "pm":
+			sb.WriteString(strings.ToLower(formatJavaDateField(t, 'a', 1, locale)))
+		}
+		rest = rest[position+len(token):]
+	}
+	return sb.String()
+}
+
+// intervalDefaultLayouts are tried when <interval> has no <jformat>, starting with what <date> prints
+var intervalDefaultLayouts = []string{
+	"Monday, January 2, 2006",
+	"January 2, 2006",
+	"2006-01-02",
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"01/02/2006",
+}
+
+// computeInterval returns the whole number of style units (years, months, weeks, days, hours,
+// minutes or seconds) from one date to another. An empty date means now; the result is
+// negative when to is before from.
+func (g *Golem) computeInterval(jformat, style, from, to string, now time.Time) (string, error) {
+	unit := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(style)), "s")
+	if unit == "" {
+		unit = "day"
+	}
+	switch unit {
+	case "year", "month", "week", "day", "hour", "minute", "second":
+	default:
+		return "", fmt.Errorf("unknown style %q (expected years, months, weeks, days, hours, minutes or seconds)", style)
+	}
+
+	start, err := g.parseIntervalDate("from", from, jformat, now)
+	if err != nil {
+		return "", err
+	}
+	end, err := g.parseIntervalDate("to", to, jformat, now)
+	if err != nil {
+		return "", err
+	}
+
+	sign := 1
+	if end.Before(start) {
+		start, end = end, start
+		sign = -1
+	}
+
+	var count int
+	switch unit {
+	case "year":
+		count = monthsBetween(start, end) / 12
+	case "month":
+		count = monthsBetween(start, end)
+	case "week":
+		count = daysBetween(start, end) / 7
+	case "day":
+		count = daysBetween(start, end)
+	case "hour":
+		count = int(end.Sub(start) / time.Hour)
+	case "minute":
+		count = int(end.Sub(start) / time.Minute)
+	case "second":
+		count = int(end.Sub(start) / time.Second)
+	}
+	return fmt.Sprintf("%d", sign*count), nil
+}
+
+// parseIntervalDate parses the <from> or <to> value of an <interval>
+func (g *Golem) parseIntervalDate(label, value, jformat string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return now, nil
+	}
+
+	if jformat != "" {
+		layout := g.convertJavaToGoTimeFormat(jformat)
+		parsed, err := time.ParseInLocation(layout, value, now.Location())
+		if err != nil {
+			return time.Time{}, fmt.Errorf("cannot parse <%s> %q with jformat %q: %v", label, value, jformat, err)
+		}
+		return parsed, nil
+	}
+
+	for _, layout := range intervalDefaultLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse <%s> %q: add a <jformat> describing the date (for example %q)", label, value, "MMMM d, yyyy")
+}
+
+// timeOfDay returns how far into its day t is
+func timeOfDay(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+}
+
+// daysBetween counts whole calendar days from start to end (start must not be after end).
+// Calendar dates are compared so daylight saving changes do not lose a day.
+func daysBetween(start, end time.Time) int {
+	a := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(b.Sub(a) / (24 * time.Hour))
+	if days > 0 && timeOfDay(end) < timeOfDay(start) {
+		days-- // the last day is not complete
+	}
+	return days
+}
+
+// monthsBetween counts whole calendar months from start to end (start must not be after end)
+func monthsBetween(start, end time.Time) int {
+	months := (end.Year()-start.Year())*12 + int(end.Month()) - int(start.Month())
+	if months > 0 && (end.Day() < start.Day() || (end.Day() == start.Day() && timeOfDay(end) < timeOfDay(start))) {
+		months-- // the last month is not complete
+	}
+	return months
+}
@@ -0,0 +1,174 @@
+package golem
+
+import (
+	"testing"
+	"time"
+)
+
+const dateFormattingTestAIML = `<aiml version="2.0">
+<category><pattern>JDATE</pattern><template><date jformat="EEEE, d MMMM yyyy"/></template></category>
+<category><pattern>JTIME</pattern><template><time jformat="HH:mm z"/></template></category>
+<category><pattern>TOKYO</pattern><template><time timezone="Asia/Tokyo" jformat="HH:mm"/></template></category>
+<category><pattern>SPANISH</pattern><template><date locale="es" jformat="EEEE d 'de' MMMM"/></template></category>
+<category><pattern>GERMAN</pattern><template><date locale="de-DE" format="%A, %d. %B %Y"/></template></category>
+<category><pattern>THINKDATE</pattern><template><think><set name="today"><date jformat="yyyy-MM-dd"/></set></think><get name="today"/></template></category>
+</aiml>`
+
+func TestFormatJavaDate(t *testing.T) {
+	// Thursday 2024-02-01 at 13:05:09.042 UTC
+	moment := time.Date(2024, 2, 1, 13, 5, 9, 42000000, time.UTC)
+	en, _ := lookupDateLocale("en")
+
+	testCases := []struct {
+		pattern  string
+		expected string
+	}{
+		{"yyyy-MM-dd", "2024-02-01"},
+		{"yy/M/d", "24/2/1"},
+		{"EEEE, MMMM d, yyyy", "Thursday, February 1, 2024"},
+		{"EEE MMM dd", "Thu Feb 01"},
+		{"hh:mm a", "01:05 PM"},
+		{"H:mm:ss.SSS", "13:05:09.042"},
+		{"k K h", "13 1 1"},
+		{"D w u F W", "32 5 4 1 1"},
+		{"G yyyy", "AD 2024"},
+		{"'Week' w 'of' yyyy", "Week 5 of 2024"},
+		{"h 'o''clock'", "1 o'clock"},
+		{"''yy", "'24"},
+		{"Z X XXX z", "+0000 Z Z UTC"},
+		// Letters that used to be mangled by sequential replacement
+		{"MMMM 'at' a", "February at PM"},
+	}
+
+	for _, tc := range testCases {
+		if got := formatJavaDate(moment, tc.pattern, en); got != tc.expected {
+			t.Errorf("formatJavaDate(%q) = %q, expected %q", tc.pattern, got, tc.expected)
+		}
+	}
+
+	midnight := time.Date(2024, 2, 1, 0, 30, 0, 0, time.UTC)
+	if got := formatJavaDate(midnight, "k h a", en); got != "24 12 AM" {
+		t.Errorf("Expected midnight hours, got %q", got)
+	}
+}
+
+func TestFormatJavaDateLocales(t *testing.T) {
+	moment := time.Date(2024, 8, 4, 18, 0, 0, 0, time.UTC) // Sunday
+
+	testCases := []struct {
+		locale   string
+		expected string
+	}{
+		{"en", "Sunday 4 August (Sun, Aug) PM"},
+		{"es", "domingo 4 agosto (dom, ago) p. m."},
+		{"es_MX", "domingo 4 agosto (dom, ago) p. m."},
+		{"fr-FR", "dimanche 4 août (dim., août) PM"},
+		{"de", "Sonntag 4 August (So., Aug.) PM"},
+	}
+	for _, tc := range testCases {
+		locale, known := lookupDateLocale(tc.locale)
+		if !known {
+			t.Errorf("Expected locale %q to be supported", tc.locale)
+		}
+		if got := formatJavaDate(moment, "EEEE d MMMM (EEE, MMM) a", locale); got != tc.expected {
+			t.Errorf("locale %s: got %q, expected %q", tc.locale, got, tc.expected)
+		}
+	}
+
+	if _, known := lookupDateLocale("xx"); known {
+		t.Error("Expected unknown locale to fall back")
+	}
+}
+
+func TestFormatLocalizedLayout(t *testing.T) {
+	moment := time.Date(2024, 3, 5, 9, 15, 0, 0, time.UTC) // Tuesday
+	fr, _ := lookupDateLocale("fr")
+
+	if got := formatLocalizedLayout(moment, "Monday 2 January 2006", fr); got != "mardi 5 mars 2024" {
+		t.Errorf("Unexpected localized layout: %q", got)
+	}
+	if got := formatLocalizedLayout(moment, "Mon, Jan 2 3:04 PM", nil); got != "Tue, Mar 5 9:15 AM" {
+		t.Errorf("Expected English layout, got %q", got)
+	}
+}
+
+func TestConvertJavaToGoTimeFormat(t *testing.T) {
+	g := New(false)
+	testCases := []struct {
+		java     string
+		expected string
+	}{
+		{"yyyy-MM-dd HH:mm:ss", "2006-01-02 15:04:05"},
+		{"EEEE, MMMM d, yyyy", "Monday, January 2, 2006"},
+		{"h:mm a", "3:04 PM"},
+		{"'Today is' EEE", "Today is Mon"},
+		{"dd/MM/yy XXX", "02/01/06 Z07:00"},
+	}
+	for _, tc := range testCases {
+		if got := g.convertJavaToGoTimeFormat(tc.java); got != tc.expected {
+			t.Errorf("convertJavaToGoTimeFormat(%q) = %q, expected %q", tc.java, got, tc.expected)
+		}
+	}
+}
+
+func TestDateTagTimezoneAndLocale(t *testing.T) {
+	clock := NewFixedClock(time.Date(2024, 8, 4, 23, 30, 0, 0, time.UTC)) // Sunday
+	g := New(false, WithClock(clock))
+	if err := g.LoadAIMLFromString(dateFormattingTestAIML); err != nil {
+		t.Fatalf("LoadAIMLFromString failed: %v", err)
+	}
+	session := g.CreateSession("dates")
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"jdate", "Sunday, 4 August 2024"},
+		{"jtime", "23:30 UTC"},
+		{"tokyo", "08:30"},
+		{"spanish", "domingo 4 de agosto"},
+		{"german", "Sonntag, 04. August 2024"},
+		{"thinkdate", "2024-08-04"},
+	}
+	for _, tc := range testCases {
+		if got := askN(t, g, session, tc.input, 1)[0]; got != tc.expected {
+			t.Errorf("%s: got %q, expected %q", tc.input, got, tc.expected)
+		}
+	}
+
+	// Session predicates provide the defaults; attributes still win
+	session.Variables["timezone"] = "America/New_York"
+	session.Variables["locale"] = "fr"
+	if got := askN(t, g, session, "jtime", 1)[0]; got != "19:30 EDT" {
+		t.Errorf("Expected session timezone, got %q", got)
+	}
+	if got := askN(t, g, session, "jdate", 1)[0]; got != "dimanche, 4 août 2024" {
+		t.Errorf("Expected session locale and timezone, got %q", got)
+	}
+	if got := askN(t, g, session, "tokyo", 1)[0]; got != "08:30" {
+		t.Errorf("Expected timezone attribute to override the session, got %q", got)
+	}
+
+	// Invalid timezones fall back to the clock's zone
+	session.Variables["timezone"] = "Mars/Olympus_Mons"
+	if got := askN(t, g, session, "jtime", 1)[0]; got != "23:30 UTC" {
+		t.Errorf("Expected fallback to clock zone, got %q", got)
+	}
+}
+
+func TestLegacyDateTagsUseSession(t *testing.T) {
+	g := New(false, WithClock(NewFixedClock(time.Date(2024, 8, 4, 23, 30, 0, 0, time.UTC))))
+	session := g.CreateSession("legacy")
+	session.Variables["timezone"] = "Europe/Berlin"
+	session.Variables["locale"] = "de"
+
+	result := g.processDateTimeTagsWithSession(`<date jformat="EEEE d. MMMM"/> <time format="24"/> <date format="%B"/>`, session)
+	if result != "Montag 5. August 01:30 August" {
+		t.Errorf("Unexpected legacy date/time result: %q", result)
+	}
+
+	result = g.processDateTimeTags(`<date timezone="Asia/Tokyo" locale="es" jformat="EEEE"/>`)
+	if result != "lunes" {
+		t.Errorf("Expected attributes without a session, got %q", result)
+	}
+}
@@ -0,0 +1,428 @@
+package golem
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Entity is a span of input an entity recognizer found, with its normalized value: lowercase
+// e-mail addresses, URLs with a scheme, phone numbers and numbers as digits, ISO 8601 dates, and
+// money as "amount CODE"
+type Entity struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	Value string `json:"value"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// entityRecognizer finds one entity type in raw text. group selects the capture group holding the
+// entity (0 for the whole match) and normalize returns its value, or false to reject the span.
+type entityRecognizer struct {
+	name      string
+	regex     *regexp.Regexp
+	group     int
+	normalize func(text string, now time.Time) (string, bool)
+}
+
+// entityKindsCache is the set of entity types the patterns of one knowledge base use
+type entityKindsCache struct {
+	kb         *AIMLKnowledgeBase
+	categories int
+	sets       int
+	kinds      map[string]bool
+}
+
+const entityMonths = `january|february|march|april|may|june|july|august|september|october|november|december|jan|feb|mar|apr|jun|jul|aug|sept|sep|oct|nov|dec`
+
+var (
+	entityDateISO       = regexp.MustCompile(`^(\d{4})-(\d{1,2})-(\d{1,2})$`)
+	entityDateNumeric   = regexp.MustCompile(`^(\d{1,2})([/.])(\d{1,2})[/.](\d{4}|\d{2})$`)
+	entityDateMonthDay  = regexp.MustCompile(`(?i)^(` + entityMonths + `)\.?\s+(\d{1,2})(?:st|nd|rd|th)?(?:,?\s+(\d{4}))?$`)
+	entityDateDayMonth  = regexp.MustCompile(`(?i)^(\d{1,2})(?:st|nd|rd|th)?\s+(?:of\s+)?(` + entityMonths + `)\.?(?:,?\s+(\d{4}))?$`)
+	entityMoneySymbol   = regexp.MustCompile(`^([$€£¥])\s?([\d,]+(?:\.\d+)?)$`)
+	entityMoneyCurrency = regexp.MustCompile(`(?i)^([\d,]+(?:\.\d+)?)\s?([a-z]+)$`)
+	entityPlaceholders  = regexp.MustCompile(`(?i)GOLEMENTITY([A-Z]+)([0-9]+)`)
+	entitySetPattern    = regexp.MustCompile(`(?i)<set>\s*([a-z]+)\s*</set>`)
+)
+
+// entityCurrencies maps currency symbols and words to ISO 4217 codes
+var entityCurrencies = map[string]string{
+	"$": "USD", "€": "EUR", "£": "GBP", "¥": "JPY",
+	"usd": "USD", "eur": "EUR", "gbp": "GBP", "jpy": "JPY",
+	"dollar": "USD", "dollars": "USD", "euro": "EUR", "euros": "EUR", "pound": "GBP", "pounds": "GBP", "yen": "JPY",
+}
+
+// entityRecognizers are tried in order; a span one recognizer claims is not offered to later ones,
+// so "2024-03-15" is a date rather than a phone number and "$5" is money rather than a number
+var entityRecognizers = []entityRecognizer{
+	{
+		name:  "EMAIL",
+		regex: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`),
+		normalize: func(text string, now time.Time) (string, bool) {
+			return strings.ToLower(text), true
+		},
+	},
+	{
+		name:  "URL",
+		regex: regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"']*[^\s<>"'.,;:!?)]`),
+		normalize: func(text string, now time.Time) (string, bool) {
+			if strings.HasPrefix(strings.ToLower(text), "www.") {
+				return "https://" + text, true
+			}
+			return text, true
+		},
+	},
+	{
+		name: "DATE",
+		regex: regexp.MustCompile(`(?i)\b(?:\d{4}-\d{1,2}-\d{1,2}|\d{1,2}[/.]\d{1,2}[/.](?:\d{4}|\d{2})|(?:` + entityMonths +
+			`)\.?\s+\d{1,2}(?:st|nd|rd|th)?(?:,?\s+\d{4})?|\d{1,2}(?:st|nd|rd|th)?\s+(?:of\s+)?(?:` + entityMonths + `)\.?(?:,?\s+\d{4})?)\b`),
+		normalize: normalizeEntityDate,
+	},
+	{
+		name:      "MONEY",
+		regex:     regexp.MustCompile(`(?i)(?:[$€£¥]\s?\d[\d,]*(?:\.\d+)?|\b\d[\d,]*(?:\.\d+)?\s?(?:usd|eur|gbp|jpy|dollars?|euros?|pounds?|yen)\b)`),
+		normalize: normalizeEntityMoney,
+	},
+	{
+		name:  "PHONE",
+		regex: regexp.MustCompile(`(?:^|[^\w+])((?:\+\d{1,3}[\s.-]?)?(?:\(\d{1,4}\)[\s.-]?)?\d{2,4}(?:[\s.-]\d{2,4}){1,4}|\+\d{7,15})\b`),
+		group: 1,
+		normalize: func(text string, now time.Time) (string, bool) {
+			digits := strings.Map(func(r rune) rune {
+				if r >= '0' && r <= '9' {
+					return r
+				}
+				return -1
+			}, text)
+			if len(digits) < 7 || len(digits) > 15 {
+				return "", false
+			}
+			if strings.HasPrefix(text, "+") {
+				return "+" + digits, true
+			}
+			return digits, true
+		},
+	},
+	{
+		name:  "NUMBER",
+		regex: regexp.MustCompile(`(?:^|[^\w.,+-])([-+]?(?:\d{1,3}(?:,\d{3})+|\d+)(?:\.\d+)?)\b`),
+		group: 1,
+		normalize: func(text string, now time.Time) (string, bool) {
+			return strings.TrimPrefix(strings.ReplaceAll(text, ",", ""), "+"), true
+		},
+	},
+}
+
+// entityTypes are the names of the built-in entity sets
+var entityTypes = func() map[string]bool {
+	types := make(map[string]bool)
+	for _, recognizer := range entityRecognizers {
+		types[recognizer.name] = true
+	}
+	return types
+}()
+
+// entitySetRegex returns the pattern regex of an entity set: the placeholder words that stand in
+// for recognized spans, and for NUMBER also plain digits, which normalization leaves intact
+func entitySetRegex(name string) string {
+	placeholder := "GOLEMENTITY" + name + "[0-9]+"
+	if name == "NUMBER" {
+		return "([0-9]+|" + placeholder + ")"
+	}
+	return "(" + placeholder + ")"
+}
+
+// isEntitySetMember reports whether word is a placeholder for, or all of, an entity of type name.
+// Dates without a year are read in the year of now.
+func isEntitySetMember(name, word string, now time.Time) bool {
+	if match := entityPlaceholders.FindStringSubmatch(word); match != nil && match[0] == word {
+		return strings.EqualFold(match[1], name)
+	}
+	_, ok := RecognizeEntity(name, word, now)
+	return ok
+}
+
+// entityPlaceholder is the word that stands in for entity i while the input is normalized
+func entityPlaceholder(entity Entity, i int) string {
+	return "GOLEMENTITY" + entity.Type + strconv.Itoa(i)
+}
+
+// normalizeEntityDate returns the ISO 8601 form of a date. Slashed dates are read month first
+// unless the first number can't be a month; dotted dates are read day first. Dates without a year
+// fall in the current year.
+func normalizeEntityDate(text string, now time.Time) (string, bool) {
+	text = strings.TrimSpace(text)
+	var year, month, day int
+	if m := entityDateISO.FindStringSubmatch(text); m != nil {
+		year, month, day = entityInt(m[1]), entityInt(m[2]), entityInt(m[3])
+	} else if m := entityDateNumeric.FindStringSubmatch(text); m != nil {
+		first, second := entityInt(m[1]), entityInt(m[3])
+		month, day = first, second
+		if m[2] == "." || first > 12 {
+			day, month = first, second
+		}
+		year = entityInt(m[4])
+		if len(m[4]) == 2 {
+			year += 2000
+		}
+	} else if m := entityDateMonthDay.FindStringSubmatch(text); m != nil {
+		month, day, year = entityMonth(m[1]), entityInt(m[2]), entityInt(m[3])
+	} else if m := entityDateDayMonth.FindStringSubmatch(text); m != nil {
+		day, month, year = entityInt(m[1]), entityMonth(m[2]), entityInt(m[3])
+	} else {
+		return "", false
+	}
+	if year == 0 {
+		year = now.Year()
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if month < 1 || month > 12 || date.Day() != day {
+		return "", false
+	}
+	return date.Format("2006-01-02"), true
+}
+
+// entityMonth returns the number of a month name or abbreviation
+func entityMonth(name string) int {
+	name = strings.ToLower(name)
+	for month := time.January; month <= time.December; month++ {
+		if strings.HasPrefix(strings.ToLower(month.String()), name[:3]) {
+			return int(month)
+		}
+	}
+	return 0
+}
+
+// isDigits reports whether text is a non-empty run of ASCII digits
+func isDigits(text string) bool {
+	if text == "" {
+		return false
+	}
+	for _, r := range text {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// entityInt parses digits, returning 0 for empty or invalid text
+func entityInt(text string) int {
+	n, _ := strconv.Atoi(text)
+	return n
+}
+
+// normalizeEntityMoney returns "amount CODE", such as "1200.50 USD"
+func normalizeEntityMoney(text string, now time.Time) (string, bool) {
+	text = strings.TrimSpace(text)
+	var amount, currency string
+	if m := entityMoneySymbol.FindStringSubmatch(text); m != nil {
+		amount, currency = m[2], entityCurrencies[m[1]]
+	} else if m := entityMoneyCurrency.FindStringSubmatch(text); m != nil {
+		amount, currency = m[1], entityCurrencies[strings.ToLower(m[2])]
+	}
+	if amount == "" || currency == "" {
+		return "", false
+	}
+	return strings.ReplaceAll(amount, ",", "") + " " + currency, true
+}
+
+// recognizeEntities finds the entities of the given types in text (all types when types is nil),
+// in order of appearance. now fills in the year of dates that don't name one.
+func recognizeEntities(text string, types map[string]bool, now time.Time) []Entity {
+	var entities []Entity
+	claimed := func(start, end int) bool {
+		for _, entity := range entities {
+			if start < entity.End && entity.Start < end {
+				return true
+			}
+		}
+		return false
+	}
+	for _, recognizer := range entityRecognizers {
+		if types != nil && !types[recognizer.name] {
+			continue
+		}
+		for _, match := range recognizer.regex.FindAllStringSubmatchIndex(text, -1) {
+			start, end := match[2*recognizer.group], match[2*recognizer.group+1]
+			if start < 0 || claimed(start, end) {
+				continue
+			}
+			// A span must not run into a word, as in "v1.2" or "abc@example.comx"
+			if next := []rune(text[end:]); len(next) > 0 && (unicode.IsLetter(next[0]) || unicode.IsDigit(next[0])) {
+				continue
+			}
+			value, ok := recognizer.normalize(text[start:end], now)
+			if !ok {
+				continue
+			}
+			entities = append(entities, Entity{Type: recognizer.name, Text: text[start:end], Value: value, Start: start, End: end})
+		}
+	}
+	sort.Slice(entities, func(i, j int) bool { return entities[i].Start < entities[j].Start })
+	return entities
+}
+
+// RecognizeEntities finds the e-mail addresses, URLs, dates, money amounts, phone numbers and
+// numbers in text, in order of appearance
+func (g *Golem) RecognizeEntities(text string) []Entity {
+	return recognizeEntities(text, nil, g.GetClock().Now())
+}
+
+// RecognizeEntity reports whether all of text (ignoring surrounding space) is an entity of the
+// given type, such as "date" or "email"
+func RecognizeEntity(entityType, text string, now time.Time) (Entity, bool) {
+	name := strings.ToUpper(strings.TrimSpace(entityType))
+	if !entityTypes[name] {
+		return Entity{}, false
+	}
+	trimmed := strings.TrimSpace(text)
+	for _, entity := range recognizeEntities(trimmed, map[string]bool{name: true}, now) {
+		if entity.Start == 0 && entity.End == len(trimmed) {
+			return entity, true
+		}
+	}
+	return Entity{}, false
+}
+
+// patternEntityTypes returns the entity sets the loaded patterns use; a loaded set or a provider
+// with the same name replaces the recognizer
+func (g *Golem) patternEntityTypes() map[string]bool {
+	kb := g.aimlKB
+	if kb == nil {
+		return nil
+	}
+	if c := g.entityKinds; c != nil && c.kb == kb && c.categories == len(kb.Categories) && c.sets == len(kb.Sets) {
+		return c.kinds
+	}
+	kinds := make(map[string]bool)
+	for _, category := range kb.Categories {
+		for _, match := range entitySetPattern.FindAllStringSubmatch(category.Pattern, -1) {
+			name := strings.ToUpper(match[1])
+			if entityTypes[name] && len(kb.Sets[name]) == 0 && g.setProvider(name) == nil {
+				kinds[name] = true
+			}
+		}
+	}
+	g.entityKinds = &entityKindsCache{kb: kb, categories: len(kb.Categories), sets: len(kb.Sets), kinds: kinds}
+	return kinds
+}
+
+// protectEntities replaces the entities the patterns ask for with placeholder words, so
+// normalization can't break up "john.doe@example.com" or "$12.50". Plain whole numbers are left
+// alone since normalization keeps them.
+func (g *Golem) protectEntities(input string) (string, []Entity) {
+	kinds := g.patternEntityTypes()
+	if len(kinds) == 0 {
+		return input, nil
+	}
+	entities := recognizeEntities(input, kinds, g.GetClock().Now())
+	if len(entities) == 0 {
+		return input, nil
+	}
+
+	var sb strings.Builder
+	last := 0
+	for i, entity := range entities {
+		if entity.Type == "NUMBER" && isDigits(entity.Text) {
+			continue
+		}
+		// The placeholder is kept apart from neighboring punctuation, which normalization may drop
+		before, after := input[last:entity.Start], input[entity.End:]
+		sb.WriteString(before)
+		if before != "" && !strings.HasSuffix(before, " ") {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(entityPlaceholder(entity, i))
+		if after != "" && !strings.HasPrefix(after, " ") {
+			sb.WriteString(" ")
+		}
+		last = entity.End
+	}
+	sb.WriteString(input[last:])
+	g.LogInfo("Recognized entities: %v", entities)
+	return sb.String(), entities
+}
+
+// matchWithEntities matches input with the entities the patterns ask for held in placeholder words,
+// keeping that match only when its pattern uses an entity set. Otherwise the input is matched as
+// typed, so a literal pattern such as MY BIRTHDAY IS MARCH * still sees "march 15". The returned
+// wildcards hold the recognized text, and the entities are nil when the input was matched as typed.
+func (g *Golem) matchWithEntities(input string, match func(input string) (*Category, map[string]string, error)) (*Category, map[string]string, []Entity, error) {
+	protected, entities := g.protectEntities(input)
+	category, wildcards, err := match(protected)
+	if protected == input {
+		return category, wildcards, entities, err
+	}
+	if err == nil && category != nil && g.usesEntitySet(category.Pattern) {
+		restoreEntities(wildcards, entities)
+		return category, wildcards, entities, nil
+	}
+	rawCategory, rawWildcards, rawErr := match(input)
+	if rawErr != nil && err == nil {
+		// Only the protected input matched
+		restoreEntities(wildcards, entities)
+		return category, wildcards, entities, nil
+	}
+	return rawCategory, rawWildcards, nil, rawErr
+}
+
+// usesEntitySet reports whether a pattern asks for one of the entity sets the recognizers fill
+func (g *Golem) usesEntitySet(pattern string) bool {
+	kinds := g.patternEntityTypes()
+	for _, match := range entitySetPattern.FindAllStringSubmatch(pattern, -1) {
+		if kinds[strings.ToUpper(match[1])] {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreEntities puts the recognized text back in place of placeholder words in wildcards. A
+// wildcard holding exactly one entity also gets its normalized value as starN_normalized, read by
+// <star normalized="true"/>.
+func restoreEntities(wildcards map[string]string, entities []Entity) {
+	if len(entities) == 0 {
+		return
+	}
+	lookup := func(match []string) (Entity, bool) {
+		i, err := strconv.Atoi(match[2])
+		if err != nil || i >= len(entities) || !strings.EqualFold(entities[i].Type, match[1]) {
+			return Entity{}, false
+		}
+		return entities[i], true
+	}
+
+	keys := make([]string, 0, len(wildcards))
+	for key := range wildcards {
+		keys = append(keys, key)
+	}
+	for _, key := range keys {
+		value := strings.TrimSpace(wildcards[key])
+		if match := entityPlaceholders.FindStringSubmatch(value); match != nil && match[0] == value {
+			if entity, ok := lookup(match); ok {
+				wildcards[key] = entity.Text
+				wildcards[key+"_normalized"] = entity.Value
+				continue
+			}
+		}
+		wildcards[key] = entityPlaceholders.ReplaceAllStringFunc(wildcards[key], func(placeholder string) string {
+			if entity, ok := lookup(entityPlaceholders.FindStringSubmatch(placeholder)); ok {
+				return entity.Text
+			}
+			return placeholder
+		})
+	}
+}
+
+// String describes an entity as TYPE "text" (value)
+func (e Entity) String() string {
+	return fmt.Sprintf("%s %q (%s)", e.Type, e.Text, e.Value)
+}
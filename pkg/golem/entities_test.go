@@ -0,0 +1,178 @@
+package golem
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const entitiesTestAIML = `<aiml version="2.0">
+<category><pattern>MY EMAIL IS <set>email</set></pattern><template>Saved <star/> as <star normalized="true"/>.</template></category>
+<category><pattern>VISIT <set>url</set></pattern><template>Opening <star normalized="true"/>.</template></category>
+<category><pattern>CALL ME AT <set>phone</set></pattern><template>Dialing <star normalized="true"/>.</template></category>
+<category><pattern>BOOK * ON <set>date</set></pattern><template>Booked <star/> for <star index="2" normalized="true"/>.</template></category>
+<category><pattern>IT COSTS <set>money</set></pattern><template><star/> is <star normalized="true"/>.</template></category>
+<category><pattern>ADD <set>number</set> AND <set>number</set></pattern><template><star normalized="true"/> plus <star index="2" normalized="true"/></template></category>
+<category><pattern>REMEMBER *</pattern><template>Noted: <star/></template></category>
+<category><pattern>CHECK *</pattern><template><srai>MY EMAIL IS <star/></srai></template></category>
+<category><pattern>*</pattern><template>Pardon?</template></category>
+</aiml>`
+
+func TestRecognizeEntities(t *testing.T) {
+	g := New(false, WithClock(NewFixedClock(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))))
+	text := "Mail John.Doe@Example.com or see www.example.com/help, call +1 (555) 123-4567 before March 15th " +
+		"or 2024-03-20 and pay $1,200.50 or 30 euros for 3.5 items, not 42."
+	expected := []Entity{
+		{Type: "EMAIL", Text: "John.Doe@Example.com", Value: "john.doe@example.com"},
+		{Type: "URL", Text: "www.example.com/help", Value: "https://www.example.com/help"},
+		{Type: "PHONE", Text: "+1 (555) 123-4567", Value: "+15551234567"},
+		{Type: "DATE", Text: "March 15th", Value: "2025-03-15"},
+		{Type: "DATE", Text: "2024-03-20", Value: "2024-03-20"},
+		{Type: "MONEY", Text: "$1,200.50", Value: "1200.50 USD"},
+		{Type: "MONEY", Text: "30 euros", Value: "30 EUR"},
+		{Type: "NUMBER", Text: "3.5", Value: "3.5"},
+		{Type: "NUMBER", Text: "42", Value: "42"},
+	}
+	entities := g.RecognizeEntities(text)
+	for i := range entities {
+		if text[entities[i].Start:entities[i].End] != entities[i].Text {
+			t.Errorf("Entity %v has wrong offsets", entities[i])
+		}
+		entities[i].Start, entities[i].End = 0, 0
+	}
+	if !reflect.DeepEqual(entities, expected) {
+		t.Errorf("Unexpected entities:\n got %v\nwant %v", entities, expected)
+	}
+}
+
+func TestRecognizeEntity(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		entityType string
+		text       string
+		value      string
+	}{
+		{"date", "15/03/2024", "2024-03-15"},
+		{"date", "03/15/24", "2024-03-15"},
+		{"date", "15.03.2024", "2024-03-15"},
+		{"date", "the 1st of May", ""},
+		{"date", "1st of May", "2025-05-01"},
+		{"date", "February 30, 2024", ""},
+		{"email", " someone@example.org ", "someone@example.org"},
+		{"email", "someone@example", ""},
+		{"number", "-1,000", "-1000"},
+		{"phone", "555 12", ""},
+		{"money", "12 bananas", ""},
+		{"color", "red", ""},
+	}
+	for _, tc := range testCases {
+		entity, ok := RecognizeEntity(tc.entityType, tc.text, now)
+		if ok != (tc.value != "") || entity.Value != tc.value {
+			t.Errorf("RecognizeEntity(%q, %q) = %v, %v; expected %q", tc.entityType, tc.text, entity, ok, tc.value)
+		}
+	}
+}
+
+func TestEntitySets(t *testing.T) {
+	g := New(false, WithClock(NewFixedClock(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))))
+	if err := g.LoadAIMLFromString(entitiesTestAIML); err != nil {
+		t.Fatalf("LoadAIMLFromString failed: %v", err)
+	}
+	session := g.CreateSession("entities")
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"My email is John.Doe@Example.com", "Saved John.Doe@Example.com as john.doe@example.com."},
+		{"visit www.example.com/help!", "Opening https://www.example.com/help."},
+		{"call me at (555) 123-4567", "Dialing 5551234567."},
+		{"book a table for two on March 15th", "Booked a table for two for 2025-03-15."},
+		{"it costs $1,200.50", "$1,200.50 is 1200.50 USD."},
+		{"add 3.5 and -2", "3.5 plus -2"},
+		{"add 7 and 8", "7 plus 8"},
+		// Wildcards get the recognized text back
+		{"remember the meeting on 15.03.2024", "Noted: the meeting on 15.03.2024"},
+		// Entity sets also match through srai
+		{"check jane@example.net", "Saved jane@example.net as jane@example.net."},
+		{"my email is not telling", "Pardon?"},
+	}
+	for _, tc := range testCases {
+		if got := askN(t, g, session, tc.input, 1)[0]; got != tc.expected {
+			t.Errorf("%q: got %q, expected %q", tc.input, got, tc.expected)
+		}
+	}
+
+	_, metadata, err := g.ProcessInputWithMetadata("my email is a@b.co", session)
+	if err != nil {
+		t.Fatalf("ProcessInputWithMetadata failed: %v", err)
+	}
+	if len(metadata.Entities) != 1 || metadata.Entities[0].Value != "a@b.co" {
+		t.Errorf("Expected the e-mail in metadata, got %v", metadata.Entities)
+	}
+}
+
+func TestEntitySetReplacedByLoadedSet(t *testing.T) {
+	g := New(false)
+	if err := g.LoadAIMLFromString(`<aiml version="2.0">
+<category><pattern>PAY WITH <set>money</set></pattern><template>Paying with <star/>.</template></category>
+<category><pattern>*</pattern><template>Pardon?</template></category>
+</aiml>`); err != nil {
+		t.Fatalf("LoadAIMLFromString failed: %v", err)
+	}
+	g.aimlKB.AddSetMembers("MONEY", []string{"cash", "card"})
+	session := g.CreateSession("entities-loaded")
+	if got := askN(t, g, session, "pay with card", 1)[0]; got != "Paying with card." {
+		t.Errorf("Expected the loaded set to answer, got %q", got)
+	}
+	if got := askN(t, g, session, "pay with $5", 1)[0]; got != "Pardon?" {
+		t.Errorf("Expected the loaded set to replace the recognizer, got %q", got)
+	}
+}
+
+func TestEntitySetsKeepLiteralPatterns(t *testing.T) {
+	g := newTestBot(t, `<aiml version="2.0">
+<category><pattern>REMIND ME ON <set>date</set></pattern><template>Reminder set for <star normalized="true"/>.</template></category>
+<category><pattern>MY BIRTHDAY IS MARCH *</pattern><template>Born on the <star/>th.</template></category>
+<category><pattern>I HAVE <set>number</set> CATS</pattern><template><star/> cats.</template></category>
+<category><pattern>TAKE 2.5 TABLETS</pattern><template>Dose noted.</template></category>
+<category><pattern>*</pattern><template>Pardon?</template></category>
+</aiml>`, WithClock(NewFixedClock(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))))
+	session := g.CreateSession("entities-literal")
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"remind me on March 15", "Reminder set for 2025-03-15."},
+		// A pattern spelling out part of an entity still sees the words
+		{"my birthday is march 15", "Born on the 15th."},
+		{"i have 2.5 cats", "2.5 cats."},
+		{"take 2.5 tablets", "Dose noted."},
+	}
+	for _, tc := range testCases {
+		if got := askN(t, g, session, tc.input, 1)[0]; got != tc.expected {
+			t.Errorf("%q: got %q, expected %q", tc.input, got, tc.expected)
+		}
+	}
+
+	_, metadata, err := g.ProcessInputWithMetadata("my birthday is march 15", session)
+	if err != nil {
+		t.Fatalf("ProcessInputWithMetadata failed: %v", err)
+	}
+	if len(metadata.Entities) != 0 {
+		t.Errorf("Expected no entities when a literal pattern matched, got %v", metadata.Entities)
+	}
+}
+
+func TestIsSetMemberUsesClock(t *testing.T) {
+	// February 29 only exists in leap years, and dates without a year are read in the clock's year
+	leap := New(false, WithClock(NewFixedClock(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))))
+	common := New(false, WithClock(NewFixedClock(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))))
+	if !leap.IsSetMember("date", "February 29") {
+		t.Error("Expected February 29 to be a date in 2024")
+	}
+	if common.IsSetMember("date", "February 29") {
+		t.Error("Expected February 29 not to be a date in 2025")
+	}
+}
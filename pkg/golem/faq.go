@@ -0,0 +1,382 @@
+package golem
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// FAQPassage is a piece of a Markdown FAQ article: the paragraphs under one heading, split further
+// when they run longer than maxFAQPassageWords
+type FAQPassage struct {
+	Title   string `json:"title"`
+	Section string `json:"section,omitempty"`
+	Text    string `json:"text"`
+	Source  string `json:"source"`
+}
+
+// FAQResult is a passage retrieved for a query with its BM25 score
+type FAQResult struct {
+	Title   string  `json:"title"`
+	Section string  `json:"section,omitempty"`
+	Passage string  `json:"passage"`
+	Source  string  `json:"source"`
+	Score   float64 `json:"score"`
+}
+
+// FAQFallback configures answering inputs only a catch-all pattern (*, _, ^ or #) matched with the
+// best FAQ passage
+type FAQFallback struct {
+	// MinScore is the BM25 score the best passage needs to answer (default 2)
+	MinScore float64
+}
+
+// BM25 parameters: term frequency saturation and document length normalization
+const (
+	faqBM25K1 = 1.2
+	faqBM25B  = 0.75
+	// maxFAQPassageWords is the length at which the paragraphs of a section start a new passage
+	maxFAQPassageWords = 120
+)
+
+// faqIndex is a BM25 index over FAQ passages
+type faqIndex struct {
+	passages      []FAQPassage
+	termFrequency []map[string]int
+	lengths       []int
+	averageLength float64
+	idf           map[string]float64
+}
+
+// faqIndexCache is the index built from one knowledge base's passages
+type faqIndexCache struct {
+	kb       *AIMLKnowledgeBase
+	passages int
+	index    *faqIndex
+}
+
+var (
+	markdownHeading   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	markdownListItem  = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+`)
+	markdownImageLink = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	markdownEmphasis  = regexp.MustCompile("(\\*\\*|__|\\*|`)")
+)
+
+// faqTerms returns the words of text under the normalization used for pattern matching, keeping
+// only tokens with letters or digits
+func (g *Golem) faqTerms(text string) []string {
+	normalized := g.applyLoadedSubstitutions(NormalizePattern(g.prepareForMatching(text)))
+	var terms []string
+	for _, token := range strings.Fields(normalized) {
+		token = strings.TrimFunc(token, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if token != "" {
+			terms = append(terms, token)
+		}
+	}
+	return terms
+}
+
+// newFAQIndex indexes passages by their title, section and text
+func (g *Golem) newFAQIndex(passages []FAQPassage) *faqIndex {
+	index := &faqIndex{passages: passages, idf: make(map[string]float64)}
+	frequency := make(map[string]int)
+	total := 0
+	for _, passage := range passages {
+		terms := g.faqTerms(passage.Title + " " + passage.Section + " " + passage.Text)
+		counts := make(map[string]int)
+		for _, term := range terms {
+			if counts[term] == 0 {
+				frequency[term]++
+			}
+			counts[term]++
+		}
+		index.termFrequency = append(index.termFrequency, counts)
+		index.lengths = append(index.lengths, len(terms))
+		total += len(terms)
+	}
+	if len(passages) > 0 {
+		index.averageLength = float64(total) / float64(len(passages))
+	}
+	// Probabilistic IDF kept positive, so terms in most passages still count a little
+	for term, count := range frequency {
+		index.idf[term] = math.Log(1 + (float64(len(passages))-float64(count)+0.5)/(float64(count)+0.5))
+	}
+	return index
+}
+
+// search scores every passage against the query terms, best first, dropping passages sharing no term
+func (index *faqIndex) search(terms []string, limit int) []FAQResult {
+	seen := make(map[string]bool)
+	var query []string
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			query = append(query, term)
+		}
+	}
+
+	var results []FAQResult
+	for i, passage := range index.passages {
+		score := 0.0
+		norm := faqBM25K1 * (1 - faqBM25B + faqBM25B*float64(index.lengths[i])/index.averageLength)
+		for _, term := range query {
+			if tf := float64(index.termFrequency[i][term]); tf > 0 {
+				score += index.idf[term] * tf * (faqBM25K1 + 1) / (tf + norm)
+			}
+		}
+		if score > 0 {
+			results = append(results, FAQResult{
+				Title:   passage.Title,
+				Section: passage.Section,
+				Passage: passage.Text,
+				Source:  passage.Source,
+				Score:   score,
+			})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// faqIndex returns the BM25 index of the loaded FAQ passages, rebuilt when the knowledge base changes
+func (g *Golem) faqIndex() *faqIndex {
+	kb := g.aimlKB
+	if kb == nil || len(kb.FAQ) == 0 {
+		return nil
+	}
+	if c := g.faqCache; c != nil && c.kb == kb && c.passages == len(kb.FAQ) {
+		return c.index
+	}
+	g.faqCache = &faqIndexCache{kb: kb, passages: len(kb.FAQ), index: g.newFAQIndex(kb.FAQ)}
+	g.LogInfo("Indexed %d FAQ passages", len(kb.FAQ))
+	return g.faqCache.index
+}
+
+// SearchFAQ returns up to limit FAQ passages matching query, best first; limit 0 returns all matches
+func (g *Golem) SearchFAQ(query string, limit int) []FAQResult {
+	index := g.faqIndex()
+	if index == nil {
+		return nil
+	}
+	return index.search(g.faqTerms(query), limit)
+}
+
+// cleanMarkdownLine strips list markers, quotes, links and emphasis from a line of Markdown
+func cleanMarkdownLine(line string) string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimSpace(strings.TrimLeft(line, ">"))
+	line = markdownListItem.ReplaceAllString(line, "")
+	line = markdownImageLink.ReplaceAllString(line, "$1")
+	line = markdownEmphasis.ReplaceAllString(line, "")
+	return strings.TrimSpace(line)
+}
+
+// faqTitleFromFilename turns reset-password.md into "reset password"
+func faqTitleFromFilename(filename string) string {
+	name := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	return strings.Join(strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' }), " ")
+}
+
+// LoadFAQFromFile splits a Markdown article into passages. The first top-level heading is the
+// title (the file name otherwise), other headings start sections, and the paragraphs of a section
+// form one passage until it reaches maxFAQPassageWords words.
+func (g *Golem) LoadFAQFromFile(filename string) ([]FAQPassage, error) {
+	g.LogInfo("Loading FAQ file: %s", filename)
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open FAQ file %s: %v", filename, err)
+	}
+	defer file.Close()
+
+	title := ""
+	section := ""
+	var passages []FAQPassage
+	var paragraphs []string
+	var paragraph []string
+	words := 0
+
+	endParagraph := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		text := strings.Join(paragraph, " ")
+		paragraph = nil
+		count := len(strings.Fields(text))
+		if words > 0 && words+count > maxFAQPassageWords {
+			passages = append(passages, FAQPassage{Section: section, Text: strings.Join(paragraphs, " ")})
+			paragraphs, words = nil, 0
+		}
+		paragraphs = append(paragraphs, text)
+		words += count
+	}
+	endSection := func() {
+		endParagraph()
+		if len(paragraphs) > 0 {
+			passages = append(passages, FAQPassage{Section: section, Text: strings.Join(paragraphs, " ")})
+		}
+		paragraphs, words = nil, 0
+	}
+
+	scanner := bufio.NewScanner(file)
+	inCode := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			endParagraph()
+			continue
+		}
+		if !inCode {
+			if match := markdownHeading.FindStringSubmatch(line); match != nil {
+				heading := cleanMarkdownLine(match[2])
+				if len(match[1]) == 1 && title == "" && len(passages) == 0 && len(paragraphs) == 0 && len(paragraph) == 0 {
+					title = heading
+					continue
+				}
+				endSection()
+				section = heading
+				continue
+			}
+		}
+		text := cleanMarkdownLine(line)
+		if inCode {
+			text = strings.TrimSpace(line)
+		}
+		if text == "" {
+			endParagraph()
+			continue
+		}
+		paragraph = append(paragraph, text)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read FAQ file %s: %v", filename, err)
+	}
+	endSection()
+
+	if title == "" {
+		title = faqTitleFromFilename(filename)
+	}
+	for i := range passages {
+		passages[i].Title = title
+		passages[i].Source = filename
+	}
+
+	g.LogInfo("Loaded %d FAQ passages from %s", len(passages), filename)
+	return passages, nil
+}
+
+// isFAQFile reports whether path is a Markdown file in a directory named faq
+func isFAQFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return (ext == ".md" || ext == ".markdown") && strings.EqualFold(filepath.Base(filepath.Dir(path)), "faq")
+}
+
+// LoadFAQFromDirectory loads the faq/*.md articles found under dirPath
+func (g *Golem) LoadFAQFromDirectory(dirPath string) ([]FAQPassage, error) {
+	files, err := findResourceFiles(dirPath, isFAQFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var allPassages []FAQPassage
+	for _, file := range files {
+		passages, err := g.LoadFAQFromFile(file)
+		if err != nil {
+			// Log the error but continue with other files
+			g.LogWarn("Failed to load %s: %v", file, err)
+			continue
+		}
+		allPassages = append(allPassages, passages...)
+	}
+	return allPassages, nil
+}
+
+// faqService answers <sraix service="faq">: the best passage, or with hint="title", "section" or
+// "score" that part of the result. It fails when nothing matches, so the sraix default applies.
+func (g *Golem) faqService(input string, params map[string]string) (string, error) {
+	results := g.SearchFAQ(input, 1)
+	if len(results) == 0 {
+		return "", fmt.Errorf("no FAQ passage matches '%s'", input)
+	}
+	return faqResultField(results[0], params["hint"]), nil
+}
+
+// faqResultField returns the part of a result named by field, the passage by default
+func faqResultField(result FAQResult, field string) string {
+	switch strings.ToLower(strings.TrimSpace(field)) {
+	case "title":
+		return result.Title
+	case "section":
+		return result.Section
+	case "score":
+		return strconv.FormatFloat(result.Score, 'f', 2, 64)
+	case "source":
+		return result.Source
+	}
+	return result.Passage
+}
+
+// WithFAQFallback answers inputs only a catch-all pattern matched with the best FAQ passage
+func WithFAQFallback(fallback FAQFallback) Option {
+	return func(g *Golem) {
+		g.SetFAQFallback(&fallback)
+	}
+}
+
+// SetFAQFallback enables the FAQ fallback; nil disables it
+func (g *Golem) SetFAQFallback(fallback *FAQFallback) {
+	if fallback != nil {
+		settings := *fallback
+		if settings.MinScore <= 0 {
+			settings.MinScore = 2
+		}
+		fallback = &settings
+	}
+	g.faqFallback = fallback
+}
+
+// GetFAQFallback returns the FAQ fallback settings, nil when it is disabled
+func (g *Golem) GetFAQFallback() *FAQFallback {
+	return g.faqFallback
+}
+
+// applyFAQFallback answers a catch-all match the intent and fuzzy fallbacks left alone with the best
+// FAQ passage, through a copy of the catch-all category whose template is an <faq> lookup of the
+// input. Input nothing matched arrives as a bare catch-all. The passage is recorded in metadata.
+func (g *Golem) applyFAQFallback(category *Category, wildcards map[string]string, input string, metadata *ResponseMetadata) (*Category, map[string]string) {
+	if g.faqFallback == nil || category == nil || !isCatchAllPattern(category.Pattern) ||
+		metadata.Intent != "" || metadata.FuzzyMatch != nil || len(metadata.Suggestions) > 0 || metadata.Form != "" {
+		return category, wildcards
+	}
+	terms := g.faqTerms(input)
+	index := g.faqIndex()
+	if index == nil || len(terms) == 0 {
+		return category, wildcards
+	}
+	results := index.search(terms, 1)
+	if len(results) == 0 || results[0].Score < g.faqFallback.MinScore {
+		return category, wildcards
+	}
+	g.LogInfo("FAQ fallback answered from '%s' (score %.2f)", results[0].Title, results[0].Score)
+	metadata.FAQ = &results[0]
+
+	// The query is rebuilt from letters and digits only, so the template stays well-formed
+	answer := *category
+	answer.Template = "<faq>" + strings.Join(terms, " ") + "</faq>"
+	return &answer, wildcards
+}
@@ -0,0 +1,196 @@
+package golem
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const faqTestAIML = `<aiml version="2.0">
+<category><pattern>HELLO</pattern><template>Hi there!</template></category>
+<category><pattern>ASK FAQ *</pattern><template><faq><star/></faq></template></category>
+<category><pattern>FAQ SOURCE *</pattern><template><faq hint="title"><star/></faq> (<get name="faq_score"/>)</template></category>
+<category><pattern>FAQ MISSING *</pattern><template><faq default="No article covers that."><star/></faq></template></category>
+<category><pattern>SEARCH *</pattern><template><sraix service="faq"><star/></sraix></template></category>
+<category><pattern>SEARCH TITLE *</pattern><template><sraix service="faq" hint="title"><star/></sraix></template></category>
+<category><pattern>*</pattern><template>Pardon?</template></category>
+</aiml>`
+
+const faqTestPasswordArticle = `# Account help
+
+## How do I reset my password?
+
+Go to **Settings > Account** and choose [Reset password](https://example.com/reset).
+A reset link is e-mailed to you.
+
+## How do I change my e-mail address?
+
+Open your profile and edit the e-mail field.
+`
+
+const faqTestBillingArticle = `Billing questions.
+
+## When am I charged?
+
+- Monthly plans are charged on the first day of the month.
+- Yearly plans are charged on the day you subscribe.
+
+## How do I get a refund?
+
+Refunds are issued within 14 days of a purchase. Contact support with your invoice number.
+`
+
+func writeFAQBot(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeResourceFile(t, dir, "support.aiml", faqTestAIML)
+	if err := os.Mkdir(filepath.Join(dir, "faq"), 0755); err != nil {
+		t.Fatalf("Failed to create faq directory: %v", err)
+	}
+	writeResourceFile(t, filepath.Join(dir, "faq"), "account.md", faqTestPasswordArticle)
+	writeResourceFile(t, filepath.Join(dir, "faq"), "billing-and-refunds.md", faqTestBillingArticle)
+	// Markdown outside faq/ is not indexed
+	writeResourceFile(t, dir, "README.md", "# Readme\n\nHow to reset the bot password.\n")
+	return dir
+}
+
+func TestLoadFAQFromFile(t *testing.T) {
+	g := New(false)
+	dir := writeFAQBot(t)
+	passages, err := g.LoadFAQFromFile(filepath.Join(dir, "faq", "account.md"))
+	if err != nil {
+		t.Fatalf("LoadFAQFromFile failed: %v", err)
+	}
+	if len(passages) != 2 {
+		t.Fatalf("Expected a passage per section, got %+v", passages)
+	}
+	expected := FAQPassage{
+		Title:   "Account help",
+		Section: "How do I reset my password?",
+		Text:    "Go to Settings > Account and choose Reset password. A reset link is e-mailed to you.",
+		Source:  filepath.Join(dir, "faq", "account.md"),
+	}
+	if passages[0] != expected {
+		t.Errorf("Expected %+v, got %+v", expected, passages[0])
+	}
+
+	// Without a top-level heading the file name is the title, and text before the first heading is kept
+	passages, err = g.LoadFAQFromFile(filepath.Join(dir, "faq", "billing-and-refunds.md"))
+	if err != nil {
+		t.Fatalf("LoadFAQFromFile failed: %v", err)
+	}
+	if len(passages) != 3 || passages[0].Title != "billing and refunds" || passages[0].Text != "Billing questions." {
+		t.Fatalf("Unexpected passages %+v", passages)
+	}
+	if passages[1].Text != "Monthly plans are charged on the first day of the month. Yearly plans are charged on the day you subscribe." {
+		t.Errorf("Expected list items joined into one passage, got %q", passages[1].Text)
+	}
+}
+
+func TestSearchFAQ(t *testing.T) {
+	g := newTestBotFromDirectory(t, writeFAQBot(t))
+	if len(g.aimlKB.FAQ) != 5 {
+		t.Fatalf("Expected 5 passages from faq/*.md, got %d", len(g.aimlKB.FAQ))
+	}
+
+	testCases := []struct {
+		query   string
+		section string
+	}{
+		{"I forgot my password", "How do I reset my password?"},
+		{"can I get my money back? refund", "How do I get a refund?"},
+		{"when do yearly plans get charged", "When am I charged?"},
+		{"change e-mail", "How do I change my e-mail address?"},
+	}
+	for _, tc := range testCases {
+		results := g.SearchFAQ(tc.query, 2)
+		if len(results) == 0 || results[0].Section != tc.section {
+			t.Errorf("%q: expected %q first, got %+v", tc.query, tc.section, results)
+			continue
+		}
+		if len(results) > 1 && results[1].Score > results[0].Score {
+			t.Errorf("%q: expected results best first, got %+v", tc.query, results)
+		}
+	}
+	if results := g.SearchFAQ("zebras", 0); len(results) != 0 {
+		t.Errorf("Expected no results for unrelated words, got %+v", results)
+	}
+}
+
+func TestFAQTemplates(t *testing.T) {
+	g := newTestBotFromDirectory(t, writeFAQBot(t))
+	session := g.CreateSession("faq")
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"ask faq how do I get a refund", "Refunds are issued within 14 days of a purchase. Contact support with your invoice number."},
+		{"search title reset my password", "Account help"},
+		{"faq missing zebras", "No article covers that."},
+		// An unmatched sraix request falls back to its content
+		{"search zebras", "zebras"},
+	}
+	for _, tc := range testCases {
+		if got := askN(t, g, session, tc.input, 1)[0]; got != tc.expected {
+			t.Errorf("%q: got %q, expected %q", tc.input, got, tc.expected)
+		}
+	}
+
+	response := askN(t, g, session, "faq source refund", 1)[0]
+	if !strings.HasPrefix(response, "billing and refunds (") || response == "billing and refunds ()" {
+		t.Errorf("Expected the title and score, got %q", response)
+	}
+	if session.Variables["faq_section"] != "How do I get a refund?" {
+		t.Errorf("Expected the section predicate, got %q", session.Variables["faq_section"])
+	}
+}
+
+func TestFAQFallback(t *testing.T) {
+	g := newTestBotFromDirectory(t, writeFAQBot(t), WithFAQFallback(FAQFallback{}))
+	session := g.CreateSession("faq-fallback")
+
+	response, metadata, err := g.ProcessInputWithMetadata("How can I reset my password?", session)
+	if err != nil {
+		t.Fatalf("ProcessInputWithMetadata failed: %v", err)
+	}
+	if !strings.HasPrefix(response, "Go to Settings > Account") {
+		t.Errorf("Expected the password passage, got %q", response)
+	}
+	if metadata.FAQ == nil || metadata.FAQ.Title != "Account help" || metadata.FAQ.Score < 2 {
+		t.Errorf("Expected the passage in metadata, got %+v", metadata.FAQ)
+	}
+
+	// Patterns still win, and weak matches reach the catch-all category
+	if got := askN(t, g, session, "hello", 1)[0]; got != "Hi there!" {
+		t.Errorf("Expected the pattern to answer, got %q", got)
+	}
+	if got := askN(t, g, session, "zebras are striped", 1)[0]; got != "Pardon?" {
+		t.Errorf("Expected the catch-all response, got %q", got)
+	}
+
+	g.SetFAQFallback(&FAQFallback{MinScore: 100})
+	if got := askN(t, g, session, "How can I reset my password?", 1)[0]; got != "Pardon?" {
+		t.Errorf("Expected no FAQ answer below the minimum score, got %q", got)
+	}
+	g.SetFAQFallback(nil)
+	if g.GetFAQFallback() != nil {
+		t.Error("Expected the fallback to be disabled")
+	}
+}
+
+func TestFAQFallbackWithoutCatchAll(t *testing.T) {
+	dir := writeFAQBot(t)
+	writeResourceFile(t, dir, "support.aiml", `<aiml version="2.0"><category><pattern>HELLO</pattern><template>Hi there!</template></category></aiml>`)
+	g := newTestBotFromDirectory(t, dir, WithFAQFallback(FAQFallback{}))
+	session := g.CreateSession("faq-unmatched")
+
+	// Input no pattern matches still gets the passage
+	if got := askN(t, g, session, "How can I reset my password?", 1)[0]; !strings.HasPrefix(got, "Go to Settings > Account") {
+		t.Errorf("Expected the password passage, got %q", got)
+	}
+	if _, err := g.ProcessInput("zebras are striped", session); err == nil {
+		t.Error("Expected no match when no passage scores high enough")
+	}
+}
@@ -0,0 +1,463 @@
+package golem
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Form collects several answers over consecutive turns. Each slot is asked for in order until
+// it has a valid answer; then the srai input is answered and the OOB message is sent.
+type Form struct {
+	Name  string     `json:"name"`
+	Slots []FormSlot `json:"slots"`
+	// Srai is the input answered when every slot is filled
+	Srai string `json:"srai,omitempty"`
+	// OOB is a message (a template) passed to the OOB handlers when every slot is filled
+	OOB string `json:"oob,omitempty"`
+	// Cancel is the response (a template) when the user abandons the form
+	Cancel string `json:"cancel,omitempty"`
+}
+
+// FormSlot is one answer a form asks for. At most one of Set, Regex and Entity validates it;
+// without one any answer is accepted. The answer is stored in the predicate named after the slot,
+// as the normalized value for entities and the first capture group (or whole match) for regexes.
+type FormSlot struct {
+	Name string `json:"name"`
+	// Prompt asks for the slot (a template)
+	Prompt string `json:"prompt"`
+	// Reprompt asks again after an invalid answer (a template, the prompt by default)
+	Reprompt string `json:"reprompt,omitempty"`
+	Set      string `json:"set,omitempty"`
+	Regex    string `json:"regex,omitempty"`
+	Entity   string `json:"entity,omitempty"`
+}
+
+// FormState is the progress of a form in a session
+type FormState struct {
+	Name   string            `json:"name"`
+	Values map[string]string `json:"values"`
+}
+
+// formCancelWords abandon the active form when they are the whole input
+var formCancelWords = map[string]bool{
+	"CANCEL": true, "STOP": true, "QUIT": true, "NEVER MIND": true, "NEVERMIND": true, "FORGET IT": true,
+}
+
+// defaultFormCancel is the response when a form without a Cancel template is abandoned
+const defaultFormCancel = "OK, I have cancelled that."
+
+// formDeclarationRegex finds <form> elements; only those with <slot> children are declarations,
+// the others are template tags
+var formDeclarationRegex = regexp.MustCompile(`(?s)<form\b[^>]*[^/]>.*?</form>`)
+
+// validate checks that the form has slots with names and prompts and valid regexes, and something
+// to do when it completes
+func (f *Form) validate() []string {
+	var problems []string
+	if len(f.Slots) == 0 {
+		problems = append(problems, fmt.Sprintf("form %s has no slots", f.Name))
+	}
+	if strings.TrimSpace(f.Srai) == "" && strings.TrimSpace(f.OOB) == "" {
+		problems = append(problems, fmt.Sprintf("form %s has no srai or oob to complete with", f.Name))
+	}
+	seen := make(map[string]bool)
+	for i, slot := range f.Slots {
+		switch {
+		case slot.Name == "":
+			problems = append(problems, fmt.Sprintf("form %s slot %d has no name", f.Name, i+1))
+		case seen[slot.Name]:
+			problems = append(problems, fmt.Sprintf("form %s has two slots named %s", f.Name, slot.Name))
+		case strings.TrimSpace(slot.Prompt) == "":
+			problems = append(problems, fmt.Sprintf("form %s slot %s has no prompt", f.Name, slot.Name))
+		}
+		seen[slot.Name] = true
+
+		validators := 0
+		for _, validator := range []string{slot.Set, slot.Regex, slot.Entity} {
+			if validator != "" {
+				validators++
+			}
+		}
+		if validators > 1 {
+			problems = append(problems, fmt.Sprintf("form %s slot %s has more than one of set, regex and entity", f.Name, slot.Name))
+		}
+		if slot.Regex != "" {
+			if _, err := regexp.Compile(slot.Regex); err != nil {
+				problems = append(problems, fmt.Sprintf("form %s slot %s has an invalid regex: %v", f.Name, slot.Name, err))
+			}
+		}
+		if slot.Entity != "" && !entityTypes[strings.ToUpper(slot.Entity)] {
+			problems = append(problems, fmt.Sprintf("form %s slot %s has unknown entity %s", f.Name, slot.Name, slot.Entity))
+		}
+	}
+	return problems
+}
+
+// LoadFormsFromFile loads a forms file: a JSON object mapping each form name to its slots and
+// completion, {"book": {"slots": [{"name": "date", "prompt": "Which day?", "entity": "date"}], "srai": "BOOKED"}}
+func (g *Golem) LoadFormsFromFile(filename string) ([]Form, error) {
+	g.LogInfo("Loading forms file: %s", filename)
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read forms file %s: %v", filename, err)
+	}
+	var entries map[string]Form
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON in forms file %s: %v", filename, err)
+	}
+
+	var forms []Form
+	var problems []string
+	for name, form := range entries {
+		form.Name = name
+		problems = append(problems, form.validate()...)
+		forms = append(forms, form)
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("invalid forms in %s:\n  %s", filename, strings.Join(problems, "\n  "))
+	}
+	sort.Slice(forms, func(i, j int) bool { return forms[i].Name < forms[j].Name })
+
+	g.LogInfo("Loaded %d forms from %s", len(forms), filename)
+	return forms, nil
+}
+
+// isFormsFile reports whether path is a .json file in a directory named forms
+func isFormsFile(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".json") && strings.EqualFold(filepath.Base(filepath.Dir(path)), "forms")
+}
+
+// LoadFormsFromDirectory loads the forms/*.json files found under dirPath
+func (g *Golem) LoadFormsFromDirectory(dirPath string) ([]Form, error) {
+	files, err := findResourceFiles(dirPath, isFormsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var allForms []Form
+	for _, file := range files {
+		forms, err := g.LoadFormsFromFile(file)
+		if err != nil {
+			// Log the error but continue with other files
+			g.LogWarn("Failed to load %s: %v", file, err)
+			continue
+		}
+		allForms = mergeForms(allForms, forms)
+	}
+	return allForms, nil
+}
+
+// mergeForms adds forms to target; a form defined again replaces the earlier definition
+func mergeForms(target, forms []Form) []Form {
+	for _, form := range forms {
+		replaced := false
+		for i := range target {
+			if strings.EqualFold(target[i].Name, form.Name) {
+				target[i] = form
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			target = append(target, form)
+		}
+	}
+	return target
+}
+
+// parseForms reads the form declarations of an AIML document, the <form> elements with slots:
+//
+//	<form name="book" srai="BOOKING COMPLETE">
+//	  <slot name="date" entity="date"><prompt>Which day?</prompt><reprompt>Which day, for example May 3rd?</reprompt></slot>
+//	  <oob>BOOK <get name="date"/></oob>
+//	  <cancel>Maybe another time.</cancel>
+//	</form>
+func (g *Golem) parseForms(content string) ([]Form, error) {
+	var forms []Form
+	var problems []string
+	for _, declaration := range formDeclarationRegex.FindAllString(content, -1) {
+		if !strings.Contains(declaration, "<slot") {
+			continue
+		}
+		root, err := NewASTParser(declaration).Parse()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse form: %v", err)
+		}
+		node := root.FindFirstTagByName("form")
+		if node == nil {
+			continue
+		}
+		form := Form{
+			Name:   strings.TrimSpace(node.Attributes["name"]),
+			Srai:   strings.TrimSpace(node.Attributes["srai"]),
+			OOB:    strings.TrimSpace(node.Attributes["oob"]),
+			Cancel: formChildContent(node, "cancel"),
+		}
+		if form.OOB == "" {
+			form.OOB = formChildContent(node, "oob")
+		}
+		if form.Name == "" {
+			problems = append(problems, "form without a name")
+			continue
+		}
+		for _, child := range node.Children {
+			if (child.Type != NodeTypeTag && child.Type != NodeTypeSelfClosingTag) || child.TagName != "slot" {
+				continue
+			}
+			form.Slots = append(form.Slots, FormSlot{
+				Name:     strings.TrimSpace(child.Attributes["name"]),
+				Prompt:   formChildContent(child, "prompt"),
+				Reprompt: formChildContent(child, "reprompt"),
+				Set:      strings.TrimSpace(child.Attributes["set"]),
+				Regex:    child.Attributes["regex"],
+				Entity:   strings.TrimSpace(child.Attributes["entity"]),
+			})
+		}
+		problems = append(problems, form.validate()...)
+		forms = append(forms, form)
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid forms: %s", strings.Join(problems, "; "))
+	}
+	return forms, nil
+}
+
+// formChildContent returns the markup inside the first child tag of node with the given name
+func formChildContent(node *ASTNode, tagName string) string {
+	for _, child := range node.Children {
+		if child.Type == NodeTypeTag && child.TagName == tagName {
+			var content strings.Builder
+			for _, grandchild := range child.Children {
+				content.WriteString(grandchild.String())
+			}
+			return strings.TrimSpace(content.String())
+		}
+	}
+	return ""
+}
+
+// Form returns the form with the given name
+func (g *Golem) Form(name string) (*Form, bool) {
+	if g.aimlKB == nil {
+		return nil, false
+	}
+	for i := range g.aimlKB.Forms {
+		if strings.EqualFold(g.aimlKB.Forms[i].Name, name) {
+			return &g.aimlKB.Forms[i], true
+		}
+	}
+	return nil, false
+}
+
+// ActiveForm returns the form the session is filling in, nil when there is none
+func (session *ChatSession) ActiveForm() *FormState {
+	if len(session.Forms) == 0 {
+		return nil
+	}
+	return session.Forms[len(session.Forms)-1]
+}
+
+// currentSlot returns the first slot of form without a value, nil when the form is complete
+func (state *FormState) currentSlot(form *Form) *FormSlot {
+	for i := range form.Slots {
+		if _, filled := state.Values[form.Slots[i].Name]; !filled {
+			return &form.Slots[i]
+		}
+	}
+	return nil
+}
+
+// StartForm makes the named form the session's active form and returns the prompt (a template) of
+// its first missing slot. A form started earlier and interrupted by another one resumes where it
+// stopped; one whose slots are all filled, for example from a restored session, starts over.
+func (g *Golem) StartForm(session *ChatSession, name string) (string, error) {
+	form, exists := g.Form(name)
+	if !exists {
+		return "", fmt.Errorf("form '%s' not found", name)
+	}
+	for i, state := range session.Forms {
+		if strings.EqualFold(state.Name, form.Name) {
+			session.Forms = append(session.Forms[:i:i], session.Forms[i+1:]...)
+			if slot := state.currentSlot(form); slot != nil {
+				session.Forms = append(session.Forms, state)
+				g.LogInfo("Resuming form %s", form.Name)
+				return slot.Prompt, nil
+			}
+			break
+		}
+	}
+	session.Forms = append(session.Forms, &FormState{Name: form.Name, Values: make(map[string]string)})
+	g.LogInfo("Starting form %s", form.Name)
+	return form.Slots[0].Prompt, nil
+}
+
+// CancelForm abandons the named form, or the active form when name is empty
+func (g *Golem) CancelForm(session *ChatSession, name string) bool {
+	for i := len(session.Forms) - 1; i >= 0; i-- {
+		if name == "" || strings.EqualFold(session.Forms[i].Name, name) {
+			g.LogInfo("Cancelling form %s", session.Forms[i].Name)
+			session.Forms = append(session.Forms[:i], session.Forms[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// formPrompt returns the prompt of the named form's current slot if it is the active form
+func (g *Golem) formPrompt(session *ChatSession, name string) string {
+	state := session.ActiveForm()
+	if state == nil || !strings.EqualFold(state.Name, name) {
+		return ""
+	}
+	form, exists := g.Form(state.Name)
+	if !exists {
+		return ""
+	}
+	if slot := state.currentSlot(form); slot != nil {
+		return slot.Prompt
+	}
+	return ""
+}
+
+// validateSlot checks an answer against the slot's validator and returns the value to store
+func (g *Golem) validateSlot(slot *FormSlot, input string) (string, bool) {
+	answer := strings.TrimSpace(input)
+	switch {
+	case slot.Entity != "":
+		entities := recognizeEntities(answer, map[string]bool{strings.ToUpper(slot.Entity): true}, g.GetClock().Now())
+		if len(entities) == 0 {
+			return "", false
+		}
+		return entities[0].Value, true
+	case slot.Regex != "":
+		re, err := regexp.Compile(slot.Regex)
+		if err != nil {
+			return "", false
+		}
+		match := re.FindStringSubmatch(answer)
+		if match == nil {
+			return "", false
+		}
+		if len(match) > 1 {
+			return match[1], true
+		}
+		return match[0], true
+	case slot.Set != "":
+		name := strings.ToUpper(slot.Set)
+		if provider := g.setProvider(name); provider != nil {
+			return answer, provider.isMember(g.CachedNormalizePattern(answer), g.GetClock().Now())
+		}
+		if entityTypes[name] && len(g.aimlKB.Sets[name]) == 0 {
+			entity, ok := RecognizeEntity(name, answer, g.GetClock().Now())
+			return entity.Text, ok
+		}
+		if !g.hasSet(g.aimlKB, name) {
+			return "", false
+		}
+		re, err := regexp.Compile("^" + g.expandSetPlaceholders(setPlaceholder(name), g.aimlKB) + "$")
+		if err != nil || !re.MatchString(g.CachedNormalizePattern(answer)) {
+			return "", false
+		}
+		return answer, true
+	}
+	return answer, answer != ""
+}
+
+// applyForm lets the session's active form handle the input, through a copy of the matched
+// category (or a new one when nothing matched) whose template carries the form's response:
+//   - a cancel word abandons the form
+//   - a valid answer fills the slot and asks for the next one, or completes the form
+//   - an input a pattern other than a catch-all matches is an interruption: it is answered and the
+//     current question is asked again
+//   - anything else gets the reprompt
+//
+// A form interrupted by another form resumes when that one completes or is cancelled.
+func (g *Golem) applyForm(category *Category, wildcards map[string]string, input string, session *ChatSession, metadata *ResponseMetadata) (*Category, map[string]string) {
+	state := session.ActiveForm()
+	if state == nil {
+		return category, wildcards
+	}
+	form, exists := g.Form(state.Name)
+	if !exists {
+		g.CancelForm(session, state.Name)
+		return category, wildcards
+	}
+	slot := state.currentSlot(form)
+	if slot == nil {
+		g.CancelForm(session, state.Name)
+		return category, wildcards
+	}
+	metadata.Form = form.Name
+
+	response := Category{Pattern: "*"}
+	if category != nil {
+		response = *category
+	}
+
+	interruption := category != nil && !isCatchAllPattern(category.Pattern)
+	value, valid := g.validateSlot(slot, input)
+	switch {
+	case formCancelWords[g.CachedNormalizePattern(input)]:
+		g.CancelForm(session, form.Name)
+		response.Template = form.Cancel
+		if response.Template == "" {
+			response.Template = defaultFormCancel
+		}
+		response.Template += g.resumeFormTemplate(session)
+		return &response, make(map[string]string)
+	case valid && !(interruption && slot.Set == "" && slot.Regex == "" && slot.Entity == ""):
+		// Free-text slots take any answer except one a pattern recognizes
+		state.Values[slot.Name] = value
+		session.Variables[slot.Name] = value
+		g.LogInfo("Form %s: %s = '%s'", form.Name, slot.Name, value)
+		if next := state.currentSlot(form); next != nil {
+			response.Template = next.Prompt
+			return &response, make(map[string]string)
+		}
+		return g.completeForm(form, session, &response), make(map[string]string)
+	case interruption:
+		g.LogInfo("Form %s interrupted by '%s'", form.Name, category.Pattern)
+		response.Template = category.Template + ` <form name="` + form.Name + `" action="prompt"/>`
+		return &response, wildcards
+	}
+
+	response.Template = slot.Reprompt
+	if response.Template == "" {
+		response.Template = slot.Prompt
+	}
+	return &response, make(map[string]string)
+}
+
+// completeForm closes a filled form: the OOB message is sent now and the srai answered by the
+// returned category's template, followed by the question of a form it interrupted
+func (g *Golem) completeForm(form *Form, session *ChatSession, response *Category) *Category {
+	g.CancelForm(session, form.Name)
+	g.LogInfo("Form %s complete", form.Name)
+	if form.OOB != "" {
+		message := g.ProcessTemplateWithContext(form.OOB, make(map[string]string), session)
+		if _, err := g.oobMgr.ProcessOOB(message, session); err != nil {
+			g.LogWarn("Form %s: %v", form.Name, err)
+		}
+	}
+	response.Template = ""
+	if form.Srai != "" {
+		response.Template = "<srai>" + form.Srai + "</srai>"
+	}
+	response.Template += g.resumeFormTemplate(session)
+	return response
+}
+
+// resumeFormTemplate asks the question of the form that becomes active again, if any
+func (g *Golem) resumeFormTemplate(session *ChatSession) string {
+	if state := session.ActiveForm(); state != nil {
+		return ` <form name="` + state.Name + `" action="prompt"/>`
+	}
+	return ""
+}
@@ -0,0 +1,214 @@
+package golem
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const formsTestAIML = `<aiml version="2.0">
+<form name="book" srai="BOOKING COMPLETE">
+  <slot name="date" entity="date"><prompt>Which day?</prompt><reprompt>Sorry, which day? For example March 15th.</reprompt></slot>
+  <slot name="time" regex="^([0-9]{1,2}(:[0-9]{2})?) ?(am|pm)?$"><prompt>What time?</prompt></slot>
+  <slot name="name"><prompt>Under what name?</prompt></slot>
+  <slot name="confirm" set="answer"><prompt>Book <get name="date"/> at <get name="time"/> for <get name="name"/>?</prompt><reprompt>Please answer yes or no.</reprompt></slot>
+  <oob>BOOK <get name="date"/> <get name="time"/></oob>
+</form>
+<category><pattern>BOOK AN APPOINTMENT</pattern><template>Let's book. <form name="book"/></template></category>
+<category><pattern>BOOKING COMPLETE</pattern><template><condition name="confirm"><li value="yes">Booked <get name="date"/> at <get name="time"/> for <get name="name"/>.</li><li>Not booked.</li></condition></template></category>
+<category><pattern>WHAT ARE YOUR HOURS</pattern><template>We open at 9am.</template></category>
+<category><pattern>*</pattern><template>Pardon?</template></category>
+</aiml>`
+
+const formsTestFile = `{
+  "feedback": {
+    "slots": [
+      {"name": "email", "prompt": "What is your e-mail?", "entity": "email"},
+      {"name": "rating", "prompt": "From 1 to 5?", "regex": "^[1-5]$", "reprompt": "A number from 1 to 5, please."}
+    ],
+    "srai": "FEEDBACK DONE",
+    "cancel": "No feedback then."
+  }
+}`
+
+// recordingOOBHandler remembers the OOB messages it handles
+type recordingOOBHandler struct {
+	messages []string
+}
+
+func (h *recordingOOBHandler) CanHandle(message string) bool {
+	return strings.HasPrefix(message, "BOOK")
+}
+func (h *recordingOOBHandler) GetName() string        { return "BOOK" }
+func (h *recordingOOBHandler) GetDescription() string { return "Records bookings" }
+
+func (h *recordingOOBHandler) Process(message string, session *ChatSession) (string, error) {
+	h.messages = append(h.messages, message)
+	return "", nil
+}
+
+func TestParseForms(t *testing.T) {
+	g := newTestBot(t, formsTestAIML, WithClock(NewFixedClock(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))))
+	g.aimlKB.AddSetMembers("ANSWER", []string{"yes", "no"})
+	form, exists := g.Form("book")
+	if !exists {
+		t.Fatal("Expected the book form to be declared")
+	}
+	if len(form.Slots) != 4 || form.Srai != "BOOKING COMPLETE" || form.Slots[0].Entity != "date" {
+		t.Fatalf("Unexpected form %+v", form)
+	}
+	if form.Slots[3].Prompt != `Book <get name="date"/> at <get name="time"/> for <get name="name"/>?` {
+		t.Errorf("Expected the prompt markup to be kept, got %q", form.Slots[3].Prompt)
+	}
+	if len(g.aimlKB.Categories) != 4 {
+		t.Errorf("Expected the declaration outside the categories, got %d categories", len(g.aimlKB.Categories))
+	}
+
+	if err := g.LoadAIMLFromString(`<aiml version="2.0"><form name="broken"><slot name="a" set="x" entity="date"/></form></aiml>`); err == nil {
+		t.Error("Expected an error for a form without prompts or completion")
+	}
+}
+
+func TestForms(t *testing.T) {
+	g := newTestBot(t, formsTestAIML, WithClock(NewFixedClock(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))))
+	g.aimlKB.AddSetMembers("ANSWER", []string{"yes", "no"})
+	handler := &recordingOOBHandler{}
+	g.oobMgr.RegisterHandler(handler)
+	session := g.CreateSession("forms")
+
+	steps := []struct {
+		input    string
+		expected string
+	}{
+		{"book an appointment", "Let's book. Which day?"},
+		{"whenever", "Sorry, which day? For example March 15th."},
+		// Other patterns interrupt the form, which asks its question again
+		{"what are your hours", "We open at 9am. Which day?"},
+		{"March 15th", "What time?"},
+		{"10:30 am", "Under what name?"},
+		{"Ada Lovelace", "Book 2025-03-15 at 10:30 for Ada Lovelace?"},
+		{"maybe", "Please answer yes or no."},
+		{"yes", "Booked 2025-03-15 at 10:30 for Ada Lovelace."},
+		{"hello", "Pardon?"},
+	}
+	for _, step := range steps {
+		if got := askN(t, g, session, step.input, 1)[0]; got != step.expected {
+			t.Errorf("%q: got %q, expected %q", step.input, got, step.expected)
+		}
+	}
+	if len(handler.messages) != 1 || handler.messages[0] != "BOOK 2025-03-15 10:30" {
+		t.Errorf("Expected the OOB message on completion, got %v", handler.messages)
+	}
+	if session.ActiveForm() != nil {
+		t.Errorf("Expected no active form, got %+v", session.ActiveForm())
+	}
+
+	// Cancelling abandons the form
+	askN(t, g, session, "book an appointment", 1)
+	_, metadata, err := g.ProcessInputWithMetadata("March 16th", session)
+	if err != nil {
+		t.Fatalf("ProcessInputWithMetadata failed: %v", err)
+	}
+	if metadata.Form != "book" {
+		t.Errorf("Expected the form in metadata, got %q", metadata.Form)
+	}
+	if got := askN(t, g, session, "never mind", 1)[0]; got != "OK, I have cancelled that." {
+		t.Errorf("Expected the cancel response, got %q", got)
+	}
+	if got := askN(t, g, session, "10 am", 1)[0]; got != "Pardon?" {
+		t.Errorf("Expected the form to be gone, got %q", got)
+	}
+}
+
+func TestStaleFormState(t *testing.T) {
+	g := newTestBot(t, formsTestAIML)
+	session := g.CreateSession("stale-form")
+	askN(t, g, session, "book an appointment", 1)
+
+	// A knowledge base without the form or a catch-all leaves the session's form state stale
+	kb := NewAIMLKnowledgeBase()
+	kb.Categories = []Category{{Pattern: "HELLO", Template: "Hi."}}
+	kb.Patterns["HELLO"] = &kb.Categories[0]
+	g.SetKnowledgeBase(kb)
+
+	if _, err := g.ProcessInput("tomorrow", session); err == nil {
+		t.Error("Expected no match once the form is gone")
+	}
+	if session.ActiveForm() != nil {
+		t.Errorf("Expected the stale form to be cancelled, got %+v", session.ActiveForm())
+	}
+	if got := askN(t, g, session, "hello", 1)[0]; got != "Hi." {
+		t.Errorf("Expected normal matching, got %q", got)
+	}
+}
+
+func TestStartFilledForm(t *testing.T) {
+	g := newTestBot(t, formsTestAIML)
+	session := g.CreateSession("filled-form")
+	values := map[string]string{"date": "2025-03-15", "time": "10:30", "name": "Ada", "confirm": "yes"}
+	session.Forms = []*FormState{{Name: "book", Values: values}}
+
+	prompt, err := g.StartForm(session, "book")
+	if err != nil {
+		t.Fatalf("StartForm failed: %v", err)
+	}
+	if prompt != "Which day?" {
+		t.Errorf("Expected the filled form to start over, got %q", prompt)
+	}
+	if state := session.ActiveForm(); len(session.Forms) != 1 || len(state.Values) != 0 {
+		t.Errorf("Expected one empty form state, got %+v", session.Forms)
+	}
+}
+
+func TestFormsFromFile(t *testing.T) {
+	dir := t.TempDir()
+	writeResourceFile(t, dir, "bot.aiml", `<aiml version="2.0">
+<category><pattern>GIVE FEEDBACK</pattern><template><form name="feedback"/></template></category>
+<category><pattern>FEEDBACK DONE</pattern><template>Thanks, <get name="email"/> rated us <get name="rating"/>.</template></category>
+<category><pattern>BOOK AN APPOINTMENT</pattern><template><form name="book"/></template></category>
+<category><pattern>*</pattern><template>Pardon?</template></category>
+</aiml>`)
+	if err := os.Mkdir(filepath.Join(dir, "forms"), 0755); err != nil {
+		t.Fatalf("Failed to create forms directory: %v", err)
+	}
+	writeResourceFile(t, filepath.Join(dir, "forms"), "feedback.json", formsTestFile)
+	writeResourceFile(t, filepath.Join(dir, "forms"), "book.json", `{"book": {"slots": [{"name": "day", "prompt": "Which day?", "entity": "date"}], "srai": "BOOKED"}}`)
+
+	g := New(false)
+	kb, err := g.LoadAIMLFromDirectory(dir)
+	if err != nil {
+		t.Fatalf("LoadAIMLFromDirectory failed: %v", err)
+	}
+	g.aimlKB = kb
+	if len(kb.Forms) != 2 {
+		t.Fatalf("Expected 2 forms, got %+v", kb.Forms)
+	}
+	session := g.CreateSession("forms-file")
+
+	steps := []struct {
+		input    string
+		expected string
+	}{
+		{"give feedback", "What is your e-mail?"},
+		// A form started while another is active interrupts it
+		{"book an appointment", "Which day?"},
+		{"cancel", "OK, I have cancelled that. What is your e-mail?"},
+		{"it is Ada@Example.com", "From 1 to 5?"},
+		{"7", "A number from 1 to 5, please."},
+		{"4", "Thanks, ada@example.com rated us 4."},
+		{"give feedback", "What is your e-mail?"},
+		{"stop", "No feedback then."},
+	}
+	for _, step := range steps {
+		if got := askN(t, g, session, step.input, 1)[0]; got != step.expected {
+			t.Errorf("%q: got %q, expected %q", step.input, got, step.expected)
+		}
+	}
+
+	writeResourceFile(t, filepath.Join(dir, "forms"), "bad.json", `{"bad": {"slots": [{"name": "x", "prompt": "X?", "regex": "("}], "srai": "X"}}`)
+	if _, err := g.LoadFormsFromFile(filepath.Join(dir, "forms", "bad.json")); err == nil {
+		t.Error("Expected an error for an invalid regex")
+	}
+}
@@ -0,0 +1,179 @@
+package golem
+
+import (
+	"sort"
+	"strings"
+)
+
+// FuzzyFallback configures the second pass that runs when only a catch-all pattern (*, _, ^ or #)
+// matched: wildcard-free patterns are scored by similarity to the input, the best one answers when
+// it scores at least Threshold, and otherwise the ones scoring at least SuggestThreshold are
+// offered as "Did you mean …?" choices
+type FuzzyFallback struct {
+	// Threshold is the score (0-1) at which the best pattern answers (default 0.75)
+	Threshold float64
+	// SuggestThreshold is the score at which a pattern is offered as a choice (default 0.6)
+	SuggestThreshold float64
+	// MaxSuggestions limits the choices offered (default 3)
+	MaxSuggestions int
+	// SuggestionPrompt starts the response listing the choices (default "Did you mean")
+	SuggestionPrompt string
+}
+
+// FuzzyCandidate is a pattern the fuzzy fallback scored against the input
+type FuzzyCandidate struct {
+	Pattern  string    `json:"pattern"`
+	Score    float64   `json:"score"`
+	Category *Category `json:"-"`
+}
+
+// WithFuzzyFallback scores wildcard-free patterns against inputs only a catch-all pattern matched
+func WithFuzzyFallback(fallback FuzzyFallback) Option {
+	return func(g *Golem) {
+		g.SetFuzzyFallback(&fallback)
+	}
+}
+
+// SetFuzzyFallback enables the fuzzy fallback; nil disables it
+func (g *Golem) SetFuzzyFallback(fallback *FuzzyFallback) {
+	if fallback != nil {
+		settings := *fallback
+		if settings.Threshold <= 0 {
+			settings.Threshold = 0.75
+		}
+		if settings.SuggestThreshold <= 0 {
+			settings.SuggestThreshold = 0.6
+		}
+		if settings.MaxSuggestions <= 0 {
+			settings.MaxSuggestions = 3
+		}
+		if settings.SuggestionPrompt == "" {
+			settings.SuggestionPrompt = "Did you mean"
+		}
+		fallback = &settings
+	}
+	g.fuzzyFallback = fallback
+}
+
+// GetFuzzyFallback returns the fuzzy fallback settings, nil when it is disabled
+func (g *Golem) GetFuzzyFallback() *FuzzyFallback {
+	return g.fuzzyFallback
+}
+
+// isCatchAllPattern reports whether a pattern is a lone wildcard that matches any input
+func isCatchAllPattern(pattern string) bool {
+	switch strings.TrimSpace(pattern) {
+	case "*", "_", "^", "#":
+		return true
+	}
+	return false
+}
+
+// atomicPatternText returns the words of a pattern without wildcards or markup, with priority
+// markers removed; ok is false for patterns that have wildcards or markup
+func atomicPatternText(pattern string) (string, bool) {
+	if strings.ContainsAny(pattern, "*_^#<") {
+		return "", false
+	}
+	words := strings.Fields(strings.ReplaceAll(pattern, "$", ""))
+	return strings.Join(words, " "), len(words) > 0
+}
+
+// fuzzyCandidates scores the wildcard-free patterns that apply without a <that> context in the current
+// topic, best first
+func (g *Golem) fuzzyCandidates(input, topic string) []FuzzyCandidate {
+	if g.fuzzyMatcher == nil {
+		g.fuzzyMatcher = NewFuzzyContextMatcher()
+	}
+	semantic := g.semanticContextMatcher()
+
+	seen := make(map[string]bool)
+	var candidates []FuzzyCandidate
+	for i := range g.aimlKB.Categories {
+		category := &g.aimlKB.Categories[i]
+		text, ok := atomicPatternText(category.Pattern)
+		if !ok || seen[text] {
+			continue
+		}
+		if that := strings.TrimSpace(category.That); that != "" && that != "*" {
+			continue
+		}
+		if categoryTopic := strings.TrimSpace(category.Topic); categoryTopic != "" && categoryTopic != "*" && !strings.EqualFold(categoryTopic, topic) {
+			continue
+		}
+		seen[text] = true
+
+		// The character-level fuzzy score is averaged with the word-level semantic score, which credits
+		// synonyms and domains. Semantic similarity averages over the words of one side, so the
+		// weaker direction is used.
+		_, fuzzyScore := g.fuzzyMatcher.MatchWithFuzzy(input, text)
+		_, forward := semantic.MatchWithSemanticSimilarity(input, text)
+		_, backward := semantic.MatchWithSemanticSimilarity(text, input)
+		score := (fuzzyScore + minFloat(forward, backward)) / 2
+		candidates = append(candidates, FuzzyCandidate{Pattern: text, Score: score, Category: category})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates
+}
+
+// minFloat returns the smaller of two scores
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// applyFuzzyFallback replaces a catch-all match with the best scoring wildcard-free category, or
+// with a copy of the catch-all category whose template offers the closest patterns as choices.
+// The outcome is recorded in metadata.
+func (g *Golem) applyFuzzyFallback(category *Category, wildcards map[string]string, input, topic string, metadata *ResponseMetadata) (*Category, map[string]string) {
+	if g.fuzzyFallback == nil || category == nil || !isCatchAllPattern(category.Pattern) || metadata.Intent != "" ||
+		metadata.Form != "" {
+		return category, wildcards
+	}
+	candidates := g.fuzzyCandidates(input, topic)
+	if len(candidates) == 0 {
+		return category, wildcards
+	}
+
+	if best := candidates[0]; best.Score >= g.fuzzyFallback.Threshold {
+		g.LogInfo("Fuzzy fallback matched '%s' (score %.2f)", best.Pattern, best.Score)
+		metadata.FuzzyMatch = &best
+		return best.Category, make(map[string]string)
+	}
+
+	var suggestions []FuzzyCandidate
+	for _, candidate := range candidates {
+		if candidate.Score < g.fuzzyFallback.SuggestThreshold || len(suggestions) == g.fuzzyFallback.MaxSuggestions {
+			break
+		}
+		suggestions = append(suggestions, candidate)
+	}
+	if len(suggestions) == 0 {
+		return category, wildcards
+	}
+	g.LogInfo("Fuzzy fallback suggestions: %v", suggestions)
+	metadata.Suggestions = suggestions
+
+	// The choices become the response (and <that>) while coverage still counts the catch-all category
+	offer := *category
+	offer.Template = g.suggestionText(suggestions)
+	return &offer, wildcards
+}
+
+// suggestionText lists the choices: Did you mean "a", "b" or "c"?
+func (g *Golem) suggestionText(suggestions []FuzzyCandidate) string {
+	quoted := make([]string, len(suggestions))
+	for i, suggestion := range suggestions {
+		quoted[i] = `"` + strings.ToLower(suggestion.Pattern) + `"`
+	}
+	choices := quoted[len(quoted)-1]
+	if len(quoted) > 1 {
+		choices = strings.Join(quoted[:len(quoted)-1], ", ") + " or " + choices
+	}
+	return g.fuzzyFallback.SuggestionPrompt + " " + choices + "?"
+}
@@ -0,0 +1,88 @@
+package golem
+
+import (
+	"testing"
+)
+
+const fuzzyFallbackTestAIML = `<aiml version="2.0">
+<category><pattern>WHAT IS A PREMIUM</pattern><template>The price of your policy.</template></category>
+<category><pattern>WHAT IS A DEDUCTIBLE</pattern><template>What you pay before we do.</template></category>
+<category><pattern>HOW DO I FILE A CLAIM</pattern><template>Call us.</template></category>
+<category><pattern>CANCEL MY POLICY</pattern><template>Sorry to see you go.</template></category>
+<category><pattern>TELL ME ABOUT *</pattern><template>I know little about <star/>.</template></category>
+<category><pattern>*</pattern><template>Pardon?</template></category>
+<category><pattern>YES</pattern><that>DID YOU MEAN *</that><template>Then ask me again.</template></category>
+</aiml>`
+
+func TestFuzzyFallback(t *testing.T) {
+	g := newTestBot(t, fuzzyFallbackTestAIML, WithFuzzyFallback(FuzzyFallback{}))
+
+	testCases := []struct {
+		input       string
+		expected    string
+		fuzzy       string
+		suggestions int
+	}{
+		{"What is the premium?", "The price of your policy.", "WHAT IS A PREMIUM", 0},
+		{"how can I file a claim", "Call us.", "HOW DO I FILE A CLAIM", 0},
+		{"cancel policy", `Did you mean "cancel my policy"?`, "", 1},
+		{"what is a", `Did you mean "what is a premium" or "what is a deductible"?`, "", 2},
+		// Unrelated input still reaches the catch-all category
+		{"I like turtles", "Pardon?", "", 0},
+		// Inputs a real pattern matches are left alone
+		{"tell me about premiums", "I know little about premiums.", "", 0},
+	}
+	for _, tc := range testCases {
+		session := g.CreateSession("fuzzy")
+		response, metadata, err := g.ProcessInputWithMetadata(tc.input, session)
+		if err != nil {
+			t.Fatalf("ProcessInputWithMetadata failed: %v", err)
+		}
+		if response != tc.expected {
+			t.Errorf("%q: got %q, expected %q", tc.input, response, tc.expected)
+		}
+		if tc.fuzzy == "" && metadata.FuzzyMatch != nil {
+			t.Errorf("%q: expected no fuzzy match, got %v", tc.input, metadata.FuzzyMatch)
+		}
+		if tc.fuzzy != "" && (metadata.FuzzyMatch == nil || metadata.FuzzyMatch.Pattern != tc.fuzzy) {
+			t.Errorf("%q: expected fuzzy match %q, got %v", tc.input, tc.fuzzy, metadata.FuzzyMatch)
+		}
+		if len(metadata.Suggestions) != tc.suggestions {
+			t.Errorf("%q: expected %d suggestions, got %v", tc.input, tc.suggestions, metadata.Suggestions)
+		}
+	}
+}
+
+func TestFuzzyFallbackSuggestionsSetThat(t *testing.T) {
+	g := newTestBot(t, fuzzyFallbackTestAIML, WithFuzzyFallback(FuzzyFallback{}))
+	session := g.CreateSession("fuzzy-that")
+	replies := askN(t, g, session, "cancel policy", 1)
+	if replies[0] != `Did you mean "cancel my policy"?` {
+		t.Fatalf("Expected a suggestion, got %q", replies[0])
+	}
+	if got := askN(t, g, session, "yes", 1)[0]; got != "Then ask me again." {
+		t.Errorf("Expected the suggestion to be the <that> context, got %q", got)
+	}
+}
+
+func TestFuzzyFallbackSettings(t *testing.T) {
+	g := newTestBot(t, fuzzyFallbackTestAIML)
+	session := g.CreateSession("fuzzy-settings")
+	if got := askN(t, g, session, "what is the premium", 1)[0]; got != "Pardon?" {
+		t.Errorf("Expected no fuzzy fallback by default, got %q", got)
+	}
+
+	// A higher threshold turns a match into a suggestion, and the limit caps the choices
+	g.SetFuzzyFallback(&FuzzyFallback{Threshold: 0.95, SuggestThreshold: 0.5, MaxSuggestions: 1, SuggestionPrompt: "Perhaps"})
+	if got := askN(t, g, session, "what is the premium", 1)[0]; got != `Perhaps "what is a premium"?` {
+		t.Errorf("Expected a single suggestion, got %q", got)
+	}
+	if settings := g.GetFuzzyFallback(); settings.MaxSuggestions != 1 || settings.Threshold != 0.95 {
+		t.Errorf("Unexpected settings %+v", settings)
+	}
+
+	g.SetFuzzyFallback(nil)
+	if got := askN(t, g, session, "what is the premium", 1)[0]; got != "Pardon?" {
+		t.Errorf("Expected no fuzzy fallback once disabled, got %q", got)
+	}
+}
@@ -1,9 +1,11 @@
 package golem
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -217,6 +219,27 @@ type ChatSession struct {
 	// Session-specific learning
 	LearnedCategories []Category            // Categories learned in this session
 	LearningStats     *SessionLearningStats // Learning statistics for this session
+
+	// LastMatch is the category that answered the most recent input (nil before the first match)
+	LastMatch *Category
+	// LastResponse describes how the most recent response was produced (nil before the first match)
+	LastResponse *ResponseMetadata
+
+	// Language is the language the most recent input was routed to in a multilingual bot
+	Language string
+
+	// Forms are the forms being filled in, the active one last; the others were interrupted
+	Forms []*FormState
+
+	// Schedules are the messages the bot will send to this session on its own
+	Schedules []*ScheduledMessage
+
+	// RandomSeed seeds the session's own random source (see SetRandomSeed); 0 means unset
+	RandomSeed int64
+	random     *rand.Rand
+
+	// turn is held while the session answers an input or sends scheduled messages
+	turn sync.Mutex
 }
 
 // SessionLearningStats represents learning statistics for a session
@@ -287,8 +310,54 @@ type Golem struct {
 	// Enhanced context resolution components
 	fuzzyMatcher    *FuzzyContextMatcher
 	semanticMatcher *SemanticContextMatcher
+	// Resources the semantic matcher was built from (nil when it was assigned directly), how loaded
+	// synonym, antonym and domain files combine with the built-in tables, and the input expansion flag
+	semanticKey          *semanticResourceKey
+	semanticResourceMode SemanticResourceMode
+	synonymExpansion     bool
+	// Policy for categories defined more than once across loaded sources
+	duplicatePolicy DuplicatePolicy
 	// Random seed for deterministic shuffling
 	randomSeed int64
+	// Clock and random source read by time-dependent and random tags; nil means wall clock and time-based selection
+	clock        Clock
+	randomSource *rand.Rand
+	// Category hit counts, nil unless coverage is enabled
+	coverage *coverageTracker
+	// Set and map providers registered by name
+	providers *providerRegistry
+	// Unicode stage applied to patterns at load and to input before matching
+	unicodeNormalization UnicodeNormalization
+	// Tokenizer for languages written without spaces, nil to split at whitespace
+	tokenizer Tokenizer
+	// Spelling pre-pass settings, nil when disabled, and the vocabulary it corrects to
+	spellingCorrection *SpellingCorrection
+	vocabulary         *spellingVocabulary
+	// Fuzzy second pass for inputs only a catch-all pattern matched, nil when disabled
+	fuzzyFallback *FuzzyFallback
+	// Intent classifier trained from the loaded intents and the confidence needed to use it (0 means 0.5)
+	intentCache      *intentClassifierCache
+	intentConfidence float64
+	// BM25 index of the loaded FAQ passages and the fallback answering from it, nil when disabled
+	faqCache    *faqIndexCache
+	faqFallback *FAQFallback
+	// Entity sets the loaded patterns use, whose spans are protected from normalization
+	entityKinds *entityKindsCache
+	// Store sessions are saved to, the callback receiving scheduled messages, and the running
+	// scheduler's stop channel (nil when stopped); scheduleMutex guards the sessions' schedules
+	sessionStore  SessionStore
+	deliver       DeliveryFunc
+	schedulerStop chan struct{}
+	scheduleMutex sync.Mutex
+	// Language of this bot's knowledge base, selecting the built-in pronoun tables
+	language string
+	// Bots per language for multilingual knowledge bases (this bot serves the default language)
+	languageBots     map[string]*Golem
+	languageDetector *LanguageDetector
+	defaultLanguage  string
+	// Settings last passed on to the language bots, guarded by languageMutex
+	languageSynced *languageSettings
+	languageMutex  sync.Mutex
 	// Tree-based processing components
 	treeProcessor     *TreeProcessor
 	useTreeProcessing bool // Feature flag for tree-based processing
@@ -1055,8 +1124,8 @@ func (cache *TemplateTagProcessingCache) InvalidateContext(context string) {
 	}
 }
 
-// New creates a new Golem instance
-func New(verbose bool) *Golem {
+// New creates a new Golem instance; options such as WithClock and WithRand are applied last
+func New(verbose bool, options ...Option) *Golem {
 	logger := log.New(os.Stdout, "[GOLEM] ", log.LstdFlags)
 
 	// Set log level based on verbose flag
@@ -1145,7 +1214,7 @@ func New(verbose bool) *Golem {
 	// Create tree processor (will be initialized after Golem is created)
 	var treeProcessor *TreeProcessor
 
-	return &Golem{
+	g := &Golem{
 		verbose:                    verbose,
 		logLevel:                   logLevel,
 		logger:                     logger,
@@ -1167,9 +1236,24 @@ func New(verbose bool) *Golem {
 		templateTagProcessingCache: templateTagProcessingCache,
 		patternMatchingCache:       patternMatchingCache,
 		persistentLearning:         persistentLearning,
+		providers:                  newProviderRegistry(),
 		treeProcessor:              treeProcessor,
 		useTreeProcessing:          true, // Tree-based AST processing is now the default (correct AIML behavior)
 	}
+
+	// FAQ retrieval is offered as a local service for <sraix service="faq">
+	sraixMgr.RegisterLocalService("faq", g.faqService)
+	// Circuit breakers and rate limits follow the bot's clock, retry jitter its random source
+	sraixMgr.now = g.now
+	sraixMgr.jitter = g.randomInt63n
+
+	// ALARM OOB messages schedule messages
+	oobMgr.RegisterHandler(&AlarmHandler{golem: g})
+
+	for _, option := range options {
+		option(g)
+	}
+	return g
 }
 
 // LogError logs an error message
@@ -1491,6 +1575,16 @@ func (g *Golem) Execute(command string, args []string) error {
 		return g.analyzeCommand(args)
 	case "generate":
 		return g.generateCommand(args)
+	case "conflicts":
+		return g.conflictsCommand(args)
+	case "test":
+		return g.testCommand(args)
+	case "diff":
+		return g.diffCommand(args)
+	case "intents":
+		return g.intentsCommand(args)
+	case "schedule":
+		return g.scheduleCommand(args)
 	default:
 		return fmt.Errorf("unknown command: %s", command)
 	}
@@ -1582,6 +1676,26 @@ func (g *Golem) loadAllRelatedFiles(filePath string) error {
 		}
 	}
 
+	// Merge synonym, antonym and domain files into knowledge base
+	if synonyms, err := g.LoadSynonymsFromDirectory(dir); err == nil {
+		mergeWordRelations(aimlKB.Synonyms, synonyms)
+	}
+	if antonyms, err := g.LoadAntonymsFromDirectory(dir); err == nil {
+		mergeWordRelations(aimlKB.Antonyms, antonyms)
+	}
+	if domains, err := g.LoadDomainsFromDirectory(dir); err == nil {
+		mergeWordRelations(aimlKB.Domains, domains)
+	}
+	if intents, err := g.LoadIntentsFromDirectory(dir); err == nil {
+		aimlKB.Intents = mergeIntents(aimlKB.Intents, intents)
+	}
+	if passages, err := g.LoadFAQFromDirectory(dir); err == nil {
+		aimlKB.FAQ = append(aimlKB.FAQ, passages...)
+	}
+	if forms, err := g.LoadFormsFromDirectory(dir); err == nil {
+		aimlKB.Forms = mergeForms(aimlKB.Forms, forms)
+	}
+
 	g.LogInfo("About to set knowledge base with %d properties", len(aimlKB.Properties))
 
 	// Set the knowledge base using SetKnowledgeBase to trigger SRAIX configuration
@@ -1602,11 +1716,44 @@ func (g *Golem) loadAllRelatedFiles(filePath string) error {
 }
 
 func (g *Golem) loadCommand(args []string) error {
-	if len(args) == 0 {
+	// Parse optional flags: --report prints the merge report, --duplicates sets the duplicate policy,
+	// --languages loads one knowledge base per language subdirectory
+	showReport := false
+	byLanguage := false
+	var paths []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--report":
+			showReport = true
+		case arg == "--languages":
+			byLanguage = true
+		case arg == "--duplicates":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--duplicates requires first-wins|last-wins|error")
+			}
+			i++
+			policy, err := ParseDuplicatePolicy(args[i])
+			if err != nil {
+				return err
+			}
+			g.SetDuplicatePolicy(policy)
+		case strings.HasPrefix(arg, "--duplicates="):
+			policy, err := ParseDuplicatePolicy(strings.TrimPrefix(arg, "--duplicates="))
+			if err != nil {
+				return err
+			}
+			g.SetDuplicatePolicy(policy)
+		default:
+			paths = append(paths, arg)
+		}
+	}
+
+	if len(paths) == 0 {
 		return fmt.Errorf("load command requires a filename or directory path")
 	}
 
-	path := args[0]
+	path := paths[0]
 	g.LogInfo("Loading: %s", path)
 
 	// Check if path exists and get absolute path
@@ -1622,7 +1769,19 @@ func (g *Golem) loadCommand(args []string) error {
 	}
 
 	// Check if it's a directory
-	if fileInfo.IsDir() {
+	if byLanguage {
+		if !fileInfo.IsDir() {
+			return fmt.Errorf("--languages requires a directory path")
+		}
+		if err := g.LoadLanguageKnowledgeBases(absPath); err != nil {
+			return err
+		}
+		fmt.Printf("Successfully loaded language knowledge bases from directory: %s\n", absPath)
+		for _, language := range g.Languages() {
+			fmt.Printf("  %s: %d categories\n", language, len(g.LanguageKnowledgeBase(language).Categories))
+		}
+		fmt.Printf("Default language: %s\n", g.GetDefaultLanguage())
+	} else if fileInfo.IsDir() {
 		// Load all related files from directory (AIML, maps, sets, properties, etc.)
 		// Use loadAllRelatedFiles which properly triggers SRAIX configuration
 		dummyFilePath := filepath.Join(absPath, "dummy.aiml")
@@ -1668,6 +1827,10 @@ func (g *Golem) loadCommand(args []string) error {
 		}
 	}
 
+	if showReport && g.aimlKB != nil {
+		fmt.Print(g.aimlKB.MergeReport.String())
+	}
+
 	return nil
 }
 
@@ -1705,6 +1868,16 @@ func (g *Golem) chatCommand(args []string) error {
 		return nil
 	}
 
+	// Multilingual bots route through ProcessInput, which picks the language's knowledge base
+	if len(g.languageBots) > 0 {
+		response, err := g.ProcessInput(input, session)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Golem [%s]: %s\n", session.Language, response)
+		return nil
+	}
+
 	// Add to history
 	session.History = append(session.History, "User: "+input)
 
@@ -1723,6 +1896,8 @@ func (g *Golem) chatCommand(args []string) error {
 		return nil
 	}
 
+	g.recordCategoryHit(category)
+
 	// Process template with session context
 	response := g.ProcessTemplateWithSession(category.Template, wildcards, session)
 	fmt.Printf("Golem: %s\n", response)
@@ -1818,6 +1993,302 @@ func (g *Golem) generateCommand(args []string) error {
 	return nil
 }
 
+// loadBotDirectory loads every AIML, set, map and properties file of a bot.
+// A file path loads the directory containing it.
+func (g *Golem) loadBotDirectory(path string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path for %s: %v", path, err)
+	}
+	fileInfo, err := os.Stat(absPath)
+	if err != nil {
+		return fmt.Errorf("path does not exist: %s", absPath)
+	}
+	if !fileInfo.IsDir() {
+		absPath = filepath.Dir(absPath)
+	}
+
+	if err := g.loadAllRelatedFiles(filepath.Join(absPath, "dummy.aiml")); err != nil {
+		return fmt.Errorf("failed to load files from directory: %v", err)
+	}
+	return nil
+}
+
+// testCommand runs declarative conversation tests against a bot
+func (g *Golem) testCommand(args []string) error {
+	junitFile := ""
+	coverageJSONFile := ""
+	showCoverage := false
+	byLanguage := false
+	var paths []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--languages":
+			byLanguage = true
+		case arg == "--junit":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--junit requires a file name")
+			}
+			i++
+			junitFile = args[i]
+		case strings.HasPrefix(arg, "--junit="):
+			junitFile = strings.TrimPrefix(arg, "--junit=")
+		case arg == "--coverage":
+			showCoverage = true
+		case arg == "--coverage-json":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--coverage-json requires a file name")
+			}
+			i++
+			coverageJSONFile = args[i]
+		case strings.HasPrefix(arg, "--coverage-json="):
+			coverageJSONFile = strings.TrimPrefix(arg, "--coverage-json=")
+		default:
+			paths = append(paths, arg)
+		}
+	}
+
+	if len(paths) < 2 {
+		return fmt.Errorf("test command requires a bot directory and a test file or directory")
+	}
+
+	if byLanguage {
+		if err := g.LoadLanguageKnowledgeBases(paths[0]); err != nil {
+			return err
+		}
+	} else if err := g.loadBotDirectory(paths[0]); err != nil {
+		return err
+	}
+
+	if showCoverage || coverageJSONFile != "" {
+		g.EnableCoverage()
+	}
+
+	report, err := g.RunConversationTestPath(paths[1])
+	if err != nil {
+		return err
+	}
+	fmt.Print(report.String())
+
+	if showCoverage {
+		fmt.Println()
+		fmt.Print(g.CoverageReport().String())
+	}
+	if coverageJSONFile != "" {
+		data, err := json.MarshalIndent(g.CoverageReport(), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode coverage report: %v", err)
+		}
+		if err := os.WriteFile(coverageJSONFile, data, 0644); err != nil {
+			return fmt.Errorf("failed to write coverage report %s: %v", coverageJSONFile, err)
+		}
+	}
+
+	if junitFile != "" {
+		out, err := os.Create(junitFile)
+		if err != nil {
+			return fmt.Errorf("failed to create JUnit report %s: %v", junitFile, err)
+		}
+		defer out.Close()
+		if err := report.WriteJUnit(out); err != nil {
+			return err
+		}
+	}
+
+	if total, failed := report.Counts(); failed > 0 {
+		return fmt.Errorf("%d of %d conversations failed", failed, total)
+	}
+	return nil
+}
+
+// diffCommand replays recorded inputs through two bot directories and reports changed turns
+func (g *Golem) diffCommand(args []string) error {
+	var oldDir, newDir, inputs, clockValue string
+	asJSON := false
+	options := ReplayOptions{Seed: 1}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		value := ""
+		if i+1 < len(args) {
+			value = args[i+1]
+		}
+		switch arg {
+		case "--old":
+			oldDir = value
+			i++
+		case "--new":
+			newDir = value
+			i++
+		case "--inputs":
+			inputs = value
+			i++
+		case "--clock":
+			clockValue = value
+			i++
+		case "--seed":
+			seed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid seed: %s", value)
+			}
+			options.Seed = seed
+			i++
+		case "--json":
+			asJSON = true
+		default:
+			return fmt.Errorf("unknown diff option: %s", arg)
+		}
+	}
+
+	if oldDir == "" || newDir == "" || inputs == "" {
+		return fmt.Errorf("diff command requires --old <dir>, --new <dir> and --inputs <file>")
+	}
+
+	// Both replays see the same instant unless a clock is given
+	options.Clock = time.Now()
+	if clockValue != "" {
+		clock, err := parseTestClock(clockValue)
+		if err != nil {
+			return err
+		}
+		options.Clock = *clock
+	}
+
+	sessions, err := LoadReplaySessions(inputs)
+	if err != nil {
+		return err
+	}
+
+	oldBot := New(g.verbose)
+	if err := oldBot.loadBotDirectory(oldDir); err != nil {
+		return fmt.Errorf("failed to load old bot: %v", err)
+	}
+	newBot := New(g.verbose)
+	if err := newBot.loadBotDirectory(newDir); err != nil {
+		return fmt.Errorf("failed to load new bot: %v", err)
+	}
+
+	report := DiffKnowledgeBases(oldBot, newBot, sessions, options)
+	if asJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode diff report: %v", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Print(report.String())
+	return nil
+}
+
+// conflictsCommand loads a bot directory and reports categories that compete for the same input
+func (g *Golem) conflictsCommand(args []string) error {
+	asJSON := false
+	minSeverity := "low"
+	var paths []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--json":
+			asJSON = true
+		case arg == "--min-severity":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--min-severity requires low|medium|high|critical")
+			}
+			i++
+			minSeverity = args[i]
+		case strings.HasPrefix(arg, "--min-severity="):
+			minSeverity = strings.TrimPrefix(arg, "--min-severity=")
+		default:
+			paths = append(paths, arg)
+		}
+	}
+
+	if len(paths) == 0 {
+		return fmt.Errorf("conflicts command requires a directory path")
+	}
+	if _, ok := conflictSeverityRank[strings.ToLower(minSeverity)]; !ok {
+		return fmt.Errorf("unknown severity: %s (expected low, medium, high or critical)", minSeverity)
+	}
+
+	// Load sets and maps too so <set> patterns are analyzed with their members
+	if err := g.loadBotDirectory(paths[0]); err != nil {
+		return err
+	}
+
+	report, err := g.DetectCategoryConflicts()
+	if err != nil {
+		return err
+	}
+	report = report.Filter(minSeverity)
+
+	if asJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode conflict report: %v", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Print(report.String())
+	return nil
+}
+
+// intentsCommand evaluates the intents of a bot directory: golem intents eval <dir> [--folds N] [--json]
+func (g *Golem) intentsCommand(args []string) error {
+	if len(args) == 0 || args[0] != "eval" {
+		return fmt.Errorf("intents command requires a subcommand: eval <dir> [--folds N] [--json]")
+	}
+	folds := 5
+	asJSON := false
+	var paths []string
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--json":
+			asJSON = true
+		case arg == "--folds":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--folds requires N")
+			}
+			i++
+			n, err := strconv.Atoi(args[i])
+			if err != nil || n < 2 {
+				return fmt.Errorf("invalid fold count: %s (expected 2 or more)", args[i])
+			}
+			folds = n
+		default:
+			paths = append(paths, arg)
+		}
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("intents eval requires a directory path")
+	}
+
+	intents, err := g.LoadIntentsFromDirectory(paths[0])
+	if err != nil {
+		return err
+	}
+	if len(intents) == 0 {
+		return fmt.Errorf("no intents found in %s (expected intents/*.json)", paths[0])
+	}
+
+	evaluation := EvaluateIntents(intents, folds)
+	if asJSON {
+		data, err := json.MarshalIndent(evaluation, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode intent evaluation: %v", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Print(evaluation.String())
+	return nil
+}
+
 // ProcessData is a library function that can be used by other programs
 func (g *Golem) ProcessData(input string) (string, error) {
 	g.LogInfo("Processing data: %s", input)
@@ -1829,17 +2300,31 @@ func (g *Golem) ProcessData(input string) (string, error) {
 
 // ProcessInput processes user input with full context support
 func (g *Golem) ProcessInput(input string, session *ChatSession) (string, error) {
+	// Scheduled messages for the session wait until the input is answered
+	session.turn.Lock()
+	defer session.turn.Unlock()
+	return g.processInput(input, session)
+}
+
+// processInput answers input in a session whose turn lock is held
+func (g *Golem) processInput(input string, session *ChatSession) (string, error) {
 	if g.aimlKB == nil {
 		return "", fmt.Errorf("no AIML knowledge base loaded")
 	}
 
+	// Multilingual bots answer from the knowledge base of the input's language
+	if bot := g.languageBot(input, session); bot != g {
+		return bot.processInput(input, session)
+	}
+
 	g.LogInfo("Processing input: %s", input)
 
-	// Normalize input
-	normalizedInput := g.CachedNormalizePattern(input)
+	// Expand synonyms and correct misspelled words; <input/> and the request history keep the text as typed
+	matchedInput, synonyms := g.expandSynonyms(input)
+	matchedInput, corrections := g.correctSpelling(matchedInput)
 
 	// Get current topic and that context
-	currentTopic := session.GetSessionTopic()
+	currentTopic := g.prepareForMatching(session.GetSessionTopic())
 	lastThat := session.GetLastThat()
 
 	// Normalize the that context for matching using enhanced that normalization
@@ -1848,11 +2333,34 @@ func (g *Golem) ProcessInput(input string, session *ChatSession) (string, error)
 		normalizedThat = g.CachedNormalizeThatPattern(lastThat)
 	}
 
-	// Try to match pattern with full context (using index 0 for last response)
-	category, wildcards, err := g.aimlKB.MatchPatternWithTopicAndThatIndexOriginalCached(g, normalizedInput, input, currentTopic, normalizedThat, 0)
-	if err != nil {
+	// Try to match pattern with full context (using index 0 for last response), holding the spans of
+	// entity sets the patterns use in placeholder words when an entity pattern takes them
+	category, wildcards, entities, err := g.matchWithEntities(matchedInput, func(input string) (*Category, map[string]string, error) {
+		return g.aimlKB.MatchPatternWithTopicAndThatIndexOriginalCached(g, g.CachedNormalizePattern(input), input, currentTopic, normalizedThat, 0)
+	})
+	if err != nil && session.ActiveForm() == nil && g.faqFallback == nil {
 		return "", err
 	}
+	corrections = g.restoreCorrections(category, wildcards, corrections)
+	metadata := &ResponseMetadata{Input: input, MatchedInput: matchedInput, Language: session.Language, Synonyms: synonyms, Corrections: corrections, Entities: entities}
+	// An active form takes the input first: answers fill its slots
+	category, wildcards = g.applyForm(category, wildcards, input, session, metadata)
+	// Without a match the fallbacks answer as if a catch-all had matched
+	unmatched := &Category{Pattern: "*"}
+	if category == nil {
+		category, wildcards = unmatched, map[string]string{"star1": matchedInput}
+	}
+	category, wildcards = g.applyIntentFallback(category, wildcards, matchedInput, metadata)
+	category, wildcards = g.applyFuzzyFallback(category, wildcards, g.CachedNormalizePattern(matchedInput), currentTopic, metadata)
+	category, wildcards = g.applyFAQFallback(category, wildcards, matchedInput, metadata)
+	if category == unmatched {
+		// No fallback answered, or the form was gone and nothing matched
+		return "", err
+	}
+	metadata.Category = category
+	g.recordCategoryHit(category)
+	session.LastMatch = category
+	session.LastResponse = metadata
 
 	// Capture that context from template before processing (for next input)
 	// This needs to be done before the template is processed because <set> tags might change the content
@@ -1863,7 +2371,7 @@ func (g *Golem) ProcessInput(input string, session *ChatSession) (string, error)
 
 	// Add to history
 	session.History = append(session.History, input)
-	session.LastActivity = time.Now().Format(time.RFC3339)
+	session.LastActivity = g.now().Format(time.RFC3339)
 
 	// Add to request history for <request> tag support
 	session.AddToRequestHistory(input)
@@ -1881,17 +2389,29 @@ func (g *Golem) ProcessInput(input string, session *ChatSession) (string, error)
 
 // ProcessInputWithThatIndex processes user input with specific that context index
 func (g *Golem) ProcessInputWithThatIndex(input string, session *ChatSession, thatIndex int) (string, error) {
+	session.turn.Lock()
+	defer session.turn.Unlock()
+	return g.processInputWithThatIndex(input, session, thatIndex)
+}
+
+// processInputWithThatIndex answers input in a session whose turn lock is held
+func (g *Golem) processInputWithThatIndex(input string, session *ChatSession, thatIndex int) (string, error) {
 	if g.aimlKB == nil {
 		return "", fmt.Errorf("no AIML knowledge base loaded")
 	}
 
+	if bot := g.languageBot(input, session); bot != g {
+		return bot.processInputWithThatIndex(input, session, thatIndex)
+	}
+
 	g.LogInfo("Processing input with that index %d: %s", thatIndex, input)
 
-	// Normalize input
-	normalizedInput := g.CachedNormalizePattern(input)
+	// Expand synonyms and correct misspelled words; <input/> and the request history keep the text as typed
+	matchedInput, synonyms := g.expandSynonyms(input)
+	matchedInput, corrections := g.correctSpelling(matchedInput)
 
 	// Get current topic and that context by index
-	currentTopic := session.GetSessionTopic()
+	currentTopic := g.prepareForMatching(session.GetSessionTopic())
 	thatContext := session.GetThatByIndex(thatIndex)
 
 	g.LogInfo("That context for index %d: '%s'", thatIndex, thatContext)
@@ -1903,11 +2423,34 @@ func (g *Golem) ProcessInputWithThatIndex(input string, session *ChatSession, th
 		normalizedThat = g.CachedNormalizeThatPattern(thatContext)
 	}
 
-	// Try to match pattern with full context and specific that index
-	category, wildcards, err := g.aimlKB.MatchPatternWithTopicAndThatIndexOriginalCached(g, normalizedInput, input, currentTopic, normalizedThat, thatIndex)
-	if err != nil {
+	// Try to match pattern with full context and specific that index, holding the spans of
+	// entity sets the patterns use in placeholder words when an entity pattern takes them
+	category, wildcards, entities, err := g.matchWithEntities(matchedInput, func(input string) (*Category, map[string]string, error) {
+		return g.aimlKB.MatchPatternWithTopicAndThatIndexOriginalCached(g, g.CachedNormalizePattern(input), input, currentTopic, normalizedThat, thatIndex)
+	})
+	if err != nil && session.ActiveForm() == nil && g.faqFallback == nil {
 		return "", err
 	}
+	corrections = g.restoreCorrections(category, wildcards, corrections)
+	metadata := &ResponseMetadata{Input: input, MatchedInput: matchedInput, Language: session.Language, Synonyms: synonyms, Corrections: corrections, Entities: entities}
+	// An active form takes the input first: answers fill its slots
+	category, wildcards = g.applyForm(category, wildcards, input, session, metadata)
+	// Without a match the fallbacks answer as if a catch-all had matched
+	unmatched := &Category{Pattern: "*"}
+	if category == nil {
+		category, wildcards = unmatched, map[string]string{"star1": matchedInput}
+	}
+	category, wildcards = g.applyIntentFallback(category, wildcards, matchedInput, metadata)
+	category, wildcards = g.applyFuzzyFallback(category, wildcards, g.CachedNormalizePattern(matchedInput), currentTopic, metadata)
+	category, wildcards = g.applyFAQFallback(category, wildcards, matchedInput, metadata)
+	if category == unmatched {
+		// No fallback answered, or the form was gone and nothing matched
+		return "", err
+	}
+	metadata.Category = category
+	g.recordCategoryHit(category)
+	session.LastMatch = category
+	session.LastResponse = metadata
 
 	// Capture that context from template before processing (for next input)
 	// This needs to be done before the template is processed because <set> tags might change the content
@@ -1918,7 +2461,7 @@ func (g *Golem) ProcessInputWithThatIndex(input string, session *ChatSession, th
 
 	// Add to history
 	session.History = append(session.History, input)
-	session.LastActivity = time.Now().Format(time.RFC3339)
+	session.LastActivity = g.now().Format(time.RFC3339)
 
 	// Add to request history for <request> tag support
 	session.AddToRequestHistory(input)
@@ -2162,7 +2705,7 @@ func (g *Golem) createSession(sessionID string) *ChatSession {
 		g.sessionID++
 	}
 
-	now := time.Now().Format(time.RFC3339)
+	now := g.now().Format(time.RFC3339)
 	session := &ChatSession{
 		ID:                sessionID,
 		Variables:         make(map[string]string),
@@ -2453,6 +2996,24 @@ func (g *Golem) sraixListCommand() error {
 			fmt.Printf("  Fallback: %s\n", config.FallbackResponse)
 		}
 		fmt.Printf("  Wildcards: %t\n", config.IncludeWildcards)
+		if config.ServerErrorRetries > 0 || config.RateLimitRetries > 0 {
+			fmt.Printf("  Retries: %d on 5xx, %d on 429 (%v to %v backoff)\n", config.ServerErrorRetries,
+				config.RateLimitRetries, config.retryBaseDelay(), config.retryMaxDelay())
+		}
+		status, _ := g.sraixMgr.ServiceStatus(name)
+		switch {
+		case config.BreakerThreshold <= 0:
+			fmt.Printf("  Circuit breaker: disabled\n")
+		case status.Breaker == BreakerOpen:
+			fmt.Printf("  Circuit breaker: open (probe in %v)\n", status.RetryAt.Sub(g.now()).Round(time.Second))
+		case status.Breaker == BreakerHalfOpen:
+			fmt.Printf("  Circuit breaker: half-open (next request probes)\n")
+		default:
+			fmt.Printf("  Circuit breaker: closed (%d/%d failures)\n", status.Failures, config.BreakerThreshold)
+		}
+		if config.RateLimit > 0 {
+			fmt.Printf("  Rate limit: %g/s, burst %g (%.1f available)\n", config.RateLimit, config.rateBurst(), status.Tokens)
+		}
 		fmt.Println()
 	}
 
@@ -2704,11 +3265,13 @@ func (g *Golem) InvalidatePatternMatchingKnowledgeBase() {
 	}
 }
 
-// InvalidatePatternMatchingSet invalidates pattern matching cache when a set changes
+// InvalidatePatternMatchingSet invalidates pattern matching cache when a set or a provider's data changes
 func (g *Golem) InvalidatePatternMatchingSet(setName string) {
 	if g.patternMatchingCache != nil {
 		g.patternMatchingCache.InvalidateSet(setName)
 	}
+	// Providers with this name drop their cached answers too
+	g.invalidateProviderCaches(setName)
 }
 
 // generateKnowledgeBaseHash creates a simple hash of the knowledge base state
@@ -3056,6 +3619,7 @@ func (cache *PatternMatchingCache) InvalidateSet(setName string) {
 
 // CachedNormalizePattern normalizes AIML patterns with caching
 func (g *Golem) CachedNormalizePattern(pattern string) string {
+	pattern = g.prepareForMatching(pattern)
 	if g.textNormalizationCache != nil {
 		if result, err := g.textNormalizationCache.GetNormalizedText(g, pattern, "NormalizePattern"); err == nil {
 			return result
@@ -3079,6 +3643,7 @@ func (g *Golem) CachedNormalizeForMatchingCasePreserving(input string) string {
 
 // CachedNormalizeThatPattern normalizes that patterns with caching
 func (g *Golem) CachedNormalizeThatPattern(pattern string) string {
+	pattern = g.prepareForMatching(pattern)
 	if g.textNormalizationCache != nil {
 		if result, err := g.textNormalizationCache.GetNormalizedText(g, pattern, "NormalizeThatPattern"); err == nil {
 			return result
@@ -0,0 +1,394 @@
+package golem
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Intent is a named goal with example utterances, answered by redirecting to its srai target
+type Intent struct {
+	Name     string   `json:"name"`
+	Examples []string `json:"examples"`
+	Srai     string   `json:"srai"`
+}
+
+// intentExample is one training utterance as a unit-length TF-IDF vector
+type intentExample struct {
+	intent string
+	vector map[string]float64
+}
+
+// IntentClassifier is an offline TF-IDF nearest-example classifier. An input scores against each
+// intent by the cosine similarity of its closest example, so inputs sharing no weighted words with
+// any example score zero instead of being forced into some intent.
+type IntentClassifier struct {
+	intents   map[string]Intent
+	idf       map[string]float64
+	unseenIDF float64
+	examples  []intentExample
+}
+
+// IntentEvaluation reports the cross-validated accuracy of an intent classifier
+type IntentEvaluation struct {
+	Folds    int                          `json:"folds"`
+	Total    int                          `json:"total"`
+	Correct  int                          `json:"correct"`
+	Accuracy float64                      `json:"accuracy"`
+	Intents  map[string]*IntentEvalResult `json:"intents"`
+	Misses   []IntentMiss                 `json:"misses,omitempty"`
+}
+
+// IntentEvalResult is the cross-validated accuracy of one intent
+type IntentEvalResult struct {
+	Total    int     `json:"total"`
+	Correct  int     `json:"correct"`
+	Accuracy float64 `json:"accuracy"`
+}
+
+// IntentMiss is a held-out example the classifier got wrong
+type IntentMiss struct {
+	Example    string  `json:"example"`
+	Expected   string  `json:"expected"`
+	Predicted  string  `json:"predicted"`
+	Confidence float64 `json:"confidence"`
+}
+
+// intentClassifierCache is the classifier trained from one knowledge base's intents
+type intentClassifierCache struct {
+	kb         *AIMLKnowledgeBase
+	intents    int
+	classifier *IntentClassifier
+}
+
+// intentTerms returns the features of a text: lowercased words and adjacent word pairs
+func intentTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+	terms := make([]string, 0, len(words)*2)
+	for i, word := range words {
+		terms = append(terms, word)
+		if i > 0 {
+			terms = append(terms, words[i-1]+" "+word)
+		}
+	}
+	return terms
+}
+
+// NewIntentClassifier trains a classifier from intents
+func NewIntentClassifier(intents []Intent) *IntentClassifier {
+	c := &IntentClassifier{intents: make(map[string]Intent), idf: make(map[string]float64)}
+	var documents [][]string
+	var labels []string
+	for _, intent := range intents {
+		c.intents[intent.Name] = intent
+		for _, example := range intent.Examples {
+			if terms := intentTerms(example); len(terms) > 0 {
+				documents = append(documents, terms)
+				labels = append(labels, intent.Name)
+			}
+		}
+	}
+
+	// Smoothed inverse document frequency: terms in every example still weigh a little
+	frequency := make(map[string]int)
+	for _, terms := range documents {
+		seen := make(map[string]bool)
+		for _, term := range terms {
+			if !seen[term] {
+				seen[term] = true
+				frequency[term]++
+			}
+		}
+	}
+	for term, count := range frequency {
+		c.idf[term] = math.Log(float64(1+len(documents))/float64(1+count)) + 1
+	}
+	c.unseenIDF = math.Log(float64(1+len(documents))) + 1
+
+	for i, terms := range documents {
+		c.examples = append(c.examples, intentExample{intent: labels[i], vector: c.vectorize(terms)})
+	}
+	return c
+}
+
+// vectorize builds the unit-length TF-IDF vector of terms. Terms no example has weigh as much as
+// the rarest ones, so unfamiliar words lower the confidence.
+func (c *IntentClassifier) vectorize(terms []string) map[string]float64 {
+	vector := make(map[string]float64)
+	for _, term := range terms {
+		if idf, exists := c.idf[term]; exists {
+			vector[term] += idf
+		} else {
+			vector[term] += c.unseenIDF
+		}
+	}
+	norm := 0.0
+	for _, weight := range vector {
+		norm += weight * weight
+	}
+	norm = math.Sqrt(norm)
+	for term := range vector {
+		vector[term] /= norm
+	}
+	return vector
+}
+
+// Intents returns the intent names, sorted
+func (c *IntentClassifier) Intents() []string {
+	names := make([]string, 0, len(c.intents))
+	for name := range c.intents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Intent returns an intent by name
+func (c *IntentClassifier) Intent(name string) (Intent, bool) {
+	intent, exists := c.intents[name]
+	return intent, exists
+}
+
+// Classify returns the most likely intent of text and a confidence between 0 and 1.
+// The intent is empty when text shares no words with any example.
+func (c *IntentClassifier) Classify(text string) (string, float64) {
+	vector := c.vectorize(intentTerms(text))
+	best, bestScore := "", 0.0
+	for _, example := range c.examples {
+		score := 0.0
+		for term, weight := range vector {
+			score += weight * example.vector[term]
+		}
+		if score > bestScore || (score == bestScore && score > 0 && example.intent < best) {
+			best, bestScore = example.intent, score
+		}
+	}
+	return best, math.Min(bestScore, 1)
+}
+
+// EvaluateIntents estimates the accuracy of a classifier trained on intents with k-fold
+// cross-validation. Each intent's examples are dealt round-robin into the folds, so every fold
+// holds out a share of every intent.
+func EvaluateIntents(intents []Intent, folds int) *IntentEvaluation {
+	if folds < 2 {
+		folds = 5
+	}
+	evaluation := &IntentEvaluation{Folds: folds, Intents: make(map[string]*IntentEvalResult)}
+	for fold := 0; fold < folds; fold++ {
+		var training []Intent
+		type heldOut struct{ intent, example string }
+		var testing []heldOut
+		for _, intent := range intents {
+			kept := Intent{Name: intent.Name, Srai: intent.Srai}
+			for i, example := range intent.Examples {
+				if i%folds == fold {
+					testing = append(testing, heldOut{intent.Name, example})
+				} else {
+					kept.Examples = append(kept.Examples, example)
+				}
+			}
+			training = append(training, kept)
+		}
+
+		classifier := NewIntentClassifier(training)
+		for _, test := range testing {
+			result := evaluation.Intents[test.intent]
+			if result == nil {
+				result = &IntentEvalResult{}
+				evaluation.Intents[test.intent] = result
+			}
+			predicted, confidence := classifier.Classify(test.example)
+			evaluation.Total++
+			result.Total++
+			if predicted == test.intent {
+				evaluation.Correct++
+				result.Correct++
+				continue
+			}
+			evaluation.Misses = append(evaluation.Misses, IntentMiss{Example: test.example, Expected: test.intent, Predicted: predicted, Confidence: confidence})
+		}
+	}
+
+	if evaluation.Total > 0 {
+		evaluation.Accuracy = float64(evaluation.Correct) / float64(evaluation.Total)
+	}
+	for _, result := range evaluation.Intents {
+		result.Accuracy = float64(result.Correct) / float64(result.Total)
+	}
+	return evaluation
+}
+
+// String formats the evaluation for the terminal
+func (e *IntentEvaluation) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Intent accuracy (%d-fold cross-validation): %.1f%% (%d/%d)\n", e.Folds, e.Accuracy*100, e.Correct, e.Total)
+	names := make([]string, 0, len(e.Intents))
+	for name := range e.Intents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		result := e.Intents[name]
+		fmt.Fprintf(&sb, "  %-24s %5.1f%% (%d/%d)\n", name, result.Accuracy*100, result.Correct, result.Total)
+	}
+	if len(e.Misses) > 0 {
+		sb.WriteString("Misclassified examples:\n")
+		for _, miss := range e.Misses {
+			predicted := miss.Predicted
+			if predicted == "" {
+				predicted = "(none)"
+			}
+			fmt.Fprintf(&sb, "  %q: expected %s, got %s (%.2f)\n", miss.Example, miss.Expected, predicted, miss.Confidence)
+		}
+	}
+	return sb.String()
+}
+
+// LoadIntentsFromFile loads an intents file: a JSON object mapping each intent name to its examples
+// and srai target, {"file_claim": {"examples": ["I had an accident"], "srai": "HOW DO I FILE A CLAIM"}}
+func (g *Golem) LoadIntentsFromFile(filename string) ([]Intent, error) {
+	g.LogInfo("Loading intents file: %s", filename)
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read intents file %s: %v", filename, err)
+	}
+	var entries map[string]struct {
+		Examples []string `json:"examples"`
+		Srai     string   `json:"srai"`
+	}
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON in intents file %s: %v", filename, err)
+	}
+
+	var intents []Intent
+	var problems []string
+	for name, entry := range entries {
+		switch {
+		case strings.TrimSpace(entry.Srai) == "":
+			problems = append(problems, fmt.Sprintf("intent %s has no srai target", name))
+		case len(entry.Examples) == 0:
+			problems = append(problems, fmt.Sprintf("intent %s has no examples", name))
+		default:
+			intents = append(intents, Intent{Name: name, Examples: entry.Examples, Srai: strings.TrimSpace(entry.Srai)})
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("invalid intents in %s:\n  %s", filename, strings.Join(problems, "\n  "))
+	}
+	sort.Slice(intents, func(i, j int) bool { return intents[i].Name < intents[j].Name })
+
+	g.LogInfo("Loaded %d intents from %s", len(intents), filename)
+	return intents, nil
+}
+
+// isIntentsFile reports whether path is a .json file in a directory named intents
+func isIntentsFile(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".json") && strings.EqualFold(filepath.Base(filepath.Dir(path)), "intents")
+}
+
+// LoadIntentsFromDirectory loads the intents/*.json files found under dirPath
+func (g *Golem) LoadIntentsFromDirectory(dirPath string) ([]Intent, error) {
+	files, err := findResourceFiles(dirPath, isIntentsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var allIntents []Intent
+	for _, file := range files {
+		intents, err := g.LoadIntentsFromFile(file)
+		if err != nil {
+			// Log the error but continue with other files
+			g.LogWarn("Failed to load %s: %v", file, err)
+			continue
+		}
+		allIntents = mergeIntents(allIntents, intents)
+	}
+	return allIntents, nil
+}
+
+// mergeIntents adds intents to target; an intent defined again gains the new examples and srai target
+func mergeIntents(target, intents []Intent) []Intent {
+	for _, intent := range intents {
+		merged := false
+		for i := range target {
+			if target[i].Name == intent.Name {
+				target[i].Examples = appendRelatedWords(append([]string(nil), target[i].Examples...), intent.Examples)
+				target[i].Srai = intent.Srai
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			target = append(target, intent)
+		}
+	}
+	return target
+}
+
+// WithIntentConfidence sets the classifier confidence needed to redirect a catch-all match to an intent
+func WithIntentConfidence(confidence float64) Option {
+	return func(g *Golem) {
+		g.SetIntentConfidence(confidence)
+	}
+}
+
+// SetIntentConfidence sets the classifier confidence (0-1) needed to redirect a catch-all match to
+// an intent's srai target (default 0.5)
+func (g *Golem) SetIntentConfidence(confidence float64) {
+	g.intentConfidence = confidence
+}
+
+// GetIntentConfidence returns the classifier confidence needed to redirect to an intent
+func (g *Golem) GetIntentConfidence() float64 {
+	if g.intentConfidence <= 0 {
+		return 0.5
+	}
+	return g.intentConfidence
+}
+
+// IntentClassifier returns the classifier trained from the loaded intents, nil when none are loaded
+func (g *Golem) IntentClassifier() *IntentClassifier {
+	kb := g.aimlKB
+	if kb == nil || len(kb.Intents) == 0 {
+		return nil
+	}
+	if c := g.intentCache; c != nil && c.kb == kb && c.intents == len(kb.Intents) {
+		return c.classifier
+	}
+	g.intentCache = &intentClassifierCache{kb: kb, intents: len(kb.Intents), classifier: NewIntentClassifier(kb.Intents)}
+	g.LogInfo("Trained intent classifier on %d intents", len(kb.Intents))
+	return g.intentCache.classifier
+}
+
+// applyIntentFallback redirects a catch-all match to the srai target of the intent the classifier
+// is confident about, through a copy of the catch-all category. The intent is recorded in metadata.
+func (g *Golem) applyIntentFallback(category *Category, wildcards map[string]string, input string, metadata *ResponseMetadata) (*Category, map[string]string) {
+	if category == nil || !isCatchAllPattern(category.Pattern) || metadata.Form != "" {
+		return category, wildcards
+	}
+	classifier := g.IntentClassifier()
+	if classifier == nil {
+		return category, wildcards
+	}
+	name, confidence := classifier.Classify(input)
+	if name == "" || confidence < g.GetIntentConfidence() {
+		return category, wildcards
+	}
+	intent, _ := classifier.Intent(name)
+	g.LogInfo("Intent %s (confidence %.2f) redirects to '%s'", name, confidence, intent.Srai)
+	metadata.Intent = name
+	metadata.IntentConfidence = confidence
+
+	redirect := *category
+	redirect.Template = "<srai>" + intent.Srai + "</srai>"
+	return &redirect, wildcards
+}
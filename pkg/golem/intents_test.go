@@ -0,0 +1,171 @@
+package golem
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const intentsTestAIML = `<aiml version="2.0">
+<category><pattern>HOW DO I FILE A CLAIM</pattern><template>Call us to file a claim.</template></category>
+<category><pattern>CANCEL MY POLICY</pattern><template>Sorry to see you go.</template></category>
+<category><pattern>WHAT IS MY PREMIUM</pattern><template>Your premium is $40 a month.</template></category>
+<category><pattern>*</pattern><template>Pardon?</template></category>
+</aiml>`
+
+const intentsTestJSON = `{
+  "file_claim": {
+    "srai": "HOW DO I FILE A CLAIM",
+    "examples": [
+      "I had a car accident",
+      "someone crashed into my car",
+      "my house was flooded",
+      "I need to report damage",
+      "a tree fell on my roof",
+      "my phone was stolen"
+    ]
+  },
+  "cancel_policy": {
+    "srai": "CANCEL MY POLICY",
+    "examples": [
+      "I want to stop my insurance",
+      "please end my coverage",
+      "I no longer need insurance",
+      "stop my coverage now",
+      "terminate my insurance contract",
+      "I want to end my insurance"
+    ]
+  },
+  "premium": {
+    "srai": "WHAT IS MY PREMIUM",
+    "examples": [
+      "how much do I pay each month",
+      "what does my insurance cost",
+      "how much is my monthly payment",
+      "what is my monthly cost",
+      "how much do I pay for coverage",
+      "what do I pay per month"
+    ]
+  }
+}`
+
+func writeIntentsBot(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeResourceFile(t, dir, "insurance.aiml", intentsTestAIML)
+	if err := os.Mkdir(filepath.Join(dir, "intents"), 0755); err != nil {
+		t.Fatalf("Failed to create intents directory: %v", err)
+	}
+	writeResourceFile(t, filepath.Join(dir, "intents"), "insurance.json", intentsTestJSON)
+	return dir
+}
+
+func TestIntentClassifier(t *testing.T) {
+	g := newTestBotFromDirectory(t, writeIntentsBot(t))
+	classifier := g.IntentClassifier()
+	if classifier == nil {
+		t.Fatal("Expected a classifier trained from intents/insurance.json")
+	}
+	if got := strings.Join(classifier.Intents(), ","); got != "cancel_policy,file_claim,premium" {
+		t.Errorf("Unexpected intents %s", got)
+	}
+
+	testCases := []struct {
+		input  string
+		intent string
+	}{
+		{"There was an accident with my car", "file_claim"},
+		{"end my insurance please", "cancel_policy"},
+		{"how much do I pay", "premium"},
+		{"zebras are striped", ""},
+	}
+	for _, tc := range testCases {
+		intent, confidence := classifier.Classify(tc.input)
+		if intent != tc.intent {
+			t.Errorf("%q: got intent %q (%.2f), expected %q", tc.input, intent, confidence, tc.intent)
+		}
+		if tc.intent == "" && confidence != 0 {
+			t.Errorf("%q: expected zero confidence, got %.2f", tc.input, confidence)
+		}
+	}
+}
+
+func TestIntentFallback(t *testing.T) {
+	g := newTestBotFromDirectory(t, writeIntentsBot(t))
+	session := g.CreateSession("intents")
+
+	response, metadata, err := g.ProcessInputWithMetadata("Someone crashed into my car yesterday", session)
+	if err != nil {
+		t.Fatalf("ProcessInputWithMetadata failed: %v", err)
+	}
+	if response != "Call us to file a claim." {
+		t.Errorf("Expected redirect to the file_claim srai, got %q", response)
+	}
+	if metadata.Intent != "file_claim" || metadata.IntentConfidence < 0.5 {
+		t.Errorf("Expected file_claim intent in metadata, got %q (%.2f)", metadata.Intent, metadata.IntentConfidence)
+	}
+
+	// Patterns still win over intents
+	response, metadata, _ = g.ProcessInputWithMetadata("cancel my policy", session)
+	if response != "Sorry to see you go." || metadata.Intent != "" {
+		t.Errorf("Expected the pattern to answer without an intent, got %q (%q)", response, metadata.Intent)
+	}
+
+	// Unrelated input reaches the catch-all category
+	if got := askN(t, g, session, "zebras are striped", 1)[0]; got != "Pardon?" {
+		t.Errorf("Expected the catch-all response, got %q", got)
+	}
+
+	// Below the confidence threshold the catch-all answers
+	g.SetIntentConfidence(0.99)
+	if got := askN(t, g, session, "Someone crashed into my car yesterday", 1)[0]; got != "Pardon?" {
+		t.Errorf("Expected no redirect below the threshold, got %q", got)
+	}
+}
+
+func TestLoadIntentsFromFileErrors(t *testing.T) {
+	g := New(false)
+	path := writeResourceFile(t, t.TempDir(), "bad.json", `{"greet": {"examples": ["hi"]}, "bye": {"srai": "BYE"}}`)
+	_, err := g.LoadIntentsFromFile(path)
+	if err == nil {
+		t.Fatal("Expected an error for intents without srai or examples")
+	}
+	for _, want := range []string{"intent greet has no srai target", "intent bye has no examples"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in error, got: %v", want, err)
+		}
+	}
+}
+
+func TestEvaluateIntents(t *testing.T) {
+	g := New(false)
+	intents, err := g.LoadIntentsFromDirectory(writeIntentsBot(t))
+	if err != nil {
+		t.Fatalf("LoadIntentsFromDirectory failed: %v", err)
+	}
+
+	evaluation := EvaluateIntents(intents, 3)
+	if evaluation.Total != 18 || evaluation.Folds != 3 {
+		t.Errorf("Expected every example held out once in 3 folds, got %d examples in %d folds", evaluation.Total, evaluation.Folds)
+	}
+	if evaluation.Correct+len(evaluation.Misses) != evaluation.Total {
+		t.Errorf("Expected each wrong prediction listed, got %d correct and %d misses of %d", evaluation.Correct, len(evaluation.Misses), evaluation.Total)
+	}
+	if evaluation.Accuracy < 0.5 {
+		t.Errorf("Expected accuracy of at least 50%%, got %.2f\n%s", evaluation.Accuracy, evaluation)
+	}
+	if result := evaluation.Intents["premium"]; result == nil || result.Total != 6 {
+		t.Errorf("Expected per-intent results, got %+v", result)
+	}
+	if report := evaluation.String(); !strings.Contains(report, "3-fold cross-validation") || !strings.Contains(report, "cancel_policy") {
+		t.Errorf("Unexpected report:\n%s", report)
+	}
+
+	if err := g.Execute("intents", []string{"eval", "--folds", "1", "bot/"}); err == nil {
+		t.Error("Expected an error for a single fold")
+	}
+	if err := g.Execute("intents", []string{"eval", "bot/", "--folds"}); err == nil || !strings.Contains(err.Error(), "--folds requires") {
+		t.Errorf("Expected an error for --folds without a count, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package golem
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const intervalTestAIML = `<aiml version="2.0">
+<category><pattern>MY BIRTHDAY IS *</pattern><template><think><set name="birthday"><star/></set></think>OK</template></category>
+<category><pattern>HOW OLD AM I</pattern><template>You are <interval><jformat>MMMM d, yyyy</jformat><style>years</style><from><get name="birthday"/></from><to><date jformat="MMMM d, yyyy"/></to></interval> years old.</template></category>
+<category><pattern>DAYS UNTIL *</pattern><template><interval><jformat>yyyy-MM-dd</jformat><style>days</style><from><date jformat="yyyy-MM-dd"/></from><to><star/></to></interval></template></category>
+<category><pattern>HOURS SINCE *</pattern><template><interval jformat="yyyy-MM-dd HH:mm" style="hours"><from><star/></from></interval></template></category>
+<category><pattern>WEEKS UNTIL *</pattern><template><interval><style>weeks</style><to><star/></to></interval></template></category>
+<category><pattern>BAD STYLE</pattern><template><interval><style>fortnights</style><from>2024-01-01</from></interval></template></category>
+</aiml>`
+
+func TestIntervalTag(t *testing.T) {
+	clock := NewFixedClock(time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC))
+	g := New(false, WithClock(clock))
+	if err := g.LoadAIMLFromString(intervalTestAIML); err != nil {
+		t.Fatalf("LoadAIMLFromString failed: %v", err)
+	}
+	session := g.CreateSession("interval")
+
+	askN(t, g, session, "my birthday is March 16, 1990", 1)
+	if got := askN(t, g, session, "how old am i", 1)[0]; got != "You are 33 years old." {
+		t.Errorf("Expected age before the birthday, got %q", got)
+	}
+	askN(t, g, session, "my birthday is March 15, 1990", 1)
+	if got := askN(t, g, session, "how old am i", 1)[0]; got != "You are 34 years old." {
+		t.Errorf("Expected age on the birthday, got %q", got)
+	}
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"days until 2024-12-25", "285"},
+		{"days until 2024-03-01", "-14"},
+		{"hours since 2024-03-14 09:30", "26"},
+		// Without a jformat the default <date> output and ISO dates are understood
+		{"weeks until 2024-04-15", "4"},
+		{"weeks until Monday, April 29, 2024", "6"},
+		{"days until 25/12/2024", "unknown"},
+		{"bad style", "unknown"},
+	}
+	for _, tc := range testCases {
+		if got := askN(t, g, session, tc.input, 1)[0]; got != tc.expected {
+			t.Errorf("%s: got %q, expected %q", tc.input, got, tc.expected)
+		}
+	}
+}
+
+func TestComputeInterval(t *testing.T) {
+	g := New(false)
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		style, from, to string
+		expected        string
+	}{
+		{"months", "2024-01-31", "2024-02-29", "0"},
+		{"months", "2024-01-31", "2024-03-31", "2"},
+		{"years", "2000-02-29", "2024-02-28", "23"},
+		{"days", "2024-03-09", "2024-03-11", "2"},
+		{"minutes", "2024-03-15", "", "720"},
+		{"seconds", "2024-03-15", "2024-03-14", "-86400"},
+		{"day", "2024-03-01", "2024-03-02", "1"},
+	}
+	for _, tc := range testCases {
+		got, err := g.computeInterval("yyyy-MM-dd", tc.style, tc.from, tc.to, now)
+		if err != nil {
+			t.Errorf("%s from %s to %s: unexpected error %v", tc.style, tc.from, tc.to, err)
+			continue
+		}
+		if got != tc.expected {
+			t.Errorf("%s from %s to %s: got %s, expected %s", tc.style, tc.from, tc.to, got, tc.expected)
+		}
+	}
+
+	if _, err := g.computeInterval("yyyy-MM-dd", "days", "March 1", "", now); err == nil {
+		t.Error("Expected an error for a date that does not match the jformat")
+	} else if want := `cannot parse <from> "March 1" with jformat "yyyy-MM-dd"`; !strings.Contains(err.Error(), want) {
+		t.Errorf("Expected error to mention %q, got %v", want, err)
+	}
+}
@@ -0,0 +1,211 @@
+package golem
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DuplicatePolicy decides which category is kept when two sources define the same pattern/that/topic key
+type DuplicatePolicy int
+
+const (
+	// DuplicatePolicyLastWins keeps the category that was loaded last (default)
+	DuplicatePolicyLastWins DuplicatePolicy = iota
+	// DuplicatePolicyFirstWins keeps the category that was loaded first
+	DuplicatePolicyFirstWins
+	// DuplicatePolicyError fails the load when a duplicate is found
+	DuplicatePolicyError
+)
+
+// String returns the policy name as accepted by ParseDuplicatePolicy
+func (p DuplicatePolicy) String() string {
+	switch p {
+	case DuplicatePolicyFirstWins:
+		return "first-wins"
+	case DuplicatePolicyError:
+		return "error"
+	default:
+		return "last-wins"
+	}
+}
+
+// ParseDuplicatePolicy parses a policy name (first-wins, last-wins or error)
+func ParseDuplicatePolicy(name string) (DuplicatePolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "last-wins", "last", "":
+		return DuplicatePolicyLastWins, nil
+	case "first-wins", "first":
+		return DuplicatePolicyFirstWins, nil
+	case "error":
+		return DuplicatePolicyError, nil
+	default:
+		return DuplicatePolicyLastWins, fmt.Errorf("unknown duplicate policy: %s (expected first-wins, last-wins or error)", name)
+	}
+}
+
+// CategoryOverride records a category that lost to another category with the same key
+type CategoryOverride struct {
+	Key         string `json:"key"`
+	Pattern     string `json:"pattern"`
+	That        string `json:"that,omitempty"`
+	Topic       string `json:"topic,omitempty"`
+	KeptFile    string `json:"kept_file"`
+	DroppedFile string `json:"dropped_file"`
+}
+
+// MergeReport describes how categories from several sources were merged into one knowledge base
+type MergeReport struct {
+	Policy    DuplicatePolicy    `json:"-"`
+	Files     []string           `json:"files"`
+	Overrides []CategoryOverride `json:"overrides"`
+}
+
+// NewMergeReport creates an empty merge report for the given policy
+func NewMergeReport(policy DuplicatePolicy) *MergeReport {
+	return &MergeReport{
+		Policy:    policy,
+		Files:     make([]string, 0),
+		Overrides: make([]CategoryOverride, 0),
+	}
+}
+
+// HasOverrides returns whether any category was overridden during the merge
+func (r *MergeReport) HasOverrides() bool {
+	return r != nil && len(r.Overrides) > 0
+}
+
+// String formats the report for display
+func (r *MergeReport) String() string {
+	if r == nil {
+		return "No merge report available\n"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Merge report (policy: %s)\n", r.Policy))
+	sb.WriteString(fmt.Sprintf("Files merged: %d\n", len(r.Files)))
+	sb.WriteString(fmt.Sprintf("Overridden categories: %d\n", len(r.Overrides)))
+	for _, override := range r.Overrides {
+		sb.WriteString(fmt.Sprintf("  %s\n", override.Key))
+		sb.WriteString(fmt.Sprintf("    kept:    %s\n", displaySourceFile(override.KeptFile)))
+		sb.WriteString(fmt.Sprintf("    dropped: %s\n", displaySourceFile(override.DroppedFile)))
+	}
+	return sb.String()
+}
+
+// displaySourceFile returns a printable name for a category source
+func displaySourceFile(file string) string {
+	if file == "" {
+		return "<string>"
+	}
+	return file
+}
+
+// CategoryKey builds the knowledge base index key for a category from its pattern, that, that index and topic
+func CategoryKey(category Category) string {
+	key := NormalizePattern(category.Pattern)
+	if category.That != "" {
+		key += "|THAT:" + NormalizePattern(category.That)
+		if category.ThatIndex != 0 {
+			key += fmt.Sprintf("|THATINDEX:%d", category.ThatIndex)
+		}
+	}
+	if category.Topic != "" {
+		key += "|TOPIC:" + strings.ToUpper(category.Topic)
+	}
+	return key
+}
+
+// SetDuplicatePolicy sets the policy used when loaded sources define the same category key
+func (g *Golem) SetDuplicatePolicy(policy DuplicatePolicy) {
+	g.duplicatePolicy = policy
+}
+
+// GetDuplicatePolicy returns the policy used when loaded sources define the same category key
+func (g *Golem) GetDuplicatePolicy() DuplicatePolicy {
+	return g.duplicatePolicy
+}
+
+// resolveDuplicateCategory applies the duplicate policy to two categories sharing a key.
+// It returns the category to keep and the one to drop, and records the decision in the report.
+func (g *Golem) resolveDuplicateCategory(key string, existing, incoming *Category, report *MergeReport) (*Category, *Category, error) {
+	var kept, dropped *Category
+	switch g.duplicatePolicy {
+	case DuplicatePolicyError:
+		return nil, nil, fmt.Errorf("duplicate category %s defined in %s and %s",
+			key, displaySourceFile(existing.SourceFile), displaySourceFile(incoming.SourceFile))
+	case DuplicatePolicyFirstWins:
+		kept, dropped = existing, incoming
+	default:
+		kept, dropped = incoming, existing
+	}
+
+	g.LogDebug("Duplicate category %s: keeping %s, dropping %s",
+		key, displaySourceFile(kept.SourceFile), displaySourceFile(dropped.SourceFile))
+
+	if report != nil {
+		report.Overrides = append(report.Overrides, CategoryOverride{
+			Key:         key,
+			Pattern:     kept.Pattern,
+			That:        kept.That,
+			Topic:       kept.Topic,
+			KeptFile:    kept.SourceFile,
+			DroppedFile: dropped.SourceFile,
+		})
+	}
+
+	return kept, dropped, nil
+}
+
+// categoryMerger accumulates categories from several sources in load order, applying the duplicate policy
+type categoryMerger struct {
+	golem      *Golem
+	categories []Category
+	index      map[string]int
+	report     *MergeReport
+}
+
+// newCategoryMerger creates a merger that records overrides in the given report
+func newCategoryMerger(golem *Golem, report *MergeReport) *categoryMerger {
+	return &categoryMerger{
+		golem:      golem,
+		categories: make([]Category, 0),
+		index:      make(map[string]int),
+		report:     report,
+	}
+}
+
+// add merges a single category
+func (m *categoryMerger) add(category Category) error {
+	key := CategoryKey(category)
+	pos, exists := m.index[key]
+	if !exists {
+		m.index[key] = len(m.categories)
+		m.categories = append(m.categories, category)
+		return nil
+	}
+
+	kept, _, err := m.golem.resolveDuplicateCategory(key, &m.categories[pos], &category, m.report)
+	if err != nil {
+		return err
+	}
+	// The winner takes the position of the first definition so load order is preserved
+	m.categories[pos] = *kept
+	return nil
+}
+
+// apply stores the merged categories in the knowledge base and rebuilds its pattern index
+func (m *categoryMerger) apply(kb *AIMLKnowledgeBase) {
+	kb.Categories = m.categories
+	kb.Patterns = make(map[string]*Category, len(m.categories))
+	for i := range kb.Categories {
+		kb.Patterns[CategoryKey(kb.Categories[i])] = &kb.Categories[i]
+	}
+}
+
+// sortOverrides orders the report overrides by key so output is stable
+func (r *MergeReport) sortOverrides() {
+	sort.SliceStable(r.Overrides, func(i, j int) bool {
+		return r.Overrides[i].Key < r.Overrides[j].Key
+	})
+}
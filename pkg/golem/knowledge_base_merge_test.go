@@ -0,0 +1,232 @@
+package golem
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeDuplicateBot writes two AIML files that both define HELLO and returns the directory
+func writeDuplicateBot(t *testing.T) (string, string, string) {
+	t.Helper()
+	tempDir := t.TempDir()
+
+	file1 := filepath.Join(tempDir, "a_greetings.aiml")
+	content1 := `<?xml version="1.0" encoding="UTF-8"?>
+<aiml version="2.0">
+    <category>
+        <pattern>HELLO</pattern>
+        <template>Hello from A</template>
+    </category>
+    <category>
+        <pattern>ONLY IN A</pattern>
+        <template>A only</template>
+    </category>
+</aiml>`
+
+	file2 := filepath.Join(tempDir, "b_greetings.aiml")
+	content2 := `<?xml version="1.0" encoding="UTF-8"?>
+<aiml version="2.0">
+    <category>
+        <pattern>HELLO</pattern>
+        <template>Hello from B</template>
+    </category>
+    <category>
+        <pattern>HELLO</pattern>
+        <that>HOW ARE YOU</that>
+        <template>Hello again from B</template>
+    </category>
+</aiml>`
+
+	if err := os.WriteFile(file1, []byte(content1), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", file1, err)
+	}
+	if err := os.WriteFile(file2, []byte(content2), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", file2, err)
+	}
+
+	return tempDir, file1, file2
+}
+
+func TestDuplicatePolicyLastWins(t *testing.T) {
+	g := New(false)
+	dir, file1, file2 := writeDuplicateBot(t)
+
+	kb, err := g.LoadAIMLFromDirectory(dir)
+	if err != nil {
+		t.Fatalf("LoadAIMLFromDirectory failed: %v", err)
+	}
+
+	if len(kb.Categories) != 3 {
+		t.Errorf("Expected 3 categories after merge, got %d", len(kb.Categories))
+	}
+
+	category := kb.Patterns["HELLO"]
+	if category == nil {
+		t.Fatal("Expected HELLO category to be indexed")
+	}
+	if category.Template != "Hello from B" {
+		t.Errorf("Expected last file to win, got template %q", category.Template)
+	}
+
+	// The that-qualified category has a different key and must not be reported
+	if !kb.MergeReport.HasOverrides() || len(kb.MergeReport.Overrides) != 1 {
+		t.Fatalf("Expected exactly one override, got %+v", kb.MergeReport)
+	}
+	override := kb.MergeReport.Overrides[0]
+	if override.Key != "HELLO" || override.KeptFile != file2 || override.DroppedFile != file1 {
+		t.Errorf("Unexpected override: %+v", override)
+	}
+	if len(kb.MergeReport.Files) != 2 {
+		t.Errorf("Expected 2 files in report, got %v", kb.MergeReport.Files)
+	}
+}
+
+func TestDuplicatePolicyFirstWins(t *testing.T) {
+	g := New(false)
+	g.SetDuplicatePolicy(DuplicatePolicyFirstWins)
+	dir, file1, file2 := writeDuplicateBot(t)
+
+	kb, err := g.LoadAIMLFromDirectory(dir)
+	if err != nil {
+		t.Fatalf("LoadAIMLFromDirectory failed: %v", err)
+	}
+
+	if got := kb.Patterns["HELLO"].Template; got != "Hello from A" {
+		t.Errorf("Expected first file to win, got template %q", got)
+	}
+	if got := kb.Patterns["HELLO"].SourceFile; got != file1 {
+		t.Errorf("Expected kept category to come from %s, got %s", file1, got)
+	}
+
+	override := kb.MergeReport.Overrides[0]
+	if override.KeptFile != file1 || override.DroppedFile != file2 {
+		t.Errorf("Unexpected override: %+v", override)
+	}
+
+	g.SetKnowledgeBase(kb)
+	session := g.CreateSession("first-wins")
+	response, err := g.ProcessInput("hello", session)
+	if err != nil {
+		t.Fatalf("ProcessInput failed: %v", err)
+	}
+	if response != "Hello from A" {
+		t.Errorf("Expected 'Hello from A', got %q", response)
+	}
+}
+
+func TestDuplicatePolicyError(t *testing.T) {
+	g := New(false)
+	g.SetDuplicatePolicy(DuplicatePolicyError)
+	dir, file1, file2 := writeDuplicateBot(t)
+
+	_, err := g.LoadAIMLFromDirectory(dir)
+	if err == nil {
+		t.Fatal("Expected duplicate category error")
+	}
+	if !strings.Contains(err.Error(), file1) || !strings.Contains(err.Error(), file2) {
+		t.Errorf("Expected error to name both files, got %v", err)
+	}
+
+	// A trailing flag without its value is not taken for a path
+	if err := g.loadCommand([]string{dir, "--duplicates"}); err == nil || !strings.Contains(err.Error(), "--duplicates requires") {
+		t.Errorf("Expected an error for --duplicates without a policy, got %v", err)
+	}
+}
+
+func TestDuplicatePolicyLoadFromString(t *testing.T) {
+	g := New(false)
+	g.SetDuplicatePolicy(DuplicatePolicyFirstWins)
+
+	if err := g.LoadAIMLFromString(`<aiml version="2.0"><category><pattern>HI</pattern><template>one</template></category></aiml>`); err != nil {
+		t.Fatalf("LoadAIMLFromString failed: %v", err)
+	}
+	if err := g.LoadAIMLFromString(`<aiml version="2.0"><category><pattern>HI</pattern><template>two</template></category></aiml>`); err != nil {
+		t.Fatalf("LoadAIMLFromString failed: %v", err)
+	}
+
+	kb := g.GetKnowledgeBase()
+	if len(kb.Categories) != 1 {
+		t.Errorf("Expected dropped category to be removed, got %d categories", len(kb.Categories))
+	}
+	if got := kb.Patterns["HI"].Template; got != "one" {
+		t.Errorf("Expected first definition to win, got %q", got)
+	}
+	if !kb.MergeReport.HasOverrides() {
+		t.Error("Expected merge report to record the override")
+	}
+}
+
+func TestDuplicatePolicyLastWinsAcrossLoads(t *testing.T) {
+	g := New(false)
+	for _, template := range []string{"one", "two", "three"} {
+		aiml := `<aiml version="2.0"><category><pattern>HELLO</pattern><template>` + template + `</template></category></aiml>`
+		if err := g.LoadAIMLFromString(aiml); err != nil {
+			t.Fatalf("LoadAIMLFromString failed: %v", err)
+		}
+	}
+
+	kb := g.GetKnowledgeBase()
+	if len(kb.Categories) != 1 || kb.Categories[0].Template != "three" {
+		t.Errorf("Expected only the last definition to remain, got %+v", kb.Categories)
+	}
+	if got := kb.Patterns["HELLO"]; got != &kb.Categories[0] {
+		t.Errorf("Expected the index to point into the merged categories, got %+v", got)
+	}
+	if len(kb.MergeReport.Overrides) != 2 {
+		t.Errorf("Expected both overrides to be reported, got %+v", kb.MergeReport.Overrides)
+	}
+}
+
+func TestMergeKnowledgeBasesKeepsBothReports(t *testing.T) {
+	g := New(false)
+	dir1, _, _ := writeDuplicateBot(t)
+	dir2, file3, file4 := writeDuplicateBot(t)
+	kb1, err := g.LoadAIMLFromDirectory(dir1)
+	if err != nil {
+		t.Fatalf("LoadAIMLFromDirectory failed: %v", err)
+	}
+	kb2, err := g.LoadAIMLFromDirectory(dir2)
+	if err != nil {
+		t.Fatalf("LoadAIMLFromDirectory failed: %v", err)
+	}
+
+	merged, err := g.mergeKnowledgeBases(kb1, kb2)
+	if err != nil {
+		t.Fatalf("mergeKnowledgeBases failed: %v", err)
+	}
+	report := merged.MergeReport
+	if len(report.Files) != 4 || report.Files[2] != file3 || report.Files[3] != file4 {
+		t.Errorf("Expected the files of both knowledge bases, got %v", report.Files)
+	}
+	// One override inside each directory, then the three categories the second directory redefines
+	if len(report.Overrides) != 5 || report.Overrides[1].KeptFile != file4 || report.Overrides[1].DroppedFile != file3 {
+		t.Errorf("Expected the overrides of both knowledge bases and the merge, got %+v", report.Overrides)
+	}
+}
+
+func TestParseDuplicatePolicy(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected DuplicatePolicy
+		wantErr  bool
+	}{
+		{"first-wins", DuplicatePolicyFirstWins, false},
+		{"LAST", DuplicatePolicyLastWins, false},
+		{"error", DuplicatePolicyError, false},
+		{"", DuplicatePolicyLastWins, false},
+		{"random", DuplicatePolicyLastWins, true},
+	}
+
+	for _, tt := range tests {
+		policy, err := ParseDuplicatePolicy(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDuplicatePolicy(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if policy != tt.expected {
+			t.Errorf("ParseDuplicatePolicy(%q) = %s, want %s", tt.input, policy, tt.expected)
+		}
+	}
+}
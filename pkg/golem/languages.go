@@ -0,0 +1,592 @@
+package golem
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// LanguagePredicate is the session predicate that pins a multilingual bot to one language
+const LanguagePredicate = "language"
+
+// languageSwitchConfidence is the detector confidence needed to leave the session's current language,
+// so short replies such as "ok" or "no" stay in the language of the conversation
+const languageSwitchConfidence = 0.8
+
+// languageCodeRegex matches language codes used as directory names and file name suffixes (en, es, pt-br)
+var languageCodeRegex = regexp.MustCompile(`^[a-z]{2}(-[a-z]{2,4})?$`)
+
+// normalizeLanguageCode lowercases a language code and writes regions with a hyphen (pt_BR -> pt-br)
+func normalizeLanguageCode(code string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(code)), "_", "-")
+}
+
+// isLanguageCode reports whether name is a language code
+func isLanguageCode(name string) bool {
+	return languageCodeRegex.MatchString(normalizeLanguageCode(name))
+}
+
+// languageProfile counts the character trigrams of a language's sample text
+type languageProfile struct {
+	counts map[string]int
+	total  int
+}
+
+// LanguageDetector identifies the language of a text from character trigram profiles.
+// It works offline: profiles come from built-in samples and from text added with AddSample.
+type LanguageDetector struct {
+	profiles map[string]*languageProfile
+	mutex    sync.RWMutex
+}
+
+// NewLanguageDetector creates a detector with the built-in profiles (en, es, de, fr, it, pt, nl)
+func NewLanguageDetector() *LanguageDetector {
+	d := &LanguageDetector{profiles: make(map[string]*languageProfile)}
+	for language, sample := range languageSamples {
+		d.AddSample(language, sample)
+	}
+	return d
+}
+
+// AddSample adds text written in a language to its profile, creating the profile if needed
+func (d *LanguageDetector) AddSample(language, text string) {
+	language = normalizeLanguageCode(language)
+	trigrams := textTrigrams(text)
+	if language == "" || len(trigrams) == 0 {
+		return
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	profile := d.profiles[language]
+	if profile == nil {
+		profile = &languageProfile{counts: make(map[string]int)}
+		d.profiles[language] = profile
+	}
+	for _, trigram := range trigrams {
+		profile.counts[trigram]++
+		profile.total++
+	}
+}
+
+// Languages returns the languages with a profile, sorted
+func (d *LanguageDetector) Languages() []string {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	languages := make([]string, 0, len(d.profiles))
+	for language := range d.profiles {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Detect returns the most likely language of text and a confidence between 0 and 1.
+// With candidates, only those languages are considered. The language is empty when text has no letters
+// or no candidate has a profile.
+func (d *LanguageDetector) Detect(text string, candidates ...string) (string, float64) {
+	trigrams := textTrigrams(text)
+	if len(trigrams) == 0 {
+		return "", 0
+	}
+	if len(candidates) == 0 {
+		candidates = d.Languages()
+	}
+
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	// Naive Bayes over trigrams with add-half smoothing
+	const alpha = 0.5
+	const vocabulary = 20000
+	scores := make(map[string]float64, len(candidates))
+	best := ""
+	for _, language := range candidates {
+		language = normalizeLanguageCode(language)
+		profile := d.profiles[language]
+		if profile == nil {
+			continue
+		}
+		score := 0.0
+		denominator := float64(profile.total) + alpha*vocabulary
+		for _, trigram := range trigrams {
+			score += math.Log((float64(profile.counts[trigram]) + alpha) / denominator)
+		}
+		scores[language] = score
+		if best == "" || score > scores[best] || (score == scores[best] && language < best) {
+			best = language
+		}
+	}
+	if best == "" {
+		return "", 0
+	}
+
+	// Confidence is the posterior of the best language among the candidates
+	sum := 0.0
+	for _, score := range scores {
+		sum += math.Exp(score - scores[best])
+	}
+	return best, 1 / sum
+}
+
+// textTrigrams returns the character trigrams of the lowercased words of text, each word padded with spaces
+func textTrigrams(text string) []string {
+	var trigrams []string
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		runes := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			trigrams = append(trigrams, string(runes[i:i+3]))
+		}
+	}
+	return trigrams
+}
+
+// WithLanguage sets the language of the bot's knowledge base, which selects the built-in
+// pronoun tables for <person>, <person2> and <gender>
+func WithLanguage(language string) Option {
+	return func(g *Golem) {
+		g.SetLanguage(language)
+	}
+}
+
+// SetLanguage sets the language of the bot's knowledge base
+func (g *Golem) SetLanguage(language string) {
+	g.language = normalizeLanguageCode(language)
+}
+
+// GetLanguage returns the language of the bot's knowledge base, empty when unset (English tables)
+func (g *Golem) GetLanguage() string {
+	return g.language
+}
+
+// GetLanguageDetector returns the detector that routes input to a language's knowledge base
+func (g *Golem) GetLanguageDetector() *LanguageDetector {
+	if g.languageDetector == nil {
+		g.languageDetector = NewLanguageDetector()
+	}
+	return g.languageDetector
+}
+
+// Languages returns the languages with a knowledge base, sorted; empty for a single knowledge base
+func (g *Golem) Languages() []string {
+	languages := make([]string, 0, len(g.languageBots))
+	for language := range g.languageBots {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// LanguageKnowledgeBase returns the knowledge base of a language, or nil when it is not loaded
+func (g *Golem) LanguageKnowledgeBase(language string) *AIMLKnowledgeBase {
+	if bot := g.languageBots[normalizeLanguageCode(language)]; bot != nil {
+		return bot.aimlKB
+	}
+	return nil
+}
+
+// SetDefaultLanguage sets the language used when input has no letters to detect.
+// Set it before LoadLanguageKnowledgeBases; the default is "en" when loaded, else the first language.
+func (g *Golem) SetDefaultLanguage(language string) {
+	g.defaultLanguage = normalizeLanguageCode(language)
+}
+
+// GetDefaultLanguage returns the default language
+func (g *Golem) GetDefaultLanguage() string {
+	return g.defaultLanguage
+}
+
+// LoadLanguageKnowledgeBases loads one knowledge base per language from a bot directory.
+// Each subdirectory named by a language code (en, es, de) is loaded with its own sets, maps,
+// properties and substitutions, and AIML files with a language suffix (greetings.es.aiml) are added
+// to that language. The default language's knowledge base becomes the bot's knowledge base, and
+// ProcessInput routes every input to the knowledge base of its detected language.
+func (g *Golem) LoadLanguageKnowledgeBases(dirPath string) error {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return fmt.Errorf("failed to read directory %s: %v", dirPath, err)
+	}
+
+	dirs := make(map[string]string)
+	files := make(map[string][]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			if isLanguageCode(name) {
+				dirs[normalizeLanguageCode(name)] = filepath.Join(dirPath, name)
+			}
+			continue
+		}
+		if !strings.HasSuffix(strings.ToLower(name), ".aiml") {
+			continue
+		}
+		suffix := strings.TrimPrefix(filepath.Ext(name[:len(name)-len(".aiml")]), ".")
+		if isLanguageCode(suffix) {
+			language := normalizeLanguageCode(suffix)
+			files[language] = append(files[language], filepath.Join(dirPath, name))
+		}
+	}
+
+	var languages []string
+	for language := range dirs {
+		languages = append(languages, language)
+	}
+	for language := range files {
+		if _, exists := dirs[language]; !exists {
+			languages = append(languages, language)
+		}
+	}
+	if len(languages) == 0 {
+		return fmt.Errorf("no language directories or language-suffixed AIML files found in %s", dirPath)
+	}
+	sort.Strings(languages)
+
+	defaultLanguage := g.defaultLanguage
+	if !containsString(languages, defaultLanguage) {
+		defaultLanguage = languages[0]
+		if containsString(languages, "en") {
+			defaultLanguage = "en"
+		}
+	}
+
+	// This bot serves the default language; the other languages get bots that share its settings
+	detector := g.GetLanguageDetector()
+	bots := make(map[string]*Golem, len(languages))
+	kbs := make(map[string]*AIMLKnowledgeBase, len(languages))
+	for _, language := range languages {
+		bot := g
+		if language != defaultLanguage {
+			bot = g.newLanguageBot(language)
+		}
+		kb, err := bot.loadLanguageKnowledgeBase(dirs[language], files[language])
+		if err != nil {
+			return fmt.Errorf("failed to load %s knowledge base: %v", language, err)
+		}
+		bots[language] = bot
+		kbs[language] = kb
+		detector.AddSample(language, knowledgeBaseText(kb))
+		g.LogInfo("Loaded %s knowledge base with %d categories", language, len(kb.Categories))
+	}
+
+	for _, language := range languages {
+		if language != defaultLanguage {
+			bots[language].SetKnowledgeBase(kbs[language])
+		}
+	}
+	g.language = defaultLanguage
+	g.defaultLanguage = defaultLanguage
+	g.languageBots = bots
+	g.languageSynced = nil
+	g.SetKnowledgeBase(kbs[defaultLanguage])
+	return nil
+}
+
+// newLanguageBot creates the bot for one language, sharing this bot's services and settings
+func (g *Golem) newLanguageBot(language string) *Golem {
+	bot := New(g.verbose)
+	bot.oobMgr = g.oobMgr
+	// HTTP services are shared; local services such as "faq" answer from the language's own knowledge base
+	bot.sraixMgr = g.sraixMgr.withLocalServices()
+	bot.sraixMgr.RegisterLocalService("faq", bot.faqService)
+	bot.applyLanguageSettings(g.currentLanguageSettings())
+	bot.language = language
+	return bot
+}
+
+// languageSettings are the settings the bots of other languages take from the bot that loaded them
+type languageSettings struct {
+	logLevel             LogLevel
+	coverage             *coverageTracker
+	providers            *providerRegistry
+	duplicatePolicy      DuplicatePolicy
+	clock                Clock
+	randomSource         *rand.Rand
+	unicodeNormalization UnicodeNormalization
+	tokenizer            Tokenizer
+	spellingCorrection   *SpellingCorrection
+	semanticResourceMode SemanticResourceMode
+	synonymExpansion     bool
+	fuzzyFallback        *FuzzyFallback
+	intentConfidence     float64
+	faqFallback          *FAQFallback
+	sessionStore         SessionStore
+	useTreeProcessing    bool
+}
+
+// currentLanguageSettings returns this bot's settings for the bots of other languages
+func (g *Golem) currentLanguageSettings() languageSettings {
+	return languageSettings{
+		logLevel:             g.logLevel,
+		coverage:             g.coverage,
+		providers:            g.providers,
+		duplicatePolicy:      g.duplicatePolicy,
+		clock:                g.clock,
+		randomSource:         g.randomSource,
+		unicodeNormalization: g.unicodeNormalization,
+		tokenizer:            g.tokenizer,
+		spellingCorrection:   g.spellingCorrection,
+		semanticResourceMode: g.semanticResourceMode,
+		synonymExpansion:     g.synonymExpansion,
+		fuzzyFallback:        g.fuzzyFallback,
+		intentConfidence:     g.intentConfidence,
+		faqFallback:          g.faqFallback,
+		sessionStore:         g.sessionStore,
+		useTreeProcessing:    g.useTreeProcessing,
+	}
+}
+
+// applyLanguageSettings gives a language bot the settings of the bot that loaded it
+func (bot *Golem) applyLanguageSettings(settings languageSettings) {
+	bot.logLevel = settings.logLevel
+	bot.coverage = settings.coverage
+	bot.providers = settings.providers
+	bot.duplicatePolicy = settings.duplicatePolicy
+	bot.clock = settings.clock
+	bot.randomSource = settings.randomSource
+	bot.unicodeNormalization = settings.unicodeNormalization
+	bot.SetTokenizer(settings.tokenizer)
+	bot.spellingCorrection = settings.spellingCorrection
+	bot.semanticResourceMode = settings.semanticResourceMode
+	bot.synonymExpansion = settings.synonymExpansion
+	bot.fuzzyFallback = settings.fuzzyFallback
+	bot.intentConfidence = settings.intentConfidence
+	bot.faqFallback = settings.faqFallback
+	bot.sessionStore = settings.sessionStore
+	bot.useTreeProcessing = settings.useTreeProcessing
+}
+
+// same reports whether two settings are equal. Clocks, tokenizers and session stores of types
+// that can't be compared count as changed, so they are passed on every time.
+func (settings languageSettings) same(other languageSettings) bool {
+	for _, value := range []interface{}{settings.clock, settings.tokenizer, settings.sessionStore} {
+		if value != nil && !reflect.TypeOf(value).Comparable() {
+			return false
+		}
+	}
+	return settings == other
+}
+
+// syncLanguageBots passes setting changes made since the last call on to the bots of the other
+// languages. It is the one place settings reach them, so setters only change this bot.
+func (g *Golem) syncLanguageBots() {
+	if len(g.languageBots) == 0 {
+		return
+	}
+	settings := g.currentLanguageSettings()
+	g.languageMutex.Lock()
+	defer g.languageMutex.Unlock()
+	if g.languageSynced != nil && g.languageSynced.same(settings) {
+		return
+	}
+	g.eachLanguageBot(func(bot *Golem) { bot.applyLanguageSettings(settings) })
+	g.languageSynced = &settings
+}
+
+// eachLanguageBot calls apply with the bots of the other languages
+func (g *Golem) eachLanguageBot(apply func(bot *Golem)) {
+	for _, bot := range g.languageBots {
+		if bot != g {
+			apply(bot)
+		}
+	}
+}
+
+// loadLanguageKnowledgeBase loads a language directory (if any) and adds the language's suffixed AIML files
+func (g *Golem) loadLanguageKnowledgeBase(dir string, files []string) (*AIMLKnowledgeBase, error) {
+	var kb *AIMLKnowledgeBase
+	if dir != "" {
+		loaded, err := g.LoadAIMLFromDirectory(dir)
+		if err != nil && !strings.Contains(err.Error(), "no AIML files found") {
+			return nil, err
+		}
+		kb = loaded
+	}
+	if kb == nil {
+		kb = NewAIMLKnowledgeBase()
+		if err := g.loadDefaultProperties(kb); err != nil {
+			return nil, fmt.Errorf("failed to load default properties: %v", err)
+		}
+	}
+
+	sort.Strings(files)
+	for _, file := range files {
+		fileKB, err := g.LoadAIML(file)
+		if err != nil {
+			return nil, err
+		}
+		// Default properties are already in kb and must not override the directory's properties
+		fileKB.Properties = make(map[string]string)
+		kb, err = g.mergeKnowledgeBases(kb, fileKB)
+		if err != nil {
+			return nil, err
+		}
+		kb.MergeReport.Files = append(kb.MergeReport.Files, file)
+	}
+	return kb, nil
+}
+
+// templateMarkupRegex finds the tags of a template
+var templateMarkupRegex = regexp.MustCompile(`<[^>]*>`)
+
+// knowledgeBaseText returns the words of a knowledge base's patterns and templates, a sample of its language
+func knowledgeBaseText(kb *AIMLKnowledgeBase) string {
+	var text strings.Builder
+	for _, category := range kb.Categories {
+		text.WriteString(templateMarkupRegex.ReplaceAllString(category.Pattern, " "))
+		text.WriteByte(' ')
+		text.WriteString(templateMarkupRegex.ReplaceAllString(category.Template, " "))
+		text.WriteByte(' ')
+	}
+	return text.String()
+}
+
+// languageBot picks the bot that answers input and records its language in the session.
+// It returns g itself for a bot with a single knowledge base.
+func (g *Golem) languageBot(input string, session *ChatSession) *Golem {
+	if len(g.languageBots) == 0 {
+		return g
+	}
+	language := g.routeLanguage(input, session)
+	if session != nil {
+		session.Language = language
+	}
+	g.syncLanguageBots()
+	return g.languageBots[language]
+}
+
+// routeLanguage chooses the language of input: the session's language predicate when it names a
+// loaded language, else the detected language. Input the detector is unsure about stays in the
+// session's current language.
+func (g *Golem) routeLanguage(input string, session *ChatSession) string {
+	current := ""
+	if session != nil {
+		if pinned := normalizeLanguageCode(session.Variables[LanguagePredicate]); g.languageBots[pinned] != nil {
+			return pinned
+		}
+		if g.languageBots[session.Language] != nil {
+			current = session.Language
+		}
+	}
+
+	language, confidence := g.GetLanguageDetector().Detect(input, g.Languages()...)
+	switch {
+	case language == "" && current != "":
+		return current
+	case language == "":
+		return g.defaultLanguage
+	case confidence < languageSwitchConfidence && current != "":
+		return current
+	}
+	return language
+}
+
+// builtinPronounTable returns the built-in <person>, <person2> or <gender> table of a language,
+// nil for English, which uses the rule-based substitution
+func builtinPronounTable(language, name string) map[string]string {
+	if tables := builtinPronounTables[normalizeLanguageCode(language)]; tables != nil {
+		return tables[name]
+	}
+	if i := strings.IndexByte(language, '-'); i > 0 {
+		return builtinPronounTable(language[:i], name)
+	}
+	return nil
+}
+
+// builtinPronounTables holds pronoun swaps for languages other than English, keyed by language and substitution name
+var builtinPronounTables = map[string]map[string]map[string]string{
+	"es": {
+		SubstitutionPerson: {
+			"yo": "tú", "tú": "yo", "me": "te", "te": "me", "mi": "tu", "tu": "mi", "mis": "tus", "tus": "mis",
+			"mío": "tuyo", "tuyo": "mío", "mía": "tuya", "tuya": "mía", "míos": "tuyos", "tuyos": "míos",
+			"mías": "tuyas", "tuyas": "mías", "conmigo": "contigo", "contigo": "conmigo",
+			"yo soy": "tú eres", "tú eres": "yo soy", "yo estoy": "tú estás", "tú estás": "yo estoy",
+			"soy": "eres", "eres": "soy", "estoy": "estás", "estás": "estoy", "tengo": "tienes", "tienes": "tengo",
+		},
+		SubstitutionPerson2: {
+			"yo": "él", "él": "yo", "mi": "su", "mis": "sus", "mío": "suyo", "mía": "suya",
+			"conmigo": "con él", "soy": "es", "estoy": "está", "tengo": "tiene",
+		},
+		SubstitutionGender: {
+			"él": "ella", "ella": "él", "ellos": "ellas", "ellas": "ellos",
+		},
+	},
+	"de": {
+		SubstitutionPerson: {
+			"ich": "du", "du": "ich", "mich": "dich", "dich": "mich", "mir": "dir", "dir": "mir",
+			"mein": "dein", "dein": "mein", "meine": "deine", "deine": "meine", "meinen": "deinen", "deinen": "meinen",
+			"meinem": "deinem", "deinem": "meinem", "meiner": "deiner", "deiner": "meiner",
+			"ich bin": "du bist", "du bist": "ich bin", "ich habe": "du hast", "du hast": "ich habe",
+			"bin": "bist", "bist": "bin",
+		},
+		SubstitutionPerson2: {
+			"ich": "er", "er": "ich", "mich": "ihn", "ihn": "mich", "mir": "ihm", "ihm": "mir",
+			"mein": "sein", "meine": "seine", "meinen": "seinen", "meinem": "seinem", "meiner": "seiner",
+			"ich bin": "er ist", "ich habe": "er hat",
+		},
+		SubstitutionGender: {
+			"er": "sie", "sie": "er", "ihn": "sie", "ihm": "ihr", "ihr": "ihm",
+			"sein": "ihr", "seine": "ihre", "ihre": "seine", "seinen": "ihren", "ihren": "seinen",
+		},
+	},
+}
+
+// languageSamples are the texts behind the built-in detector profiles
+var languageSamples = map[string]string{
+	"en": `hello hi good morning good afternoon good evening good night how are you how is it going what is your name
+my name is i am fine thank you very much please yes no where do you live what time is it i would like to know
+who are you can you help me the weather is nice today i like to read books and listen to music what do you think
+about that tell me something about yourself this is a test of the system we have been waiting for the train
+there are many people in the city and they work every day she said that he would come with them later because
+it was raining which one do you want i don't know why not goodbye see you later what does it cost how much
+i need an answer for tomorrow the dog and the cat are in the house of their parents`,
+	"es": `hola buenos días buenas tardes buenas noches cómo estás qué tal cómo te llamas me llamo soy estoy bien
+gracias muchas gracias por favor sí no dónde vives qué hora es quisiera saber quién eres puedes ayudarme hace
+buen tiempo hoy me gusta leer libros y escuchar música qué piensas de eso cuéntame algo sobre ti esto es una
+prueba del sistema estamos esperando el tren hay mucha gente en la ciudad y trabajan todos los días ella dijo que
+él vendría con ellos más tarde porque estaba lloviendo cuál quieres no sé por qué no adiós hasta luego cuánto
+cuesta necesito una respuesta para mañana el perro y el gato están en la casa de sus padres`,
+	"de": `hallo guten morgen guten tag guten abend gute nacht wie geht es dir wie heißt du ich heiße ich bin mir
+geht es gut danke vielen dank bitte ja nein wo wohnst du wie spät ist es ich möchte wissen wer bist du kannst du
+mir helfen das wetter ist heute schön ich lese gern bücher und höre musik was denkst du darüber erzähl mir etwas
+über dich das ist ein test des systems wir warten auf den zug es gibt viele leute in der stadt und sie arbeiten
+jeden tag sie sagte dass er später mit ihnen kommen würde weil es regnete welches willst du ich weiß nicht warum
+nicht tschüss auf wiedersehen bis später was kostet das ich brauche eine antwort für morgen der hund und die
+katze sind im haus ihrer eltern`,
+	"fr": `bonjour salut bonsoir bonne nuit comment allez vous comment ça va comment tu t'appelles je m'appelle
+je suis ça va bien merci merci beaucoup s'il vous plaît oui non où habites tu quelle heure est il je voudrais
+savoir qui es tu peux tu m'aider il fait beau aujourd'hui j'aime lire des livres et écouter de la musique qu'est
+ce que tu en penses parle moi de toi c'est un test du système nous attendons le train il y a beaucoup de gens
+dans la ville et ils travaillent tous les jours elle a dit qu'il viendrait avec eux plus tard parce qu'il pleuvait
+lequel veux tu je ne sais pas pourquoi pas au revoir à bientôt combien ça coûte j'ai besoin d'une réponse pour
+demain le chien et le chat sont dans la maison de leurs parents`,
+	"it": `ciao buongiorno buon pomeriggio buonasera buonanotte come stai come ti chiami mi chiamo sono sto bene
+grazie grazie mille per favore sì no dove abiti che ore sono vorrei sapere chi sei puoi aiutarmi oggi fa bel
+tempo mi piace leggere libri e ascoltare musica cosa ne pensi parlami di te questo è un test del sistema stiamo
+aspettando il treno ci sono molte persone in città e lavorano ogni giorno lei ha detto che lui sarebbe venuto con
+loro più tardi perché pioveva quale vuoi non lo so perché no arrivederci a presto quanto costa ho bisogno di una
+risposta per domani il cane e il gatto sono nella casa dei loro genitori`,
+	"pt": `olá oi bom dia boa tarde boa noite como vai você como você se chama meu nome é eu sou estou bem obrigado
+muito obrigada por favor sim não onde você mora que horas são eu gostaria de saber quem é você pode me ajudar
+hoje o tempo está bom eu gosto de ler livros e ouvir música o que você acha disso me conte algo sobre você isto é
+um teste do sistema estamos esperando o trem há muitas pessoas na cidade e elas trabalham todos os dias ela disse
+que ele viria com eles mais tarde porque estava chovendo qual você quer não sei por que não tchau até logo quanto
+custa preciso de uma resposta para amanhã o cachorro e o gato estão na casa dos seus pais`,
+	"nl": `hallo hoi goedemorgen goedemiddag goedenavond welterusten hoe gaat het met je hoe heet je ik heet ik ben
+het gaat goed dank je wel bedankt alsjeblieft ja nee waar woon je hoe laat is het ik wil graag weten wie ben jij
+kun je me helpen het is mooi weer vandaag ik lees graag boeken en luister naar muziek wat vind jij daarvan vertel
+me iets over jezelf dit is een test van het systeem we wachten op de trein er zijn veel mensen in de stad en ze
+werken elke dag ze zei dat hij later met hen zou komen omdat het regende welke wil je ik weet het niet waarom
+niet dag tot ziens tot later wat kost dat ik heb een antwoord nodig voor morgen de hond en de kat zijn in het huis
+van hun ouders`,
+}
@@ -0,0 +1,187 @@
+package golem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeLanguageBot writes a bot directory with en/ and es/ subdirectories and a German suffixed file
+func writeLanguageBot(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	aiml := func(categories string) string {
+		return `<?xml version="1.0" encoding="UTF-8"?>
+<aiml version="2.0">
+` + categories + `
+</aiml>`
+	}
+	for _, sub := range []string{"en", "es"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", sub, err)
+		}
+	}
+	writeResourceFile(t, filepath.Join(dir, "en"), "bot.aiml", aiml(`<category><pattern>HELLO *</pattern><template>Hello!</template></category>
+<category><pattern>I LIKE *</pattern><template>You said <person>i like <star/></person>.</template></category>
+<category><pattern>*</pattern><template>I do not understand.</template></category>`))
+	writeResourceFile(t, filepath.Join(dir, "es"), "bot.aiml", aiml(`<category><pattern>HOLA *</pattern><template>¡Hola!</template></category>
+<category><pattern>YO TENGO *</pattern><template>Dices que <person>yo tengo <star/></person>.</template></category>
+<category><pattern>*</pattern><template>No entiendo.</template></category>`))
+	writeResourceFile(t, filepath.Join(dir, "es"), "greetings.map", "bye:adiós\n")
+	writeResourceFile(t, dir, "bot.de.aiml", aiml(`<category><pattern>GUTEN *</pattern><template>Guten Tag!</template></category>
+<category><pattern>ICH HABE *</pattern><template>Du sagst <person>ich habe <star/></person>.</template></category>
+<category><pattern>*</pattern><template>Das verstehe ich nicht.</template></category>`))
+	return dir
+}
+
+func TestLanguageDetector(t *testing.T) {
+	detector := NewLanguageDetector()
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"hello, how are you today?", "en"},
+		{"what time is it", "en"},
+		{"hola, ¿cómo estás?", "es"},
+		{"quiero saber qué hora es", "es"},
+		{"guten Morgen, wie geht es dir?", "de"},
+		{"ich möchte wissen, wie spät es ist", "de"},
+	}
+	for _, tc := range testCases {
+		if got, _ := detector.Detect(tc.input, "en", "es", "de"); got != tc.expected {
+			t.Errorf("Detect(%q) = %q, expected %q", tc.input, got, tc.expected)
+		}
+	}
+
+	if got, confidence := detector.Detect("12345 !!!"); got != "" || confidence != 0 {
+		t.Errorf("Expected no language for text without letters, got %q (%.2f)", got, confidence)
+	}
+
+	// Custom languages are learned from samples
+	detector.AddSample("xx", "zzq qzz zqz zzq qzz")
+	if got, _ := detector.Detect("zzq zqz"); got != "xx" {
+		t.Errorf("Expected custom profile to win, got %q", got)
+	}
+}
+
+func TestLoadLanguageKnowledgeBases(t *testing.T) {
+	g := New(false)
+	if err := g.LoadLanguageKnowledgeBases(writeLanguageBot(t)); err != nil {
+		t.Fatalf("LoadLanguageKnowledgeBases failed: %v", err)
+	}
+
+	if languages := g.Languages(); len(languages) != 3 || languages[0] != "de" || languages[1] != "en" || languages[2] != "es" {
+		t.Fatalf("Expected de, en and es, got %v", languages)
+	}
+	if g.GetDefaultLanguage() != "en" || g.GetKnowledgeBase() != g.LanguageKnowledgeBase("en") {
+		t.Errorf("Expected en to be the default knowledge base")
+	}
+	if g.LanguageKnowledgeBase("es").Maps["greetings"]["bye"] != "adiós" {
+		t.Errorf("Expected the es directory's map in the es knowledge base")
+	}
+	if len(g.LanguageKnowledgeBase("de").Categories) != 3 {
+		t.Errorf("Expected the suffixed file in the de knowledge base, got %d categories", len(g.LanguageKnowledgeBase("de").Categories))
+	}
+
+	if err := New(false).LoadLanguageKnowledgeBases(t.TempDir()); err == nil {
+		t.Error("Expected an error for a directory without languages")
+	}
+}
+
+func TestLanguageRouting(t *testing.T) {
+	g := New(false)
+	if err := g.LoadLanguageKnowledgeBases(writeLanguageBot(t)); err != nil {
+		t.Fatalf("LoadLanguageKnowledgeBases failed: %v", err)
+	}
+	session := g.CreateSession("multilingual")
+
+	testCases := []struct {
+		input    string
+		expected string
+		language string
+	}{
+		{"hello there, how are you", "Hello!", "en"},
+		{"hola, ¿cómo estás?", "¡Hola!", "es"},
+		// Short input the detector is unsure about stays in the conversation's language
+		{"ok", "No entiendo.", "es"},
+		{"guten Morgen, wie geht es dir", "Guten Tag!", "de"},
+		// Each language has its own pronoun table
+		{"ich habe meine Katze gefunden", "Du sagst du hast deine Katze gefunden.", "de"},
+		{"yo tengo mi libro aquí", "Dices que tú tienes tu libro aquí.", "es"},
+		{"I like my new house", "You said you like your new house.", "en"},
+	}
+	for _, tc := range testCases {
+		if got := askN(t, g, session, tc.input, 1)[0]; got != tc.expected {
+			t.Errorf("%q: got %q, expected %q", tc.input, got, tc.expected)
+		}
+		if session.Language != tc.language {
+			t.Errorf("%q: routed to %q, expected %q", tc.input, session.Language, tc.language)
+		}
+	}
+
+	// The language predicate pins the knowledge base
+	session.Variables[LanguagePredicate] = "ES"
+	if got := askN(t, g, session, "hello there, how are you", 1)[0]; got != "No entiendo." {
+		t.Errorf("Expected pinned Spanish answer, got %q", got)
+	}
+}
+
+func TestWithLanguagePronounTables(t *testing.T) {
+	g := New(false, WithLanguage("de"))
+	if got := g.SubstitutePronouns("ich bin in meinem Haus"); got != "du bist in deinem Haus" {
+		t.Errorf("Unexpected German person substitution %q", got)
+	}
+	if got := g.SubstituteGenderPronouns("er gab ihr sein Buch"); got != "sie gab ihm ihr Buch" {
+		t.Errorf("Unexpected German gender substitution %q", got)
+	}
+
+	g.SetLanguage("es-MX")
+	if got := g.SubstitutePronouns2("yo perdí mi libro"); got != "él perdí su libro" {
+		t.Errorf("Expected the es table for es-mx, got %q", got)
+	}
+}
+
+func TestLanguageBotsFollowSettings(t *testing.T) {
+	dir := writeLanguageBot(t)
+	writeResourceFile(t, filepath.Join(dir, "es"), "faq.aiml", `<aiml version="2.0">
+<category><pattern>BUSCA *</pattern><template><sraix service="faq" default="Nada."><star/></sraix></template></category>
+</aiml>`)
+	if err := os.Mkdir(filepath.Join(dir, "es", "faq"), 0755); err != nil {
+		t.Fatalf("Failed to create faq directory: %v", err)
+	}
+	writeResourceFile(t, filepath.Join(dir, "es", "faq"), "horario.md", "## ¿Cuándo abren?\n\nAbrimos a las nueve de la mañana.\n")
+
+	g := New(false)
+	if err := g.LoadLanguageKnowledgeBases(dir); err != nil {
+		t.Fatalf("LoadLanguageKnowledgeBases failed: %v", err)
+	}
+
+	// Settings changed after loading reach every language
+	clock := NewFixedClock(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+	g.SetClock(clock)
+	g.EnableCoverage()
+	g.SetFAQFallback(&FAQFallback{})
+
+	// The faq service searches the knowledge base of the session's language
+	session := g.CreateSession("faq-es")
+	if got := askN(t, g, session, "busca cuándo abren por la mañana", 1)[0]; got != "Abrimos a las nueve de la mañana." {
+		t.Errorf("Expected the Spanish FAQ passage, got %q", got)
+	}
+	es := g.languageBots["es"]
+	if es.GetClock() != clock || es.GetFAQFallback() == nil || es.coverage != g.coverage {
+		t.Errorf("Expected the es bot to follow the settings, got clock %v, FAQ %v", es.GetClock(), es.GetFAQFallback())
+	}
+	g.SetClock(nil)
+	askN(t, g, session, "busca cuándo abren", 1)
+	if es.GetClock() != SystemClock() {
+		t.Errorf("Expected a later change to reach the es bot, got clock %v", es.GetClock())
+	}
+
+	// Coverage counts the hits of every language
+	report := g.CoverageReport()
+	if report.Total != 10 || report.Covered != 1 {
+		t.Errorf("Expected 10 categories with 1 covered, got %d with %d covered", report.Total, report.Covered)
+	}
+}
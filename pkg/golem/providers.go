@@ -0,0 +1,257 @@
+package golem
+
+import (
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SetProvider backs a set with live data instead of a .set file
+type SetProvider interface {
+	// IsMember reports whether an upper-case word or phrase from the input belongs to the set
+	IsMember(member string) bool
+	// Members lists the set members, or returns nil when the set is too large or cannot be listed.
+	// Patterns using a listed set match any member; otherwise they match one word checked with IsMember.
+	Members() []string
+}
+
+// MapProvider backs a map with live data instead of a .map file
+type MapProvider interface {
+	// Lookup returns the value for key and whether the key exists
+	Lookup(key string) (string, bool)
+}
+
+// providerRegistry holds the set and map providers registered by name
+type providerRegistry struct {
+	mutex sync.RWMutex
+	sets  map[string]*setProviderEntry
+	maps  map[string]*mapProviderEntry
+}
+
+// newProviderRegistry creates an empty provider registry
+func newProviderRegistry() *providerRegistry {
+	return &providerRegistry{
+		sets: make(map[string]*setProviderEntry),
+		maps: make(map[string]*mapProviderEntry),
+	}
+}
+
+// setProviderEntry is a registered set provider with its TTL cache
+type setProviderEntry struct {
+	provider SetProvider
+	ttl      time.Duration
+
+	mutex       sync.Mutex
+	regex       string
+	regexExpire time.Time
+	regexCached bool
+	membership  map[string]cachedProviderResult
+}
+
+// mapProviderEntry is a registered map provider with its TTL cache
+type mapProviderEntry struct {
+	provider MapProvider
+	ttl      time.Duration
+
+	mutex   sync.Mutex
+	lookups map[string]cachedProviderResult
+}
+
+// cachedProviderResult is one cached provider answer
+type cachedProviderResult struct {
+	value   string
+	found   bool
+	expires time.Time
+}
+
+// RegisterSetProvider makes <set>name</set> patterns consult provider. Answers are cached for ttl;
+// a ttl of zero asks the provider every time. A provider replaces a loaded set with the same name.
+func (g *Golem) RegisterSetProvider(name string, provider SetProvider, ttl time.Duration) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	g.providers.mutex.Lock()
+	g.providers.sets[name] = &setProviderEntry{provider: provider, ttl: ttl, membership: make(map[string]cachedProviderResult)}
+	g.providers.mutex.Unlock()
+	g.InvalidatePatternMatchingSet(name)
+	g.LogInfo("Registered set provider '%s' (ttl %v)", name, ttl)
+}
+
+// UnregisterSetProvider removes a set provider
+func (g *Golem) UnregisterSetProvider(name string) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	g.providers.mutex.Lock()
+	delete(g.providers.sets, name)
+	g.providers.mutex.Unlock()
+	g.InvalidatePatternMatchingSet(name)
+}
+
+// RegisterMapProvider makes <map name="name"> consult provider. Answers are cached for ttl;
+// a ttl of zero asks the provider every time. Keys the provider does not know fall back to the loaded map.
+func (g *Golem) RegisterMapProvider(name string, provider MapProvider, ttl time.Duration) {
+	name = strings.TrimSpace(name)
+	g.providers.mutex.Lock()
+	g.providers.maps[strings.ToLower(name)] = &mapProviderEntry{provider: provider, ttl: ttl, lookups: make(map[string]cachedProviderResult)}
+	g.providers.mutex.Unlock()
+	g.LogInfo("Registered map provider '%s' (ttl %v)", name, ttl)
+}
+
+// UnregisterMapProvider removes a map provider
+func (g *Golem) UnregisterMapProvider(name string) {
+	g.providers.mutex.Lock()
+	delete(g.providers.maps, strings.ToLower(strings.TrimSpace(name)))
+	g.providers.mutex.Unlock()
+}
+
+// setProvider returns the provider registered for a set, or nil
+func (g *Golem) setProvider(name string) *setProviderEntry {
+	if g == nil || g.providers == nil {
+		return nil
+	}
+	g.providers.mutex.RLock()
+	defer g.providers.mutex.RUnlock()
+	return g.providers.sets[strings.ToUpper(strings.TrimSpace(name))]
+}
+
+// mapProvider returns the provider registered for a map, or nil
+func (g *Golem) mapProvider(name string) *mapProviderEntry {
+	if g == nil || g.providers == nil {
+		return nil
+	}
+	g.providers.mutex.RLock()
+	defer g.providers.mutex.RUnlock()
+	return g.providers.maps[strings.ToLower(strings.TrimSpace(name))]
+}
+
+// invalidateProviderCaches drops the cached answers of the set and map providers with the given name
+func (g *Golem) invalidateProviderCaches(name string) {
+	if entry := g.setProvider(name); entry != nil {
+		entry.mutex.Lock()
+		entry.regexCached = false
+		entry.regex = ""
+		entry.membership = make(map[string]cachedProviderResult)
+		entry.mutex.Unlock()
+	}
+	if entry := g.mapProvider(name); entry != nil {
+		entry.mutex.Lock()
+		entry.lookups = make(map[string]cachedProviderResult)
+		entry.mutex.Unlock()
+	}
+}
+
+// membersRegex returns the regex of the provider's members, from the cache while it is fresh.
+// It is empty when the provider does not list its members.
+func (e *setProviderEntry) membersRegex(name string, now time.Time, prepare func(string) string) string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.regexCached && now.Before(e.regexExpire) {
+		return e.regex
+	}
+	regex := setMemberRegex(name, e.provider.Members(), prepare)
+	if e.ttl > 0 {
+		e.regex = regex
+		e.regexExpire = now.Add(e.ttl)
+		e.regexCached = true
+	}
+	return regex
+}
+
+// isMember asks the provider whether member belongs to the set, from the cache while it is fresh
+func (e *setProviderEntry) isMember(member string, now time.Time) bool {
+	member = strings.ToUpper(strings.TrimSpace(member))
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if cached, exists := e.membership[member]; exists && now.Before(cached.expires) {
+		return cached.found
+	}
+	found := e.provider.IsMember(member)
+	if e.ttl > 0 {
+		e.membership[member] = cachedProviderResult{found: found, expires: now.Add(e.ttl)}
+	}
+	return found
+}
+
+// lookup asks the provider for key, from the cache while it is fresh
+func (e *mapProviderEntry) lookup(key string, now time.Time) (string, bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if cached, exists := e.lookups[key]; exists && now.Before(cached.expires) {
+		return cached.value, cached.found
+	}
+	value, found := e.provider.Lookup(key)
+	if e.ttl > 0 {
+		e.lookups[key] = cachedProviderResult{value: value, found: found, expires: now.Add(e.ttl)}
+	}
+	return value, found
+}
+
+// lookupMapValue looks key up in a registered map provider, then in the loaded map, then in the built-in maps
+func (g *Golem) lookupMapValue(kb *AIMLKnowledgeBase, name, key string) (string, bool) {
+	if entry := g.mapProvider(name); entry != nil {
+		if value, found := entry.lookup(key, g.now()); found {
+			return value, true
+		}
+	}
+	if kb != nil {
+		if value, exists := kb.Maps[name][key]; exists {
+			return value, true
+		}
+	}
+	return g.builtinMapLookup(name, key)
+}
+
+// providerSetGroupPrefix starts the regex group name of a set checked with SetProvider.IsMember
+const providerSetGroupPrefix = "providerset_"
+
+// providerSetRegex returns the pattern regex of a provider set: its members when it lists them,
+// otherwise a one-word group that is checked with IsMember after matching
+func (g *Golem) providerSetRegex(name string) (string, bool) {
+	entry := g.setProvider(name)
+	if entry == nil {
+		return "", false
+	}
+	if regex := entry.membersRegex(name, g.now(), g.prepareForMatching); regex != "" {
+		return regex, true
+	}
+	return "(?P<" + providerSetGroupPrefix + hex.EncodeToString([]byte(strings.ToUpper(name))) + ">[^\\s]+)", true
+}
+
+// checkProviderSetGroups verifies the words captured for unlisted provider sets with IsMember
+func (g *Golem) checkProviderSetGroups(re *regexp.Regexp, matches []string) bool {
+	for i, groupName := range re.SubexpNames() {
+		if !strings.HasPrefix(groupName, providerSetGroupPrefix) || i >= len(matches) {
+			continue
+		}
+		name, err := hex.DecodeString(strings.TrimPrefix(groupName, providerSetGroupPrefix))
+		if err != nil {
+			continue
+		}
+		entry := g.setProvider(string(name))
+		if entry == nil || !entry.isMember(matches[i], g.now()) {
+			return false
+		}
+	}
+	return true
+}
+
+// usesSetProvider reports whether a pattern refers to a set with a registered provider
+func (g *Golem) usesSetProvider(pattern string) bool {
+	if g == nil || g.providers == nil || !strings.Contains(pattern, "<set>") {
+		return false
+	}
+	g.providers.mutex.RLock()
+	empty := len(g.providers.sets) == 0
+	g.providers.mutex.RUnlock()
+	if empty {
+		return false
+	}
+	for _, match := range patternSetRefRegex.FindAllStringSubmatch(pattern, -1) {
+		if g.setProvider(match[1]) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// patternSetRefRegex finds the set references of a pattern
+var patternSetRefRegex = regexp.MustCompile(`<set>([^<]+)</set>`)
@@ -0,0 +1,130 @@
+package golem
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// catalogProvider is a set and map provider over an in-memory product catalog
+type catalogProvider struct {
+	products map[string]string // upper-case product name to price
+	listed   bool
+	calls    int
+}
+
+func (p *catalogProvider) IsMember(member string) bool {
+	p.calls++
+	_, exists := p.products[member]
+	return exists
+}
+
+func (p *catalogProvider) Members() []string {
+	p.calls++
+	if !p.listed {
+		return nil
+	}
+	members := make([]string, 0, len(p.products))
+	for name := range p.products {
+		members = append(members, name)
+	}
+	return members
+}
+
+func (p *catalogProvider) Lookup(key string) (string, bool) {
+	p.calls++
+	price, exists := p.products[strings.ToUpper(key)]
+	return price, exists
+}
+
+const providerTestAIML = `<aiml version="2.0">
+<category><pattern>BUY <set>products</set></pattern><template><star/> costs <map name="prices"><star/></map>.</template></category>
+<category><pattern>BUY *</pattern><template>We do not sell <star/>.</template></category>
+<category><pattern>IS * IN STOCK</pattern><template><map name="prices" operation="contains"><star/></map></template></category>
+</aiml>`
+
+func TestSetAndMapProviders(t *testing.T) {
+	for _, listed := range []bool{true, false} {
+		g := New(false)
+		if err := g.LoadAIMLFromString(providerTestAIML); err != nil {
+			t.Fatalf("LoadAIMLFromString failed: %v", err)
+		}
+		catalog := &catalogProvider{products: map[string]string{"WIDGET": "$5", "GADGET": "$12"}, listed: listed}
+		g.RegisterSetProvider("products", catalog, 0)
+		g.RegisterMapProvider("prices", catalog, 0)
+		session := g.CreateSession("providers")
+
+		if got := askN(t, g, session, "buy widget", 1)[0]; got != "widget costs $5." {
+			t.Errorf("listed=%v: expected provider set match, got %q", listed, got)
+		}
+		if got := askN(t, g, session, "buy sprocket", 1)[0]; got != "We do not sell sprocket." {
+			t.Errorf("listed=%v: expected non-member to fall through, got %q", listed, got)
+		}
+
+		// Live data: a product added after loading matches right away without a TTL
+		catalog.products["SPROCKET"] = "$3"
+		if got := askN(t, g, session, "buy sprocket", 1)[0]; got != "sprocket costs $3." {
+			t.Errorf("listed=%v: expected new product to match, got %q", listed, got)
+		}
+		if got := askN(t, g, session, "is gadget in stock", 1)[0]; got != "true" {
+			t.Errorf("listed=%v: expected contains to use the map provider, got %q", listed, got)
+		}
+	}
+}
+
+func TestProviderCacheTTL(t *testing.T) {
+	clock := NewFixedClock(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	g := New(false, WithClock(clock))
+	if err := g.LoadAIMLFromString(providerTestAIML); err != nil {
+		t.Fatalf("LoadAIMLFromString failed: %v", err)
+	}
+	catalog := &catalogProvider{products: map[string]string{"WIDGET": "$5"}}
+	g.RegisterSetProvider("products", catalog, time.Minute)
+	g.RegisterMapProvider("prices", catalog, time.Minute)
+	session := g.CreateSession("ttl")
+
+	askN(t, g, session, "buy widget", 1)
+	calls := catalog.calls
+	if got := askN(t, g, session, "buy widget", 1)[0]; got != "widget costs $5." || catalog.calls != calls {
+		t.Errorf("Expected cached answers within the TTL, got %q after %d new calls", got, catalog.calls-calls)
+	}
+
+	// Cached answers stay until the TTL expires or the set is invalidated
+	catalog.products["WIDGET"] = "$6"
+	if got := askN(t, g, session, "buy widget", 1)[0]; got != "widget costs $5." {
+		t.Errorf("Expected the cached price, got %q", got)
+	}
+	g.InvalidatePatternMatchingSet("prices")
+	if got := askN(t, g, session, "buy widget", 1)[0]; got != "widget costs $6." {
+		t.Errorf("Expected the new price after invalidation, got %q", got)
+	}
+
+	delete(catalog.products, "WIDGET")
+	clock.Time = clock.Time.Add(2 * time.Minute)
+	if got := askN(t, g, session, "buy widget", 1)[0]; got != "We do not sell widget." {
+		t.Errorf("Expected the removed product to stop matching after the TTL, got %q", got)
+	}
+}
+
+func TestMapProviderFallsBackToLoadedMap(t *testing.T) {
+	g := New(false)
+	if err := g.LoadAIMLFromString(providerTestAIML); err != nil {
+		t.Fatalf("LoadAIMLFromString failed: %v", err)
+	}
+	g.aimlKB.Maps["prices"] = map[string]string{"widget": "$1", "bolt": "$0.10"}
+	g.RegisterSetProvider("products", &catalogProvider{products: map[string]string{"WIDGET": "", "BOLT": ""}, listed: true}, 0)
+	g.RegisterMapProvider("prices", &catalogProvider{products: map[string]string{"WIDGET": "$5"}}, 0)
+	session := g.CreateSession("fallback")
+
+	if got := askN(t, g, session, "buy widget", 1)[0]; got != "widget costs $5." {
+		t.Errorf("Expected the provider to win over the loaded map, got %q", got)
+	}
+	if got := askN(t, g, session, "buy bolt", 1)[0]; got != "bolt costs $0.10." {
+		t.Errorf("Expected unknown keys to use the loaded map, got %q", got)
+	}
+
+	g.UnregisterSetProvider("products")
+	if got := askN(t, g, session, "buy widget", 1)[0]; got != "We do not sell widget." {
+		t.Errorf("Expected the pattern to stop matching without the provider, got %q", got)
+	}
+}
@@ -0,0 +1,317 @@
+package golem
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReplaySession is an ordered list of user inputs from one recorded conversation
+type ReplaySession struct {
+	ID     string   `json:"id"`
+	Inputs []string `json:"inputs"`
+}
+
+// ReplayOptions fixes the clock and random seed so two replays are comparable
+type ReplayOptions struct {
+	Seed  int64
+	Clock time.Time
+}
+
+// ReplayTurn is the outcome of one replayed input
+type ReplayTurn struct {
+	Session  string `json:"session"`
+	Turn     int    `json:"turn"`
+	Input    string `json:"input"`
+	Response string `json:"response"`
+	Category string `json:"category,omitempty"` // key of the category that answered
+	Source   string `json:"source,omitempty"`   // file:line of that category
+	Error    string `json:"error,omitempty"`
+}
+
+// ReplayChange is a turn whose response or matched category differs between two replays
+type ReplayChange struct {
+	Session         string `json:"session"`
+	Turn            int    `json:"turn"`
+	Input           string `json:"input"`
+	OldResponse     string `json:"old_response"`
+	NewResponse     string `json:"new_response"`
+	OldCategory     string `json:"old_category"`
+	NewCategory     string `json:"new_category"`
+	OldSource       string `json:"old_source,omitempty"`
+	NewSource       string `json:"new_source,omitempty"`
+	ResponseChanged bool   `json:"response_changed"`
+	CategoryChanged bool   `json:"category_changed"`
+}
+
+// ReplayChangeGroup collects the changes of inputs previously answered by the same category
+type ReplayChangeGroup struct {
+	Category string         `json:"category"`
+	Source   string         `json:"source,omitempty"`
+	Changes  []ReplayChange `json:"changes"`
+}
+
+// ReplayDiffReport compares the replies of two knowledge bases to the same inputs
+type ReplayDiffReport struct {
+	Sessions int                 `json:"sessions"`
+	Turns    int                 `json:"turns"`
+	Changed  int                 `json:"changed"`
+	Groups   []ReplayChangeGroup `json:"groups"`
+}
+
+// replayLogEntry is one line of a JSONL input log
+type replayLogEntry struct {
+	Session   string `json:"session"`
+	SessionID string `json:"session_id"`
+	Input     string `json:"input"`
+}
+
+// LoadReplaySessions reads recorded inputs from a JSONL log or a plain transcript.
+// JSONL lines hold {"session": "...", "input": "..."}; sessions keep the order of their first input.
+// Transcripts hold one input per line, optionally prefixed with "User:"; "Bot:" and "Golem:"
+// lines are ignored and a blank line starts a new session.
+func LoadReplaySessions(path string) ([]ReplaySession, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open inputs %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read inputs %s: %v", path, err)
+	}
+
+	if isJSONLines(lines) {
+		return parseReplayLog(path, lines)
+	}
+	return parseReplayTranscript(lines), nil
+}
+
+// isJSONLines reports whether the first non-empty line is a JSON object
+func isJSONLines(lines []string) bool {
+	for _, line := range lines {
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			return strings.HasPrefix(trimmed, "{")
+		}
+	}
+	return false
+}
+
+// parseReplayLog parses JSONL input log lines
+func parseReplayLog(path string, lines []string) ([]ReplaySession, error) {
+	var sessions []ReplaySession
+	index := make(map[string]int)
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var entry replayLogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid JSON: %v", path, i+1, err)
+		}
+		if entry.Input == "" {
+			return nil, fmt.Errorf("%s:%d: missing input", path, i+1)
+		}
+		id := entry.Session
+		if id == "" {
+			id = entry.SessionID
+		}
+		if id == "" {
+			id = "default"
+		}
+		pos, exists := index[id]
+		if !exists {
+			pos = len(sessions)
+			index[id] = pos
+			sessions = append(sessions, ReplaySession{ID: id})
+		}
+		sessions[pos].Inputs = append(sessions[pos].Inputs, entry.Input)
+	}
+	return sessions, nil
+}
+
+// parseReplayTranscript parses a plain-text transcript
+func parseReplayTranscript(lines []string) []ReplaySession {
+	var sessions []ReplaySession
+	current := ReplaySession{}
+	flush := func() {
+		if len(current.Inputs) > 0 {
+			current.ID = fmt.Sprintf("transcript_%d", len(sessions)+1)
+			sessions = append(sessions, current)
+		}
+		current = ReplaySession{}
+	}
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		lower := strings.ToLower(trimmed)
+		switch {
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(lower, "bot:") || strings.HasPrefix(lower, "golem:"):
+			continue
+		case strings.HasPrefix(lower, "user:"):
+			current.Inputs = append(current.Inputs, strings.TrimSpace(trimmed[len("user:"):]))
+		default:
+			current.Inputs = append(current.Inputs, trimmed)
+		}
+	}
+	flush()
+	return sessions
+}
+
+// Replay runs recorded sessions through the loaded knowledge base with a fixed clock and seed.
+// Each session starts fresh with its own seeded random source, so results do not depend on session order.
+func (g *Golem) Replay(sessions []ReplaySession, options ReplayOptions) []ReplayTurn {
+	previousClock := g.clock
+	defer g.SetClock(previousClock)
+	g.SetClock(NewFixedClock(options.Clock))
+
+	var turns []ReplayTurn
+	for _, recorded := range sessions {
+		session := g.createSession("replay_" + recorded.ID)
+		session.SetRandomSeed(options.Seed)
+
+		for i, input := range recorded.Inputs {
+			session.LastMatch = nil
+			turn := ReplayTurn{Session: recorded.ID, Turn: i + 1, Input: input}
+			response, err := g.ProcessInput(input, session)
+			turn.Response = strings.TrimSpace(response)
+			if err != nil {
+				turn.Error = err.Error()
+			}
+			if session.LastMatch != nil {
+				turn.Category = CategoryKey(*session.LastMatch)
+				turn.Source = categorySource(session.LastMatch)
+			}
+			turns = append(turns, turn)
+		}
+
+		g.discardSession(session.ID)
+	}
+	return turns
+}
+
+// categorySource formats where a category was defined
+func categorySource(category *Category) string {
+	if category.SourceLine == 0 {
+		return displaySourceFile(category.SourceFile)
+	}
+	return fmt.Sprintf("%s:%d", displaySourceFile(category.SourceFile), category.SourceLine)
+}
+
+// DiffReplays compares two replays of the same sessions and groups the changed turns
+// by the category that answered them in the old replay
+func DiffReplays(oldTurns, newTurns []ReplayTurn) *ReplayDiffReport {
+	report := &ReplayDiffReport{Groups: make([]ReplayChangeGroup, 0)}
+	sessions := make(map[string]bool)
+	groups := make(map[string]*ReplayChangeGroup)
+
+	for i := range oldTurns {
+		if i >= len(newTurns) {
+			break
+		}
+		before, after := oldTurns[i], newTurns[i]
+		sessions[before.Session] = true
+		report.Turns++
+
+		oldResponse := replayOutcome(before)
+		newResponse := replayOutcome(after)
+		change := ReplayChange{
+			Session:         before.Session,
+			Turn:            before.Turn,
+			Input:           before.Input,
+			OldResponse:     oldResponse,
+			NewResponse:     newResponse,
+			OldCategory:     before.Category,
+			NewCategory:     after.Category,
+			OldSource:       before.Source,
+			NewSource:       after.Source,
+			ResponseChanged: oldResponse != newResponse,
+			CategoryChanged: before.Category != after.Category,
+		}
+		if !change.ResponseChanged && !change.CategoryChanged {
+			continue
+		}
+		report.Changed++
+
+		groupKey := before.Category
+		if groupKey == "" {
+			groupKey = "(no match)"
+		}
+		group, exists := groups[groupKey]
+		if !exists {
+			group = &ReplayChangeGroup{Category: groupKey, Source: before.Source}
+			groups[groupKey] = group
+		}
+		group.Changes = append(group.Changes, change)
+	}
+	report.Sessions = len(sessions)
+
+	for _, group := range groups {
+		report.Groups = append(report.Groups, *group)
+	}
+	// Categories with the most changed turns come first
+	sort.Slice(report.Groups, func(i, j int) bool {
+		if len(report.Groups[i].Changes) != len(report.Groups[j].Changes) {
+			return len(report.Groups[i].Changes) > len(report.Groups[j].Changes)
+		}
+		return report.Groups[i].Category < report.Groups[j].Category
+	})
+	return report
+}
+
+// replayOutcome returns the response, or the error when the turn failed
+func replayOutcome(turn ReplayTurn) string {
+	if turn.Error != "" {
+		return "error: " + turn.Error
+	}
+	return turn.Response
+}
+
+// DiffKnowledgeBases replays the same sessions through two bots and reports what changed
+func DiffKnowledgeBases(oldBot, newBot *Golem, sessions []ReplaySession, options ReplayOptions) *ReplayDiffReport {
+	return DiffReplays(oldBot.Replay(sessions, options), newBot.Replay(sessions, options))
+}
+
+// String formats the report grouped by category
+func (r *ReplayDiffReport) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Replayed %d turns in %d sessions: %d changed\n", r.Turns, r.Sessions, r.Changed))
+	for _, group := range r.Groups {
+		sb.WriteString(fmt.Sprintf("\n%s", group.Category))
+		if group.Source != "" {
+			sb.WriteString(fmt.Sprintf(" (%s)", group.Source))
+		}
+		sb.WriteString(fmt.Sprintf(": %d changed\n", len(group.Changes)))
+		for _, change := range group.Changes {
+			sb.WriteString(fmt.Sprintf("  [%s #%d] %q\n", change.Session, change.Turn, change.Input))
+			if change.CategoryChanged {
+				sb.WriteString(fmt.Sprintf("    category: %s -> %s\n", replayCategoryLabel(change.OldCategory), replayCategoryLabel(change.NewCategory)))
+			}
+			if change.ResponseChanged {
+				sb.WriteString(fmt.Sprintf("    - %s\n", change.OldResponse))
+				sb.WriteString(fmt.Sprintf("    + %s\n", change.NewResponse))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// replayCategoryLabel returns a printable category key
+func replayCategoryLabel(key string) string {
+	if key == "" {
+		return "(no match)"
+	}
+	return key
+}
@@ -0,0 +1,163 @@
+package golem
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeReplayBot writes a bot directory with the given categories
+func writeReplayBot(t *testing.T, categories string) string {
+	t.Helper()
+	dir := t.TempDir()
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<aiml version="2.0">
+` + categories + `
+</aiml>`
+	if err := os.WriteFile(filepath.Join(dir, "bot.aiml"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write bot: %v", err)
+	}
+	return dir
+}
+
+// loadReplayBot loads a bot directory into a new instance
+func loadReplayBot(t *testing.T, dir string) *Golem {
+	t.Helper()
+	g := New(false)
+	if err := g.loadBotDirectory(dir); err != nil {
+		t.Fatalf("Failed to load bot: %v", err)
+	}
+	return g
+}
+
+const replayOldCategories = `<category><pattern>HELLO</pattern><template>Hello!</template></category>
+<category><pattern>MY NAME IS *</pattern><template><think><set name="name"><star/></set></think>OK</template></category>
+<category><pattern>WHO AM I</pattern><template>You are <get name="name"/></template></category>
+<category><pattern>WHAT DAY IS IT</pattern><template><date format="%A"/></template></category>
+<category><pattern>*</pattern><template>I do not understand</template></category>`
+
+const replayNewCategories = `<category><pattern>HELLO</pattern><template>Hi there!</template></category>
+<category><pattern>MY NAME IS *</pattern><template><think><set name="name"><star/></set></think>OK</template></category>
+<category><pattern>WHO AM I</pattern><template>You are <get name="name"/></template></category>
+<category><pattern>WHAT DAY IS IT</pattern><template><date format="%A"/></template></category>
+<category><pattern>HELP</pattern><template>How can I help?</template></category>
+<category><pattern>*</pattern><template>I do not understand</template></category>`
+
+func TestLoadReplaySessions(t *testing.T) {
+	dir := t.TempDir()
+
+	jsonl := filepath.Join(dir, "log.jsonl")
+	content := `{"session": "a", "input": "hello"}
+{"session": "b", "input": "help"}
+{"session": "a", "input": "who am i"}
+`
+	if err := os.WriteFile(jsonl, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+	sessions, err := LoadReplaySessions(jsonl)
+	if err != nil {
+		t.Fatalf("LoadReplaySessions failed: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].ID != "a" || len(sessions[0].Inputs) != 2 || sessions[1].Inputs[0] != "help" {
+		t.Errorf("Unexpected sessions: %+v", sessions)
+	}
+
+	transcript := filepath.Join(dir, "transcript.txt")
+	content = "User: hello\nBot: Hello!\nwho am i\n\nUser: help\n"
+	if err := os.WriteFile(transcript, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write transcript: %v", err)
+	}
+	sessions, err = LoadReplaySessions(transcript)
+	if err != nil {
+		t.Fatalf("LoadReplaySessions failed: %v", err)
+	}
+	if len(sessions) != 2 || strings.Join(sessions[0].Inputs, "|") != "hello|who am i" || sessions[1].Inputs[0] != "help" {
+		t.Errorf("Unexpected transcript sessions: %+v", sessions)
+	}
+
+	broken := filepath.Join(dir, "broken.jsonl")
+	if err := os.WriteFile(broken, []byte("{\"input\": \"hi\"}\n{\"session\": \"x\"}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+	if _, err := LoadReplaySessions(broken); err == nil || !strings.Contains(err.Error(), ":2:") {
+		t.Errorf("Expected line number in error, got %v", err)
+	}
+}
+
+func TestDiffKnowledgeBases(t *testing.T) {
+	oldBot := loadReplayBot(t, writeReplayBot(t, replayOldCategories))
+	newBot := loadReplayBot(t, writeReplayBot(t, replayNewCategories))
+
+	sessions := []ReplaySession{
+		{ID: "a", Inputs: []string{"hello", "my name is Ada", "who am i", "what day is it"}},
+		{ID: "b", Inputs: []string{"help", "who am i"}},
+	}
+	options := ReplayOptions{Seed: 1, Clock: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)}
+
+	report := DiffKnowledgeBases(oldBot, newBot, sessions, options)
+	if report.Turns != 6 || report.Sessions != 2 {
+		t.Errorf("Expected 6 turns in 2 sessions, got %d in %d", report.Turns, report.Sessions)
+	}
+	// Only HELLO (new response) and HELP (new category) changed; state and clock are replayed identically
+	if report.Changed != 2 {
+		t.Fatalf("Expected 2 changed turns, got %d:\n%s", report.Changed, report.String())
+	}
+
+	groups := make(map[string]ReplayChangeGroup)
+	for _, group := range report.Groups {
+		groups[group.Category] = group
+	}
+	hello := groups["HELLO"].Changes
+	if len(hello) != 1 || !hello[0].ResponseChanged || hello[0].CategoryChanged || hello[0].NewResponse != "Hi there!" {
+		t.Errorf("Unexpected HELLO change: %+v", hello)
+	}
+	help := groups["*"].Changes
+	if len(help) != 1 || !help[0].CategoryChanged || help[0].NewCategory != "HELP" || help[0].Session != "b" {
+		t.Errorf("Expected help to move from * to HELP: %+v", help)
+	}
+	if !strings.Contains(groups["*"].Source, "bot.aiml:") {
+		t.Errorf("Expected group source with file and line, got %q", groups["*"].Source)
+	}
+
+	text := report.String()
+	for _, want := range []string{"category: * -> HELP", "- Hello!", "+ Hi there!"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected report to contain %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestReplayUsesFixedClock(t *testing.T) {
+	bot := loadReplayBot(t, writeReplayBot(t, replayOldCategories))
+	sessions := []ReplaySession{{ID: "clock", Inputs: []string{"what day is it"}}}
+
+	turns := bot.Replay(sessions, ReplayOptions{Clock: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)})
+	if len(turns) != 1 || turns[0].Response != "Tuesday" {
+		t.Errorf("Expected the fixed clock's weekday, got %+v", turns)
+	}
+	if turns[0].Category != "WHAT DAY IS IT" {
+		t.Errorf("Expected matched category to be recorded, got %q", turns[0].Category)
+	}
+	if bot.clock != nil {
+		t.Error("Expected the clock to be restored after replay")
+	}
+}
+
+func TestDiffCommand(t *testing.T) {
+	oldDir := writeReplayBot(t, replayOldCategories)
+	newDir := writeReplayBot(t, replayNewCategories)
+	inputs := filepath.Join(t.TempDir(), "log.jsonl")
+	if err := os.WriteFile(inputs, []byte(`{"session": "a", "input": "hello"}`+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+
+	g := New(false)
+	if err := g.Execute("diff", []string{"--old", oldDir, "--new", newDir, "--inputs", inputs, "--seed", "3", "--clock", "2024-01-02T10:00:00Z"}); err != nil {
+		t.Fatalf("diff command failed: %v", err)
+	}
+	if err := g.Execute("diff", []string{"--old", oldDir}); err == nil {
+		t.Error("Expected error when --new and --inputs are missing")
+	}
+}
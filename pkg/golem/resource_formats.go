@@ -0,0 +1,241 @@
+package golem
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// ResourceFormat is the on-disk format of a set, map, properties or substitution file
+type ResourceFormat int
+
+const (
+	// ResourceFormatJSON is the JSON array format
+	ResourceFormatJSON ResourceFormat = iota
+	// ResourceFormatText is the Pandorabots text format: one member or key:value pair per line
+	ResourceFormatText
+	// ResourceFormatCSV is comma-separated values
+	ResourceFormatCSV
+	// ResourceFormatTSV is tab-separated values
+	ResourceFormatTSV
+)
+
+// String returns the format name
+func (f ResourceFormat) String() string {
+	switch f {
+	case ResourceFormatJSON:
+		return "json"
+	case ResourceFormatText:
+		return "text"
+	case ResourceFormatCSV:
+		return "csv"
+	case ResourceFormatTSV:
+		return "tsv"
+	default:
+		return "unknown"
+	}
+}
+
+// resourceFormatExtensions maps explicit format extensions to formats
+var resourceFormatExtensions = map[string]ResourceFormat{
+	".txt": ResourceFormatText,
+	".csv": ResourceFormatCSV,
+	".tsv": ResourceFormatTSV,
+}
+
+// resourceName returns the resource name of a file of the given kind (".set", ".map", ...).
+// Both "colors.set" and "colors.set.csv" are colors sets.
+func resourceName(path, kind string) (string, bool) {
+	base := filepath.Base(path)
+	if _, exists := resourceFormatExtensions[strings.ToLower(filepath.Ext(base))]; exists {
+		base = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	if len(base) <= len(kind) || !strings.EqualFold(base[len(base)-len(kind):], kind) {
+		return "", false
+	}
+	return base[:len(base)-len(kind)], true
+}
+
+// isResourceFile reports whether path is a resource file of the given kind in any format
+func isResourceFile(path, kind string) bool {
+	_, ok := resourceName(path, kind)
+	return ok
+}
+
+// detectResourceFormat picks the format from an explicit extension, otherwise from the content:
+// files starting with '[' are JSON and anything else is the text format
+func detectResourceFormat(filename string, content []byte) ResourceFormat {
+	if format, exists := resourceFormatExtensions[strings.ToLower(filepath.Ext(filename))]; exists {
+		return format
+	}
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")))
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		return ResourceFormatJSON
+	}
+	return ResourceFormatText
+}
+
+// resourceRecord is one entry of a text, CSV or TSV resource file
+type resourceRecord struct {
+	Line   int
+	Fields []string
+}
+
+// parseResourceRecords reads the entries of a text, CSV or TSV resource file.
+// Every entry must have exactly fields values, or at least -fields values when fields is negative.
+// Text lines with more than one field are split at the first of the separator characters. Blank lines and lines starting with '#' are skipped.
+// Malformed lines are all reported in one error, each with its line number.
+func parseResourceRecords(filename string, content []byte, format ResourceFormat, fields int, separators string) ([]resourceRecord, error) {
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+	var records []resourceRecord
+	var problems []string
+	report := func(line int, format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf("%s:%d: %s", filename, line, fmt.Sprintf(format, args...)))
+	}
+
+	switch format {
+	case ResourceFormatCSV, ResourceFormatTSV:
+		reader := csv.NewReader(bytes.NewReader(content))
+		reader.Comment = '#'
+		reader.FieldsPerRecord = -1
+		if format == ResourceFormatTSV {
+			reader.Comma = '\t'
+			reader.LazyQuotes = true
+		} else {
+			reader.TrimLeadingSpace = true
+		}
+		for {
+			row, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				var parseErr *csv.ParseError
+				if errors.As(err, &parseErr) {
+					report(parseErr.Line, "%v", parseErr.Err)
+					if parseErr.Err == csv.ErrQuote || parseErr.Err == csv.ErrBareQuote {
+						continue
+					}
+				}
+				return nil, fmt.Errorf("failed to read %s: %v", filename, err)
+			}
+			line, _ := reader.FieldPos(0)
+
+			// Extra empty cells come from spreadsheet exports
+			for len(row) > maxInt(fields, 1) && strings.TrimSpace(row[len(row)-1]) == "" {
+				row = row[:len(row)-1]
+			}
+			if strings.TrimSpace(strings.Join(row, "")) == "" {
+				continue
+			}
+			if fields < 0 && len(row) < -fields {
+				report(line, "expected at least %d column(s), found %d", -fields, len(row))
+				continue
+			}
+			if fields > 0 && len(row) != fields {
+				report(line, "expected %d column(s), found %d", fields, len(row))
+				continue
+			}
+			for i := range row {
+				row[i] = strings.TrimSpace(row[i])
+			}
+			records = append(records, resourceRecord{Line: line, Fields: row})
+		}
+	default:
+		for i, raw := range strings.Split(string(content), "\n") {
+			line := strings.TrimSpace(strings.TrimSuffix(raw, "\r"))
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if fields == 1 {
+				records = append(records, resourceRecord{Line: i + 1, Fields: []string{line}})
+				continue
+			}
+			pos := strings.IndexAny(line, separators)
+			if pos < 0 {
+				report(i+1, "expected key%cvalue, found %q", separators[0], line)
+				continue
+			}
+			records = append(records, resourceRecord{
+				Line:   i + 1,
+				Fields: []string{strings.TrimSpace(line[:pos]), strings.TrimSpace(line[pos+1:])},
+			})
+		}
+	}
+
+	for _, record := range records {
+		if record.Fields[0] == "" {
+			report(record.Line, "empty key")
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("malformed lines in %s:\n  %s", filename, strings.Join(problems, "\n  "))
+	}
+	return records, nil
+}
+
+// parseResourcePairs reads a text, CSV or TSV file of key/value pairs
+func parseResourcePairs(filename string, content []byte, format ResourceFormat, separators string) (map[string]string, error) {
+	records, err := parseResourceRecords(filename, content, format, 2, separators)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]string, len(records))
+	for _, record := range records {
+		result[record.Fields[0]] = record.Fields[1]
+	}
+	return result, nil
+}
+
+// wholeWordSubstitutions pads the patterns of a text, CSV or TSV substitution file with a space on
+// each side that starts or ends with a letter or digit, and their replacements on the same sides.
+// These formats trim their entries, so "u,you" must mean the word "u" rather than every "u" in the input.
+func wholeWordSubstitutions(pairs map[string]string) map[string]string {
+	result := make(map[string]string, len(pairs))
+	for pattern, replacement := range pairs {
+		runes := []rune(pattern)
+		if isSubstitutionWordRune(runes[0]) {
+			pattern, replacement = " "+pattern, " "+replacement
+		}
+		if isSubstitutionWordRune(runes[len(runes)-1]) {
+			pattern, replacement = pattern+" ", replacement+" "
+		}
+		result[pattern] = replacement
+	}
+	return result
+}
+
+// parseResourceMembers reads a text, CSV or TSV file with one set member per line
+func parseResourceMembers(filename string, content []byte, format ResourceFormat) ([]string, error) {
+	records, err := parseResourceRecords(filename, content, format, 1, "")
+	if err != nil {
+		return nil, err
+	}
+	members := make([]string, 0, len(records))
+	for _, record := range records {
+		members = append(members, record.Fields[0])
+	}
+	return members, nil
+}
+
+// parseResourceRelations reads a text, CSV or TSV file of words and their related words: text lines
+// hold word:related, related and CSV/TSV rows hold word,related,related
+func parseResourceRelations(filename string, content []byte, format ResourceFormat) (map[string][]string, error) {
+	records, err := parseResourceRecords(filename, content, format, -2, ":")
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string][]string, len(records))
+	for _, record := range records {
+		related := record.Fields[1:]
+		if format == ResourceFormatText {
+			related = strings.Split(record.Fields[1], ",")
+		}
+		result[record.Fields[0]] = appendRelatedWords(result[record.Fields[0]], related)
+	}
+	return result, nil
+}
@@ -0,0 +1,153 @@
+package golem
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeResourceFile writes a resource file into dir and returns its path
+func writeResourceFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestResourceName(t *testing.T) {
+	testCases := []struct {
+		path, kind string
+		name       string
+		ok         bool
+	}{
+		{"bot/colors.set", ".set", "colors", true},
+		{"bot/Colors.SET.csv", ".set", "Colors", true},
+		{"bot/capitals.map.tsv", ".map", "capitals", true},
+		{"bot/person.substitution.txt", ".substitution", "person", true},
+		{"bot/readme.txt", ".set", "", false},
+		{"bot/colors.csv", ".set", "", false},
+		{"bot/.set", ".set", "", false},
+	}
+	for _, tc := range testCases {
+		name, ok := resourceName(tc.path, tc.kind)
+		if name != tc.name || ok != tc.ok {
+			t.Errorf("resourceName(%q, %q) = %q, %v; expected %q, %v", tc.path, tc.kind, name, ok, tc.name, tc.ok)
+		}
+	}
+}
+
+func TestLoadSetFormats(t *testing.T) {
+	g := New(false)
+	dir := t.TempDir()
+
+	testCases := []struct {
+		name, content string
+	}{
+		{"json.set", `["red", "green", "light blue"]`},
+		{"text.set", "# primary colors\nred\n\ngreen\r\nlight blue\n"},
+		{"sheet.set.csv", "red\n\"green\"\n# comment\nlight blue,,\n"},
+		{"sheet.set.tsv", "red\ngreen\nlight blue\n"},
+		{"plain.set.txt", "red\ngreen\nlight blue"},
+	}
+	expected := []string{"red", "green", "light blue"}
+	for _, tc := range testCases {
+		members, err := g.LoadSetFromFile(writeResourceFile(t, dir, tc.name, tc.content))
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(members, expected) {
+			t.Errorf("%s: got %v, expected %v", tc.name, members, expected)
+		}
+	}
+
+	_, err := g.LoadSetFromFile(writeResourceFile(t, dir, "bad.set.csv", "red\ngreen,blue\nyellow\norange,pink\n"))
+	if err == nil {
+		t.Fatal("Expected an error for rows with several columns")
+	}
+	for _, want := range []string{"bad.set.csv:2: expected 1 column(s), found 2", "bad.set.csv:4:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %v", want, err)
+		}
+	}
+}
+
+func TestLoadPairFormats(t *testing.T) {
+	g := New(false)
+	dir := t.TempDir()
+	expected := map[string]string{"france": "Paris", "united kingdom": "London"}
+
+	for name, content := range map[string]string{
+		"json.map":      `[{"key": "france", "value": "Paris"}, {"key": "united kingdom", "value": "London"}]`,
+		"text.map":      "# Pandorabots format\nfrance:Paris\r\n\nunited kingdom : London\n",
+		"plain.map.txt": "france:Paris\nunited kingdom:London",
+		"sheet.map.csv": "# exported\n\"france\",Paris,,\nunited kingdom, London\n",
+		"sheet.map.tsv": "france\tParis\nunited kingdom\tLondon\t\n",
+	} {
+		result, err := g.LoadMapFromFile(writeResourceFile(t, dir, name, content))
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("%s: got %v, expected %v", name, result, expected)
+		}
+	}
+
+	// Values may contain the separator after the first one
+	result, err := g.LoadMapFromFile(writeResourceFile(t, dir, "urls.map", "home:http://example.com\n"))
+	if err != nil || result["home"] != "http://example.com" {
+		t.Errorf("Expected value split at the first colon, got %v (%v)", result, err)
+	}
+
+	properties, err := g.LoadPropertiesFromFile(writeResourceFile(t, dir, "bot.properties", "name=Golem\nversion:2.0\n# comment\n"))
+	if err != nil || properties["name"] != "Golem" || properties["version"] != "2.0" {
+		t.Errorf("Expected key=value and key:value properties, got %v (%v)", properties, err)
+	}
+
+	// Words are substituted as whole words, punctuation wherever it appears
+	substitutions, err := g.LoadSubstitutionFromFile(writeResourceFile(t, dir, "normal.substitution.csv", "wanna,want to\n\"(\",\"\"\nu,you\n"))
+	if err != nil || substitutions[" wanna "] != " want to " || substitutions["("] != "" || len(substitutions) != 3 {
+		t.Errorf("Expected CSV substitutions with an empty replacement, got %v (%v)", substitutions, err)
+	}
+	g.SetKnowledgeBase(NewAIMLKnowledgeBase())
+	g.GetKnowledgeBase().Substitutions["normal"] = substitutions
+	if got := g.applyLoadedSubstitutions("THANK U BUD (WANNA GO)"); got != "THANK YOU BUD WANT TO GO)" {
+		t.Errorf("Expected single-letter entries to replace whole words only, got %q", got)
+	}
+
+	_, err = g.LoadMapFromFile(writeResourceFile(t, dir, "broken.map", "france:Paris\nno separator here\n:orphan\n"))
+	if err == nil {
+		t.Fatal("Expected an error for malformed map lines")
+	}
+	for _, want := range []string{"broken.map:2: expected key:value", "broken.map:3: empty key"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %v", want, err)
+		}
+	}
+}
+
+func TestLoadResourceFormatsFromDirectory(t *testing.T) {
+	dir := writeReplayBot(t, `<category><pattern>I LIKE <set>colors</set></pattern><template>Nice</template></category>
+<category><pattern>CAPITAL OF *</pattern><template><map name="capital"><star/></map></template></category>`)
+	writeResourceFile(t, dir, "colors.set.csv", "red\ngreen\n")
+	writeResourceFile(t, dir, "capital.map", "france:Paris\n")
+	writeResourceFile(t, dir, "bot.properties.txt", "name:Golem\n")
+	writeResourceFile(t, dir, "notes.txt", "not a resource\n")
+
+	g := loadReplayBot(t, dir)
+	session := g.CreateSession("formats")
+	if got := askN(t, g, session, "i like green", 1)[0]; got != "Nice" {
+		t.Errorf("Expected CSV set to be loaded, got %q", got)
+	}
+	if got := askN(t, g, session, "capital of france", 1)[0]; got != "Paris" {
+		t.Errorf("Expected text map to be loaded, got %q", got)
+	}
+	if got := g.aimlKB.GetProperty("name"); got != "Golem" {
+		t.Errorf("Expected text properties to be loaded, got %q", got)
+	}
+}
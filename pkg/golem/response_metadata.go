@@ -0,0 +1,41 @@
+package golem
+
+// ResponseMetadata describes how the response to an input was produced
+type ResponseMetadata struct {
+	// Input is the text the user typed
+	Input string `json:"input"`
+	// MatchedInput is the text that was matched, after synonym expansion and spelling correction
+	MatchedInput string `json:"matched_input"`
+	// Category is the category that answered
+	Category *Category `json:"-"`
+	// Language is the knowledge base language the input was routed to, empty for single-language bots
+	Language string `json:"language,omitempty"`
+	// Synonyms lists the words synonym expansion replaced (Distance is always zero)
+	Synonyms []WordCorrection `json:"synonyms,omitempty"`
+	// Corrections lists the words the spelling pre-pass replaced
+	Corrections []WordCorrection `json:"corrections,omitempty"`
+	// Entities lists the spans recognized for the entity sets the patterns use
+	Entities []Entity `json:"entities,omitempty"`
+	// Intent is the intent the classifier redirected a catch-all match to, with its confidence
+	Intent           string  `json:"intent,omitempty"`
+	IntentConfidence float64 `json:"intent_confidence,omitempty"`
+	// FuzzyMatch is the pattern the fuzzy fallback answered with instead of the catch-all
+	// category, nil when no fuzzy match was used
+	FuzzyMatch *FuzzyCandidate `json:"fuzzy_match,omitempty"`
+	// Suggestions lists the patterns the fuzzy fallback offered as "Did you mean …?" choices
+	Suggestions []FuzzyCandidate `json:"suggestions,omitempty"`
+	// FAQ is the passage the FAQ fallback answered with, nil when the fallback was not used
+	FAQ *FAQResult `json:"faq,omitempty"`
+	// Form is the form that handled the input, empty when no form was being filled in
+	Form string `json:"form,omitempty"`
+}
+
+// ProcessInputWithMetadata processes user input like ProcessInput and also returns how the
+// response was produced
+func (g *Golem) ProcessInputWithMetadata(input string, session *ChatSession) (string, *ResponseMetadata, error) {
+	response, err := g.ProcessInput(input, session)
+	if err != nil {
+		return "", nil, err
+	}
+	return response, session.LastResponse, nil
+}
@@ -0,0 +1,399 @@
+package golem
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScheduledMessage is a message the bot sends on its own once it is due. Its template is
+// processed at that time, in the session that scheduled it, with the wildcards of the match that
+// scheduled it.
+type ScheduledMessage struct {
+	ID        string            `json:"id"`
+	SessionID string            `json:"session_id"`
+	Template  string            `json:"template"`
+	Wildcards map[string]string `json:"wildcards,omitempty"`
+	Due       time.Time         `json:"due"`
+	Created   time.Time         `json:"created"`
+}
+
+// DeliveryFunc receives the messages of due schedules, for sending to the session's user
+type DeliveryFunc func(session *ChatSession, message string)
+
+// scheduleDays finds the day counts time.ParseDuration doesn't accept
+var scheduleDays = regexp.MustCompile(`([0-9]+)d`)
+
+// ParseScheduleDelay parses a delay such as "10m", "1h30m" or "2d" (days are 24 hours)
+func ParseScheduleDelay(delay string) (time.Duration, error) {
+	text := scheduleDays.ReplaceAllStringFunc(strings.TrimSpace(delay), func(days string) string {
+		n, _ := strconv.Atoi(strings.TrimSuffix(days, "d"))
+		return strconv.Itoa(n*24) + "h"
+	})
+	duration, err := time.ParseDuration(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid delay %q (expected a duration such as 10m, 1h30m or 2d)", delay)
+	}
+	if duration < 0 {
+		return 0, fmt.Errorf("invalid delay %q (must not be negative)", delay)
+	}
+	return duration, nil
+}
+
+// WithDeliveryFunc sets the callback that receives scheduled messages
+func WithDeliveryFunc(deliver DeliveryFunc) Option {
+	return func(g *Golem) {
+		g.SetDeliveryFunc(deliver)
+	}
+}
+
+// SetDeliveryFunc sets the callback that receives scheduled messages. Without one, due messages
+// wait until a callback is set.
+func (g *Golem) SetDeliveryFunc(deliver DeliveryFunc) {
+	g.scheduleMutex.Lock()
+	g.deliver = deliver
+	g.scheduleMutex.Unlock()
+}
+
+// Schedule makes the bot send template to session after delay, and saves the session to the
+// session store
+func (g *Golem) Schedule(session *ChatSession, template string, delay time.Duration) (*ScheduledMessage, error) {
+	return g.schedule(session, template, nil, delay)
+}
+
+// schedule makes the bot send template to session after delay, processed with a copy of wildcards
+func (g *Golem) schedule(session *ChatSession, template string, wildcards map[string]string, delay time.Duration) (*ScheduledMessage, error) {
+	if session == nil {
+		return nil, fmt.Errorf("scheduled messages need a session")
+	}
+	if strings.TrimSpace(template) == "" {
+		return nil, fmt.Errorf("scheduled message is empty")
+	}
+
+	now := g.GetClock().Now()
+	g.scheduleMutex.Lock()
+	id := now.UnixNano()
+	for g.findSchedule(session, fmt.Sprintf("%s-%d", session.ID, id)) >= 0 {
+		id++
+	}
+	message := &ScheduledMessage{
+		ID:        fmt.Sprintf("%s-%d", session.ID, id),
+		SessionID: session.ID,
+		Template:  template,
+		Due:       now.Add(delay),
+		Created:   now,
+	}
+	for key, value := range wildcards {
+		if message.Wildcards == nil {
+			message.Wildcards = make(map[string]string, len(wildcards))
+		}
+		message.Wildcards[key] = value
+	}
+	session.Schedules = append(session.Schedules, message)
+	g.scheduleMutex.Unlock()
+
+	g.LogInfo("Scheduled %s for %s in session %s", message.ID, message.Due.Format(time.RFC3339), session.ID)
+	if err := g.SaveSession(session); err != nil {
+		g.LogWarn("Failed to save session %s: %v", session.ID, err)
+	}
+	return message, nil
+}
+
+// findSchedule returns the index of the session's scheduled message with the given ID, or -1
+func (g *Golem) findSchedule(session *ChatSession, id string) int {
+	for i, message := range session.Schedules {
+		if message.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// ScheduledMessages returns the pending messages of every open session, the next due first
+func (g *Golem) ScheduledMessages() []ScheduledMessage {
+	g.sessionMutex.RLock()
+	g.scheduleMutex.Lock()
+	var messages []ScheduledMessage
+	for _, session := range g.sessions {
+		for _, message := range session.Schedules {
+			messages = append(messages, *message)
+		}
+	}
+	g.scheduleMutex.Unlock()
+	g.sessionMutex.RUnlock()
+
+	sortScheduledMessages(messages)
+	return messages
+}
+
+// sortScheduledMessages orders messages by due time, then ID
+func sortScheduledMessages(messages []ScheduledMessage) {
+	sort.Slice(messages, func(i, j int) bool {
+		if !messages[i].Due.Equal(messages[j].Due) {
+			return messages[i].Due.Before(messages[j].Due)
+		}
+		return messages[i].ID < messages[j].ID
+	})
+}
+
+// CancelScheduledMessage removes a pending message and reports whether it existed. A session
+// answering an input is waited for.
+func (g *Golem) CancelScheduledMessage(id string) bool {
+	g.sessionMutex.RLock()
+	var owner *ChatSession
+	g.scheduleMutex.Lock()
+	for _, session := range g.sessions {
+		if g.findSchedule(session, id) >= 0 {
+			owner = session
+			break
+		}
+	}
+	g.scheduleMutex.Unlock()
+	g.sessionMutex.RUnlock()
+
+	if owner == nil {
+		return false
+	}
+
+	// The schedules are changed and saved in the session's turn, like the rest of the session
+	owner.turn.Lock()
+	defer owner.turn.Unlock()
+	g.scheduleMutex.Lock()
+	i := g.findSchedule(owner, id)
+	if i >= 0 {
+		owner.Schedules = append(owner.Schedules[:i], owner.Schedules[i+1:]...)
+	}
+	g.scheduleMutex.Unlock()
+	if i < 0 {
+		// The scheduler sent it in the meantime
+		return false
+	}
+
+	g.LogInfo("Cancelled scheduled message %s", id)
+	if err := g.SaveSession(owner); err != nil {
+		g.LogWarn("Failed to save session %s: %v", owner.ID, err)
+	}
+	return true
+}
+
+// RunDueSchedules sends the messages that are due and returns how many were sent. Each template
+// is processed in its session, like a response, and the output goes to the DeliveryFunc; messages
+// whose output is empty are dropped. Nothing runs until a DeliveryFunc is set. A session answering
+// an input is waited for, so RunDueSchedules may run alongside ProcessInput.
+func (g *Golem) RunDueSchedules() int {
+	now := g.GetClock().Now()
+
+	g.sessionMutex.RLock()
+	g.scheduleMutex.Lock()
+	deliver := g.deliver
+	var sessions []*ChatSession
+	if deliver != nil {
+		for _, session := range g.sessions {
+			for _, message := range session.Schedules {
+				if !message.Due.After(now) {
+					sessions = append(sessions, session)
+					break
+				}
+			}
+		}
+	}
+	g.scheduleMutex.Unlock()
+	g.sessionMutex.RUnlock()
+
+	g.syncLanguageBots()
+	sent := 0
+	for _, session := range sessions {
+		// The due messages are taken, sent and saved in the session's turn
+		session.turn.Lock()
+		g.scheduleMutex.Lock()
+		var messages []*ScheduledMessage
+		pending := session.Schedules[:0]
+		for _, message := range session.Schedules {
+			if message.Due.After(now) {
+				pending = append(pending, message)
+			} else {
+				messages = append(messages, message)
+			}
+		}
+		session.Schedules = pending
+		g.scheduleMutex.Unlock()
+
+		sort.Slice(messages, func(i, j int) bool { return messages[i].Due.Before(messages[j].Due) })
+		// Multilingual bots answer in the session's language
+		bot := g
+		if languageBot := g.languageBots[session.Language]; languageBot != nil {
+			bot = languageBot
+		}
+		for _, message := range messages {
+			wildcards := make(map[string]string, len(message.Wildcards))
+			for key, value := range message.Wildcards {
+				wildcards[key] = value
+			}
+			response := bot.ProcessTemplateWithContext(message.Template, wildcards, session)
+			if strings.TrimSpace(response) == "" {
+				g.LogInfo("Scheduled message %s produced no output", message.ID)
+				continue
+			}
+			// The message is the bot's last word, so <that> patterns can match the reply to it
+			session.AddToThatHistory(response)
+			session.AddToResponseHistory(response)
+			session.History = append(session.History, "Golem: "+response)
+			g.LogInfo("Delivering scheduled message %s to session %s", message.ID, session.ID)
+			deliver(session, response)
+			sent++
+		}
+		if len(messages) > 0 {
+			if err := g.SaveSession(session); err != nil {
+				g.LogWarn("Failed to save session %s: %v", session.ID, err)
+			}
+		}
+		session.turn.Unlock()
+	}
+	return sent
+}
+
+// StartScheduler runs due messages every interval (a second when interval is 0) on its own
+// goroutine until StopScheduler is called. Starting a running scheduler restarts it with the new
+// interval.
+func (g *Golem) StartScheduler(interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	g.StopScheduler()
+
+	stop := make(chan struct{})
+	g.scheduleMutex.Lock()
+	g.schedulerStop = stop
+	g.scheduleMutex.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				g.RunDueSchedules()
+			}
+		}
+	}()
+	g.LogInfo("Scheduler started (every %v)", interval)
+}
+
+// StopScheduler stops the scheduler started by StartScheduler
+func (g *Golem) StopScheduler() {
+	g.scheduleMutex.Lock()
+	defer g.scheduleMutex.Unlock()
+	if g.schedulerStop != nil {
+		close(g.schedulerStop)
+		g.schedulerStop = nil
+	}
+}
+
+// AlarmHandler schedules messages from OOB requests: "ALARM 10m Time for a break" sends
+// "Time for a break" (a template) to the session in ten minutes
+type AlarmHandler struct {
+	golem *Golem
+}
+
+// CanHandle returns true for ALARM messages
+func (h *AlarmHandler) CanHandle(message string) bool {
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(message)), "ALARM")
+}
+
+// Process schedules the alarm's message
+func (h *AlarmHandler) Process(message string, session *ChatSession) (string, error) {
+	if oobMsg, isOOB := ParseOOBMessage(message); isOOB {
+		message = oobMsg.Type + " " + oobMsg.Content
+	}
+	parts := strings.Fields(message)
+	if len(parts) < 3 {
+		return "", fmt.Errorf("alarm requires a delay and a message: ALARM <delay> <message>")
+	}
+	delay, err := ParseScheduleDelay(parts[1])
+	if err != nil {
+		return "", err
+	}
+	scheduled, err := h.golem.Schedule(session, strings.Join(parts[2:], " "), delay)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Alarm %s set for %s", scheduled.ID, scheduled.Due.Format(time.RFC3339)), nil
+}
+
+// GetName returns the handler name
+func (h *AlarmHandler) GetName() string {
+	return "ALARM"
+}
+
+// GetDescription returns the handler description
+func (h *AlarmHandler) GetDescription() string {
+	return "Schedules a message: ALARM <delay> <message>"
+}
+
+// scheduleCommand handles the schedule command
+func (g *Golem) scheduleCommand(args []string) error {
+	if len(args) == 0 || args[0] != "list" {
+		return fmt.Errorf("schedule command requires a subcommand: list [--store <dir>]")
+	}
+	store := g.sessionStore
+	for i := 1; i < len(args); i++ {
+		switch {
+		case args[i] == "--store":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--store requires a directory")
+			}
+			i++
+			store = NewFileSessionStore(args[i])
+		default:
+			return fmt.Errorf("unknown schedule list option: %s", args[i])
+		}
+	}
+
+	// Open sessions first, then saved sessions that are not open
+	messages := g.ScheduledMessages()
+	if store != nil {
+		ids, err := store.List()
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			g.sessionMutex.RLock()
+			_, open := g.sessions[id]
+			g.sessionMutex.RUnlock()
+			if open {
+				continue
+			}
+			session, err := store.Load(id)
+			if err != nil {
+				g.LogWarn("Failed to read session %s: %v", id, err)
+				continue
+			}
+			for _, message := range session.Schedules {
+				messages = append(messages, *message)
+			}
+		}
+		sortScheduledMessages(messages)
+	}
+
+	if len(messages) == 0 {
+		fmt.Println("No scheduled messages")
+		return nil
+	}
+	now := g.GetClock().Now()
+	fmt.Println("Scheduled Messages:")
+	fmt.Println(strings.Repeat("=", 50))
+	for _, message := range messages {
+		when := "due now"
+		if message.Due.After(now) {
+			when = "in " + message.Due.Sub(now).Round(time.Second).String()
+		}
+		fmt.Printf("%s  %s (%s)  session %s\n  %s\n",
+			message.ID, message.Due.Format(time.RFC3339), when, message.SessionID, message.Template)
+	}
+	return nil
+}
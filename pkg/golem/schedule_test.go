@@ -0,0 +1,262 @@
+package golem
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+const scheduleTestAIML = `<aiml version="2.0">
+<category><pattern>REMIND ME TO *</pattern>
+<template>OK.<schedule in="10m">Time to <star/>! Done?</schedule></template></category>
+<category><pattern>YES</pattern><that>TIME TO *</that><template>Well done.</template></category>
+<category><pattern>*</pattern><template>Pardon?</template></category>
+</aiml>`
+
+type deliveredMessage struct {
+	sessionID string
+	message   string
+}
+
+// recordDeliveries returns a DeliveryFunc that appends the messages it receives to delivered
+func recordDeliveries(delivered *[]deliveredMessage) DeliveryFunc {
+	return func(session *ChatSession, message string) {
+		*delivered = append(*delivered, deliveredMessage{session.ID, message})
+	}
+}
+
+func TestParseScheduleDelay(t *testing.T) {
+	testCases := []struct {
+		delay    string
+		expected time.Duration
+	}{
+		{"10m", 10 * time.Minute},
+		{"1h30m", 90 * time.Minute},
+		{"2d", 48 * time.Hour},
+		{"1d12h", 36 * time.Hour},
+		{"soon", -1},
+		{"-5m", -1},
+	}
+	for _, tc := range testCases {
+		delay, err := ParseScheduleDelay(tc.delay)
+		if (err != nil) != (tc.expected < 0) || (err == nil && delay != tc.expected) {
+			t.Errorf("ParseScheduleDelay(%q) = %v, %v; expected %v", tc.delay, delay, err, tc.expected)
+		}
+	}
+}
+
+func TestScheduleTag(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	delivered := &[]deliveredMessage{}
+	g := newTestBot(t, scheduleTestAIML, WithClock(NewFixedClock(start)), WithDeliveryFunc(recordDeliveries(delivered)))
+	session := g.CreateSession("reminders")
+
+	if got := askN(t, g, session, "remind me to stretch", 1)[0]; got != "OK." {
+		t.Errorf("Expected the schedule tag to output nothing, got %q", got)
+	}
+	messages := g.ScheduledMessages()
+	if len(messages) != 1 || !messages[0].Due.Equal(start.Add(10*time.Minute)) || messages[0].SessionID != "reminders" {
+		t.Fatalf("Unexpected schedules %+v", messages)
+	}
+
+	if sent := g.RunDueSchedules(); sent != 0 {
+		t.Errorf("Expected nothing due yet, sent %d", sent)
+	}
+	g.SetClock(NewFixedClock(start.Add(10 * time.Minute)))
+	if sent := g.RunDueSchedules(); sent != 1 {
+		t.Fatalf("Expected the reminder to be sent, sent %d", sent)
+	}
+	if len(*delivered) != 1 || (*delivered)[0] != (deliveredMessage{"reminders", "Time to stretch! Done?"}) {
+		t.Errorf("Unexpected deliveries %+v", *delivered)
+	}
+	if len(g.ScheduledMessages()) != 0 {
+		t.Errorf("Expected the schedule to be removed, got %+v", g.ScheduledMessages())
+	}
+
+	// The message is the bot's last word, so <that> matches the reply to it
+	if got := askN(t, g, session, "yes", 1)[0]; got != "Well done." {
+		t.Errorf("Expected the reply to match the scheduled message, got %q", got)
+	}
+}
+
+func TestScheduleCancelAndAlarm(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	delivered := &[]deliveredMessage{}
+	g := newTestBot(t, scheduleTestAIML, WithClock(NewFixedClock(start)), WithDeliveryFunc(recordDeliveries(delivered)))
+	session := g.CreateSession("alarms")
+
+	response, err := g.oobMgr.ProcessOOB("ALARM 1h Meeting starts", session)
+	if err != nil {
+		t.Fatalf("ProcessOOB failed: %v", err)
+	}
+	if response == "" {
+		t.Error("Expected the alarm to be confirmed")
+	}
+	if _, err := g.oobMgr.ProcessOOB("ALARM later Meeting starts", session); err == nil {
+		t.Error("Expected an error for an invalid delay")
+	}
+
+	reminder, err := g.Schedule(session, "Lunch?", 30*time.Minute)
+	if err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+	if !g.CancelScheduledMessage(reminder.ID) || g.CancelScheduledMessage(reminder.ID) {
+		t.Error("Expected the message to be cancelled once")
+	}
+
+	g.SetClock(NewFixedClock(start.Add(2 * time.Hour)))
+	g.RunDueSchedules()
+	if len(*delivered) != 1 || (*delivered)[0].message != "Meeting starts" {
+		t.Errorf("Expected only the alarm to be delivered, got %+v", *delivered)
+	}
+}
+
+func TestSchedulesSurviveRestart(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	store := NewFileSessionStore(t.TempDir())
+	g := newTestBot(t, scheduleTestAIML, WithClock(NewFixedClock(start)), WithSessionStore(store))
+	session := g.CreateSession("user/42")
+	askN(t, g, session, "remind me to drink water", 1)
+
+	// A new bot restores the session, its predicates and its schedule from the store
+	delivered := &[]deliveredMessage{}
+	restarted := newTestBot(t, scheduleTestAIML, WithClock(NewFixedClock(start.Add(time.Hour))),
+		WithDeliveryFunc(recordDeliveries(delivered)), WithSessionStore(store))
+	restored, err := restarted.RestoreSessions()
+	if err != nil || restored != 1 {
+		t.Fatalf("RestoreSessions = %d, %v; expected 1 session", restored, err)
+	}
+	if sent := restarted.RunDueSchedules(); sent != 1 {
+		t.Fatalf("Expected the overdue reminder to be sent, sent %d", sent)
+	}
+	if len(*delivered) != 1 || (*delivered)[0] != (deliveredMessage{"user/42", "Time to drink water! Done?"}) {
+		t.Errorf("Unexpected deliveries %+v", *delivered)
+	}
+
+	saved, err := store.Load("user/42")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(saved.Schedules) != 0 {
+		t.Errorf("Expected the sent message to be removed from the store, got %+v", saved.Schedules)
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	delivered := make(chan string, 1)
+	g := New(false, WithDeliveryFunc(func(session *ChatSession, message string) {
+		delivered <- message
+	}))
+	session := g.CreateSession("scheduler")
+	if _, err := g.Schedule(session, "Ping", 0); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+
+	g.StartScheduler(10 * time.Millisecond)
+	defer g.StopScheduler()
+	select {
+	case message := <-delivered:
+		if message != "Ping" {
+			t.Errorf("Expected Ping, got %q", message)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the scheduler to deliver the message")
+	}
+}
+
+func TestRunDueSchedulesWaitsForInput(t *testing.T) {
+	g := newTestBot(t, scheduleTestAIML, WithDeliveryFunc(func(session *ChatSession, message string) {}))
+	session := g.CreateSession("busy")
+	const turns = 50
+	for i := 0; i < turns; i++ {
+		if _, err := g.Schedule(session, "Ping", 0); err != nil {
+			t.Fatalf("Schedule failed: %v", err)
+		}
+	}
+
+	// Scheduled messages go out while the user is typing, without interleaving with a response
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < turns; i++ {
+			if _, err := g.ProcessInput("hello", session); err != nil {
+				t.Errorf("ProcessInput failed: %v", err)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for sent := 0; sent < turns; {
+			sent += g.RunDueSchedules()
+		}
+	}()
+	wg.Wait()
+
+	if len(session.History) != 2*turns {
+		t.Errorf("Expected %d inputs and messages in the history, got %d", 2*turns, len(session.History))
+	}
+}
+
+func TestSchedulesSavedInTurn(t *testing.T) {
+	store := NewFileSessionStore(t.TempDir())
+	aiml := `<aiml version="2.0"><category><pattern>PING ME</pattern><template>OK.<schedule in="0s">Ping</schedule></template></category></aiml>`
+	g := newTestBot(t, aiml, WithSessionStore(store), WithDeliveryFunc(func(session *ChatSession, message string) {}))
+	session := g.CreateSession("saved")
+	const turns = 30
+
+	// Inputs that schedule messages, the scheduler and cancellations all change and save the session
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	sent, cancelled := 0, 0
+	done := make(chan struct{})
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		for i := 0; i < turns; i++ {
+			if _, err := g.ProcessInput("ping me", session); err != nil {
+				t.Errorf("ProcessInput failed: %v", err)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for {
+			n := g.RunDueSchedules()
+			mutex.Lock()
+			sent += n
+			finished := sent+cancelled == turns
+			mutex.Unlock()
+			if finished {
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			for _, message := range g.ScheduledMessages() {
+				if g.CancelScheduledMessage(message.ID) {
+					mutex.Lock()
+					cancelled++
+					mutex.Unlock()
+				}
+			}
+		}
+	}()
+	wg.Wait()
+
+	saved, err := store.Load("saved")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if sent+cancelled != turns || len(saved.Schedules) != 0 {
+		t.Errorf("Expected every message sent or cancelled and none saved, got %d sent, %d cancelled, %d saved", sent, cancelled, len(saved.Schedules))
+	}
+}
@@ -0,0 +1,340 @@
+package golem
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SemanticResourceMode decides how loaded synonym, antonym and domain files combine with the
+// built-in English tables of the semantic matcher
+type SemanticResourceMode int
+
+const (
+	// SemanticResourcesMerge adds loaded entries to the built-in tables (default)
+	SemanticResourcesMerge SemanticResourceMode = iota
+	// SemanticResourcesReplace drops a built-in table once files of that kind are loaded
+	SemanticResourcesReplace
+)
+
+// String returns the mode name as accepted by ParseSemanticResourceMode
+func (m SemanticResourceMode) String() string {
+	if m == SemanticResourcesReplace {
+		return "replace"
+	}
+	return "merge"
+}
+
+// ParseSemanticResourceMode parses a mode name (merge or replace)
+func ParseSemanticResourceMode(name string) (SemanticResourceMode, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "merge", "":
+		return SemanticResourcesMerge, nil
+	case "replace":
+		return SemanticResourcesReplace, nil
+	default:
+		return SemanticResourcesMerge, fmt.Errorf("unknown semantic resource mode: %s (expected merge or replace)", name)
+	}
+}
+
+// semanticResourceKey identifies the resources a semantic matcher was built from
+type semanticResourceKey struct {
+	kb       *AIMLKnowledgeBase
+	synonyms int
+	antonyms int
+	domains  int
+	mode     SemanticResourceMode
+}
+
+// WithSemanticResourceMode sets how loaded synonym, antonym and domain files combine with the built-in tables
+func WithSemanticResourceMode(mode SemanticResourceMode) Option {
+	return func(g *Golem) {
+		g.SetSemanticResourceMode(mode)
+	}
+}
+
+// SetSemanticResourceMode sets how loaded synonym, antonym and domain files combine with the built-in tables
+func (g *Golem) SetSemanticResourceMode(mode SemanticResourceMode) {
+	g.semanticResourceMode = mode
+}
+
+// GetSemanticResourceMode returns how loaded synonym, antonym and domain files combine with the built-in tables
+func (g *Golem) GetSemanticResourceMode() SemanticResourceMode {
+	return g.semanticResourceMode
+}
+
+// WithSynonymExpansion replaces input words no pattern uses with a synonym a pattern does use before matching
+func WithSynonymExpansion() Option {
+	return func(g *Golem) {
+		g.SetSynonymExpansion(true)
+	}
+}
+
+// SetSynonymExpansion enables or disables the synonym-expansion stage for user input
+func (g *Golem) SetSynonymExpansion(enabled bool) {
+	g.synonymExpansion = enabled
+}
+
+// IsSynonymExpansionEnabled reports whether user input goes through synonym expansion
+func (g *Golem) IsSynonymExpansionEnabled() bool {
+	return g.synonymExpansion
+}
+
+// semanticContextMatcher returns the semantic matcher for the current knowledge base, rebuilding it
+// from the built-in tables and the loaded resource files after loads. A matcher assigned directly
+// is kept as it is.
+func (g *Golem) semanticContextMatcher() *SemanticContextMatcher {
+	key := semanticResourceKey{kb: g.aimlKB, mode: g.semanticResourceMode}
+	if g.aimlKB != nil {
+		key.synonyms, key.antonyms, key.domains = len(g.aimlKB.Synonyms), len(g.aimlKB.Antonyms), len(g.aimlKB.Domains)
+	}
+	if g.semanticMatcher != nil && (g.semanticKey == nil || *g.semanticKey == key) {
+		return g.semanticMatcher
+	}
+
+	matcher := NewSemanticContextMatcher()
+	replace := g.semanticResourceMode == SemanticResourcesReplace
+	if !replace || key.synonyms == 0 {
+		matcher.InitializeSynonyms()
+	}
+	if !replace || key.domains == 0 {
+		matcher.InitializeDomainMappings()
+	}
+	// Sorted so that the order of each word's synonyms, which expansion picks from, is stable
+	if kb := g.aimlKB; kb != nil {
+		for _, word := range sortedRelationKeys(kb.Synonyms) {
+			matcher.AddSynonyms(word, kb.Synonyms[word]...)
+		}
+		for _, word := range sortedRelationKeys(kb.Antonyms) {
+			matcher.AddAntonyms(word, kb.Antonyms[word]...)
+		}
+		for name, words := range kb.Domains {
+			matcher.AddDomain(name, words...)
+		}
+	}
+	g.semanticMatcher = matcher
+	g.semanticKey = &key
+	return matcher
+}
+
+// sortedRelationKeys returns the words of a relation table in order
+func sortedRelationKeys(relations map[string][]string) []string {
+	words := make([]string, 0, len(relations))
+	for word := range relations {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
+}
+
+// appendRelatedWords adds the trimmed, non-empty words to related, skipping ones already listed
+func appendRelatedWords(related []string, words []string) []string {
+	for _, word := range words {
+		word = strings.TrimSpace(word)
+		if word != "" && !containsFold(related, word) {
+			related = append(related, word)
+		}
+	}
+	return related
+}
+
+// containsFold reports whether words contains word, ignoring case
+func containsFold(words []string, word string) bool {
+	for _, w := range words {
+		if strings.EqualFold(w, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadWordRelationsFromFile loads a .synonyms or .antonyms file. JSON files hold arrays whose first
+// word is related to the rest ([["claim","demand","request"]]); text lines hold claim: demand, request.
+// Words are keyed in lower case.
+func (g *Golem) LoadWordRelationsFromFile(filename string) (map[string][]string, error) {
+	g.LogInfo("Loading word relation file: %s", filename)
+
+	// Read the file content
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read word relation file %s: %v", filename, err)
+	}
+
+	var relations map[string][]string
+	if format := detectResourceFormat(filename, content); format != ResourceFormatJSON {
+		relations, err = parseResourceRelations(filename, content, format)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var groups [][]string
+		if err := json.Unmarshal(content, &groups); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON in word relation file %s: %v", filename, err)
+		}
+		relations = make(map[string][]string)
+		for _, group := range groups {
+			if len(group) < 2 || strings.TrimSpace(group[0]) == "" {
+				g.LogWarn("Skipping word relation group with fewer than two words in %s: %v", filename, group)
+				continue
+			}
+			relations[strings.TrimSpace(group[0])] = appendRelatedWords(relations[strings.TrimSpace(group[0])], group[1:])
+		}
+	}
+
+	result := make(map[string][]string, len(relations))
+	for word, related := range relations {
+		key := strings.ToLower(word)
+		result[key] = appendRelatedWords(result[key], related)
+	}
+	g.LogInfo("Loaded %d word relations from %s", len(result), filename)
+	return result, nil
+}
+
+// findResourceFiles lists the files under dirPath that match accepts
+func findResourceFiles(dirPath string, match func(path string) bool) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dirPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && match(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory %s: %v", dirPath, err)
+	}
+	return files, nil
+}
+
+// resourceKind returns a findResourceFiles predicate for resource files of the given kind (".synonyms", ...)
+func resourceKind(kind string) func(path string) bool {
+	return func(path string) bool {
+		return isResourceFile(path, kind)
+	}
+}
+
+// loadWordRelationsFromDirectory merges all word relation files of the given kind under dirPath
+func (g *Golem) loadWordRelationsFromDirectory(dirPath, kind string) (map[string][]string, error) {
+	files, err := findResourceFiles(dirPath, resourceKind(kind))
+	if err != nil {
+		return nil, err
+	}
+	allRelations := make(map[string][]string)
+	for _, file := range files {
+		relations, err := g.LoadWordRelationsFromFile(file)
+		if err != nil {
+			// Log the error but continue with other files
+			g.LogWarn("Failed to load %s: %v", file, err)
+			continue
+		}
+		mergeWordRelations(allRelations, relations)
+	}
+	return allRelations, nil
+}
+
+// LoadSynonymsFromDirectory loads and merges all .synonyms files from a directory
+func (g *Golem) LoadSynonymsFromDirectory(dirPath string) (map[string][]string, error) {
+	return g.loadWordRelationsFromDirectory(dirPath, ".synonyms")
+}
+
+// LoadAntonymsFromDirectory loads and merges all .antonyms files from a directory
+func (g *Golem) LoadAntonymsFromDirectory(dirPath string) (map[string][]string, error) {
+	return g.loadWordRelationsFromDirectory(dirPath, ".antonyms")
+}
+
+// LoadDomainsFromDirectory loads all .domain files from a directory. Each file lists the words of
+// one domain in the set file format and is named after it (insurance.domain).
+func (g *Golem) LoadDomainsFromDirectory(dirPath string) (map[string][]string, error) {
+	files, err := findResourceFiles(dirPath, resourceKind(".domain"))
+	if err != nil {
+		return nil, err
+	}
+	allDomains := make(map[string][]string)
+	for _, file := range files {
+		words, err := g.LoadSetFromFile(file)
+		if err != nil {
+			g.LogWarn("Failed to load %s: %v", file, err)
+			continue
+		}
+		name, _ := resourceName(file, ".domain")
+		name = strings.ToUpper(name)
+		for _, word := range words {
+			allDomains[name] = appendRelatedWords(allDomains[name], []string{strings.ToUpper(word)})
+		}
+	}
+	return allDomains, nil
+}
+
+// semanticMapLookup serves the synonym, antonym and domain maps from the semantic matcher
+func (g *Golem) semanticMapLookup(name, key string) (string, bool) {
+	matcher := g.semanticContextMatcher()
+	var related []string
+	switch strings.ToLower(name) {
+	case "synonym":
+		related = matcher.SynonymsOf(key)
+	case "antonym":
+		related = matcher.AntonymsOf(key)
+	case "domain":
+		if domain := matcher.DomainOf(key); domain != "" {
+			related = []string{domain}
+		}
+	}
+	if len(related) == 0 {
+		return "", false
+	}
+	return matchWordCase(key, strings.ToLower(related[0])), true
+}
+
+// expandSynonyms replaces input words that no pattern or set uses with their first synonym that one
+// does, keeping punctuation and the case of the word as typed
+func (g *Golem) expandSynonyms(input string) (string, []WordCorrection) {
+	if !g.synonymExpansion || g.aimlKB == nil {
+		return input, nil
+	}
+	vocabulary := g.spellingVocabulary()
+	matcher := g.semanticContextMatcher()
+	known := func(phrase string) bool {
+		words := strings.Fields(phrase)
+		for _, word := range words {
+			if !vocabulary.words[g.vocabularyKey(word)] {
+				return false
+			}
+		}
+		return len(words) > 0
+	}
+
+	var expansions []WordCorrection
+	tokens := strings.Fields(input)
+	for i, token := range tokens {
+		prefix, word, suffix := splitTokenWord(token)
+		if word == "" || known(word) {
+			continue
+		}
+		for _, synonym := range matcher.SynonymsOf(word) {
+			if !known(synonym) {
+				continue
+			}
+			replacement := matchWordCase(word, strings.ToLower(synonym))
+			tokens[i] = prefix + replacement + suffix
+			expansions = append(expansions, WordCorrection{Original: word, Corrected: replacement})
+			break
+		}
+	}
+	if len(expansions) == 0 {
+		return input, nil
+	}
+	g.LogInfo("Synonym expansions: %v", expansions)
+	return strings.Join(tokens, " "), expansions
+}
+
+// mergeWordRelations adds the related words of source to target
+func mergeWordRelations(target, source map[string][]string) {
+	for word, related := range source {
+		target[word] = appendRelatedWords(target[word], related)
+	}
+}
@@ -0,0 +1,140 @@
+package golem
+
+import (
+	"reflect"
+	"testing"
+)
+
+const semanticResourcesTestAIML = `<aiml version="2.0">
+<category><pattern>I WANT TO FILE A CLAIM</pattern><template>Let's start your claim.</template></category>
+<category><pattern>WHAT IS A PREMIUM</pattern><template>The price of your policy.</template></category>
+<category><pattern>SYNONYM OF *</pattern><template><map name="synonym"><star/></map></template></category>
+<category><pattern>ANTONYM OF *</pattern><template><map name="antonym"><star/></map></template></category>
+<category><pattern>DOMAIN OF *</pattern><template><map name="domain"><star/></map></template></category>
+<category><pattern>*</pattern><template>Pardon?</template></category>
+</aiml>`
+
+func newSemanticResourcesTestBot(t *testing.T, options ...Option) *Golem {
+	t.Helper()
+	dir := t.TempDir()
+	writeResourceFile(t, dir, "insurance.aiml", semanticResourcesTestAIML)
+	writeResourceFile(t, dir, "insurance.synonyms", "# insurance terms\nclaim: request, demand\npremium: Fee\n")
+	writeResourceFile(t, dir, "extra.synonyms.csv", "policy,coverage,plan\n")
+	writeResourceFile(t, dir, "insurance.antonyms", `[["insured", "uninsured"]]`)
+	writeResourceFile(t, dir, "perils.domain", "flood\nfire\nhail\n")
+
+	g := New(false, options...)
+	kb, err := g.LoadAIMLFromDirectory(dir)
+	if err != nil {
+		t.Fatalf("LoadAIMLFromDirectory failed: %v", err)
+	}
+	g.aimlKB = kb
+	return g
+}
+
+func TestLoadSemanticResources(t *testing.T) {
+	g := newSemanticResourcesTestBot(t)
+	kb := g.aimlKB
+
+	if got := kb.Synonyms["claim"]; !reflect.DeepEqual(got, []string{"request", "demand"}) {
+		t.Errorf("Expected claim synonyms from text file, got %v", got)
+	}
+	if got := kb.Synonyms["policy"]; !reflect.DeepEqual(got, []string{"coverage", "plan"}) {
+		t.Errorf("Expected policy synonyms from CSV file, got %v", got)
+	}
+	if got := kb.Antonyms["insured"]; !reflect.DeepEqual(got, []string{"uninsured"}) {
+		t.Errorf("Expected insured antonyms from JSON file, got %v", got)
+	}
+	if got := kb.Domains["PERILS"]; !reflect.DeepEqual(got, []string{"FLOOD", "FIRE", "HAIL"}) {
+		t.Errorf("Expected perils domain, got %v", got)
+	}
+
+	// Relations work in both directions and reach the that-context matcher
+	matcher := g.semanticContextMatcher()
+	if got := matcher.SynonymsOf("fee"); !reflect.DeepEqual(got, []string{"PREMIUM"}) {
+		t.Errorf("Expected reverse synonym, got %v", got)
+	}
+	if matched, score := matcher.MatchWithSemanticSimilarity("file a request", "file a claim"); !matched || score < 0.9 {
+		t.Errorf("Expected loaded synonyms in semantic matching, got %v (%.2f)", matched, score)
+	}
+}
+
+func TestSemanticResourceMaps(t *testing.T) {
+	g := newSemanticResourcesTestBot(t)
+	session := g.CreateSession("semantic-maps")
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"synonym of claim", "request"},
+		{"synonym of Uninsured", "Uninsured"},
+		{"antonym of Uninsured", "Insured"},
+		{"domain of hail", "perils"},
+		// Built-in tables are kept in merge mode
+		{"synonym of happy", "glad"},
+		{"domain of crimson", "colors"},
+	}
+	for _, tc := range testCases {
+		if got := askN(t, g, session, tc.input, 1)[0]; got != tc.expected {
+			t.Errorf("%q: got %q, expected %q", tc.input, got, tc.expected)
+		}
+	}
+
+	// Replace mode drops a built-in table once files of that kind are loaded
+	g.SetSemanticResourceMode(SemanticResourcesReplace)
+	matcher := g.semanticContextMatcher()
+	if got := matcher.SynonymsOf("happy"); len(got) != 0 {
+		t.Errorf("Expected no built-in synonyms in replace mode, got %v", got)
+	}
+	if got := matcher.DomainOf("crimson"); got != "" {
+		t.Errorf("Expected no built-in domains in replace mode, got %q", got)
+	}
+	if got := matcher.SynonymsOf("claim"); !reflect.DeepEqual(got, []string{"REQUEST", "DEMAND"}) {
+		t.Errorf("Expected loaded synonyms in replace mode, got %v", got)
+	}
+}
+
+func TestParseSemanticResourceMode(t *testing.T) {
+	for _, name := range []string{"merge", "replace"} {
+		mode, err := ParseSemanticResourceMode(name)
+		if err != nil || mode.String() != name {
+			t.Errorf("ParseSemanticResourceMode(%q) = %v, %v", name, mode, err)
+		}
+	}
+	if _, err := ParseSemanticResourceMode("append"); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
+
+func TestSynonymExpansion(t *testing.T) {
+	g := newSemanticResourcesTestBot(t, WithSynonymExpansion())
+	session := g.CreateSession("synonym-expansion")
+
+	response, metadata, err := g.ProcessInputWithMetadata("I want to file a Request!", session)
+	if err != nil {
+		t.Fatalf("ProcessInputWithMetadata failed: %v", err)
+	}
+	if response != "Let's start your claim." {
+		t.Errorf("Expected the claim category, got %q", response)
+	}
+	if expected := []WordCorrection{{Original: "Request", Corrected: "Claim"}}; !reflect.DeepEqual(metadata.Synonyms, expected) {
+		t.Errorf("Expected synonyms %v, got %v", expected, metadata.Synonyms)
+	}
+	if metadata.MatchedInput != "I want to file a Claim!" {
+		t.Errorf("Expected matched input with the synonym, got %q", metadata.MatchedInput)
+	}
+
+	// Words a pattern uses are left alone
+	if got := askN(t, g, session, "what is a premium", 1)[0]; got != "The price of your policy." {
+		t.Errorf("Expected exact match, got %q", got)
+	}
+	if got := askN(t, g, session, "what is a fee", 1)[0]; got != "The price of your policy." {
+		t.Errorf("Expected expansion of fee, got %q", got)
+	}
+
+	g.SetSynonymExpansion(false)
+	if got := askN(t, g, session, "I want to file a request", 1)[0]; got != "Pardon?" {
+		t.Errorf("Expected no expansion when disabled, got %q", got)
+	}
+}
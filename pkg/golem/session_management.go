@@ -20,7 +20,7 @@ func NewSessionManagement(golem *Golem) *SessionManagement {
 
 // NewChatSession creates a new chat session
 func (sm *SessionManagement) NewChatSession(id string) *ChatSession {
-	now := time.Now().Format(time.RFC3339)
+	now := sm.golem.now().Format(time.RFC3339)
 	return &ChatSession{
 		ID:                id,
 		CreatedAt:         now,
@@ -57,7 +57,7 @@ func (sm *SessionManagement) getDefaultContextConfig() *ContextConfig {
 // SetSessionTopic sets the topic for a session
 func (sm *SessionManagement) SetSessionTopic(session *ChatSession, topic string) {
 	session.Topic = topic
-	session.LastActivity = time.Now().Format(time.RFC3339)
+	session.LastActivity = sm.golem.now().Format(time.RFC3339)
 }
 
 // GetSessionTopic gets the topic for a session
@@ -79,7 +79,7 @@ func (sm *SessionManagement) AddToThatHistory(session *ChatSession, response str
 		session.ThatHistory = session.ThatHistory[1:]
 	}
 
-	session.LastActivity = time.Now().Format(time.RFC3339)
+	session.LastActivity = sm.golem.now().Format(time.RFC3339)
 }
 
 // GetLastThat gets the last response from that history
@@ -111,7 +111,7 @@ func (sm *SessionManagement) AddToRequestHistory(session *ChatSession, request s
 		session.RequestHistory = session.RequestHistory[1:]
 	}
 
-	session.LastActivity = time.Now().Format(time.RFC3339)
+	session.LastActivity = sm.golem.now().Format(time.RFC3339)
 }
 
 // GetRequestHistory gets the request history
@@ -143,7 +143,7 @@ func (sm *SessionManagement) AddToResponseHistory(session *ChatSession, response
 		session.ResponseHistory = session.ResponseHistory[1:]
 	}
 
-	session.LastActivity = time.Now().Format(time.RFC3339)
+	session.LastActivity = sm.golem.now().Format(time.RFC3339)
 }
 
 // GetResponseHistory gets the response history
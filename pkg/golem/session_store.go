@@ -0,0 +1,167 @@
+package golem
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SessionStore saves chat sessions so they outlive the process
+type SessionStore interface {
+	// Save writes the session, replacing an earlier copy with the same ID
+	Save(session *ChatSession) error
+	// Load reads the session with the given ID
+	Load(id string) (*ChatSession, error)
+	// List returns the IDs of the saved sessions
+	List() ([]string, error)
+	// Delete removes the saved session with the given ID
+	Delete(id string) error
+}
+
+// FileSessionStore saves each session as a JSON file in a directory
+type FileSessionStore struct {
+	Dir string
+}
+
+// NewFileSessionStore creates a session store writing to dir, created on the first save
+func NewFileSessionStore(dir string) *FileSessionStore {
+	return &FileSessionStore{Dir: dir}
+}
+
+// path returns the file of a session; IDs are escaped so any ID is a valid file name
+func (s *FileSessionStore) path(id string) string {
+	return filepath.Join(s.Dir, url.PathEscape(id)+".json")
+}
+
+// Save writes the session to a temporary file and renames it, so a crash never leaves half a session
+func (s *FileSessionStore) Save(session *ChatSession) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create session directory: %v", err)
+	}
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session %s: %v", session.ID, err)
+	}
+	filename := s.path(session.ID)
+	tempFile := filename + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write session %s: %v", session.ID, err)
+	}
+	if err := os.Rename(tempFile, filename); err != nil {
+		os.Remove(tempFile) // Clean up temp file
+		return fmt.Errorf("failed to rename temporary file: %v", err)
+	}
+	return nil
+}
+
+// Load reads a saved session
+func (s *FileSessionStore) Load(id string) (*ChatSession, error) {
+	data, err := os.ReadFile(s.path(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session %s: %v", id, err)
+	}
+	var session ChatSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to parse session %s: %v", id, err)
+	}
+	if session.Variables == nil {
+		session.Variables = make(map[string]string)
+	}
+	return &session, nil
+}
+
+// List returns the IDs of the sessions in the directory, sorted; a missing directory has none
+func (s *FileSessionStore) List() ([]string, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session directory: %v", err)
+	}
+	var ids []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		id, err := url.PathUnescape(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Delete removes a saved session; deleting a session that was never saved is not an error
+func (s *FileSessionStore) Delete(id string) error {
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete session %s: %v", id, err)
+	}
+	return nil
+}
+
+// WithSessionStore saves sessions with scheduled messages to store (see RestoreSessions)
+func WithSessionStore(store SessionStore) Option {
+	return func(g *Golem) {
+		g.SetSessionStore(store)
+	}
+}
+
+// SetSessionStore sets the store sessions are saved to; nil keeps sessions in memory only
+func (g *Golem) SetSessionStore(store SessionStore) {
+	g.sessionStore = store
+}
+
+// GetSessionStore returns the session store, nil when none is set
+func (g *Golem) GetSessionStore() SessionStore {
+	return g.sessionStore
+}
+
+// SaveSession writes the session to the session store, if one is set
+func (g *Golem) SaveSession(session *ChatSession) error {
+	if g.sessionStore == nil || session == nil {
+		return nil
+	}
+	return g.sessionStore.Save(session)
+}
+
+// RestoreSessions loads the saved sessions into the bot, keeping sessions that are already open,
+// and returns how many were restored. The current session is left unchanged.
+func (g *Golem) RestoreSessions() (int, error) {
+	if g.sessionStore == nil {
+		return 0, nil
+	}
+	ids, err := g.sessionStore.List()
+	if err != nil {
+		return 0, err
+	}
+
+	restored := 0
+	for _, id := range ids {
+		g.sessionMutex.RLock()
+		_, open := g.sessions[id]
+		g.sessionMutex.RUnlock()
+		if open {
+			continue
+		}
+		session, err := g.sessionStore.Load(id)
+		if err != nil {
+			// Log the error but continue with other sessions
+			g.LogWarn("Failed to restore session %s: %v", id, err)
+			continue
+		}
+		g.sessionMutex.Lock()
+		g.sessions[id] = session
+		g.sessionMutex.Unlock()
+		restored++
+	}
+	g.LogInfo("Restored %d sessions", restored)
+	return restored, nil
+}
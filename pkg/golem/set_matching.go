@@ -0,0 +1,145 @@
+package golem
+
+import (
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// setGroupPrefix starts the regex group name of a set whose members are listed in the regex
+const setGroupPrefix = "set_"
+
+// setPlaceholderRegex finds the placeholders left by setPlaceholder
+var setPlaceholderRegex = regexp.MustCompile("\uE000([^\uE001]+)\uE001")
+
+// setPlaceholder stands in for a set while a pattern is turned into a regex, so the set regex is
+// not escaped like literal pattern text. The name is hex encoded so characters such as '_' are
+// not read as wildcards.
+func setPlaceholder(name string) string {
+	return "\uE000" + hex.EncodeToString([]byte(strings.ToUpper(name))) + "\uE001"
+}
+
+// hasSet reports whether a pattern set resolves to a provider, loaded or built-in set
+func (g *Golem) hasSet(kb *AIMLKnowledgeBase, name string) bool {
+	if g.setProvider(name) != nil {
+		return true
+	}
+	if kb != nil && len(kb.Sets[name]) > 0 {
+		return true
+	}
+	_, exists := builtinSetRegex(name)
+	return exists
+}
+
+// expandSetPlaceholders replaces set placeholders with set regexes. Providers come first, then
+// loaded sets, then built-in sets.
+func (g *Golem) expandSetPlaceholders(regex string, kb *AIMLKnowledgeBase) string {
+	if !strings.ContainsRune(regex, '\uE000') {
+		return regex
+	}
+	return setPlaceholderRegex.ReplaceAllStringFunc(regex, func(placeholder string) string {
+		decoded, err := hex.DecodeString(strings.Trim(placeholder, "\uE000\uE001"))
+		if err != nil {
+			return placeholder
+		}
+		name := string(decoded)
+		if setRegex, ok := g.providerSetRegex(name); ok {
+			return setRegex
+		}
+		if setRegex := g.loadedSetRegex(kb, name); setRegex != "" {
+			return setRegex
+		}
+		if setRegex, ok := builtinSetRegex(name); ok {
+			return setRegex
+		}
+		return "([^\\s]*)"
+	})
+}
+
+// loadedSetRegex returns the regex of a loaded set, using the pattern matching cache when available
+func (g *Golem) loadedSetRegex(kb *AIMLKnowledgeBase, name string) string {
+	if kb == nil || len(kb.Sets[name]) == 0 {
+		return ""
+	}
+	if g != nil && g.patternMatchingCache != nil {
+		if regex, found := g.patternMatchingCache.GetSetRegex(name, kb.Sets[name]); found {
+			return regex
+		}
+	}
+	regex := setMemberRegex(name, kb.Sets[name], g.prepareForMatching)
+	if regex != "" && g != nil && g.patternMatchingCache != nil {
+		g.patternMatchingCache.SetSetRegex(name, kb.Sets[name], regex)
+	}
+	return regex
+}
+
+// setMemberRegex builds a named capture group matching any member of a set. Members are
+// normalized like user input, so "St. Louis" matches "st louis", and longer members come first
+// so "NEW YORK CITY" wins over "NEW YORK" when both fit.
+func setMemberRegex(name string, members []string, prepare func(string) string) string {
+	seen := make(map[string]bool, len(members))
+	normalized := make([]string, 0, len(members))
+	for _, member := range members {
+		member = NormalizePattern(prepare(member))
+		if member == "" || seen[member] {
+			continue
+		}
+		seen[member] = true
+		normalized = append(normalized, member)
+	}
+	if len(normalized) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(normalized, func(i, j int) bool {
+		wordsI, wordsJ := strings.Count(normalized[i], " "), strings.Count(normalized[j], " ")
+		if wordsI != wordsJ {
+			return wordsI > wordsJ
+		}
+		return len(normalized[i]) > len(normalized[j])
+	})
+	alternatives := make([]string, len(normalized))
+	for i, member := range normalized {
+		alternatives[i] = regexp.QuoteMeta(member)
+	}
+	return "(?P<" + setGroupPrefix + hex.EncodeToString([]byte(strings.ToUpper(name))) + ">" + strings.Join(alternatives, "|") + ")"
+}
+
+// restoreCaptureText replaces captures with the same words from the user's original input, so
+// <star/> keeps "New York" or "café" rather than "NEW YORK" or "CAFE". Set captures are always
+// replaced; wildcards only when they still hold the normalized text.
+func restoreCaptureText(re *regexp.Regexp, normalizedInput, originalInput string, wildcards map[string]string, g *Golem) {
+	indexes := re.FindStringSubmatchIndex(normalizedInput)
+	if indexes == nil {
+		return
+	}
+	originalWords := strings.Fields(g.segmentText(NormalizeForMatchingCasePreserving(originalInput)))
+	if len(originalWords) != len(strings.Fields(normalizedInput)) {
+		return
+	}
+
+	for i, groupName := range re.SubexpNames() {
+		if i == 0 || indexes[2*i] < 0 {
+			continue
+		}
+		start, end := indexes[2*i], indexes[2*i+1]
+		captured := normalizedInput[start:end]
+		key := "star" + strconv.Itoa(i)
+		isSet := strings.HasPrefix(groupName, setGroupPrefix) || strings.HasPrefix(groupName, providerSetGroupPrefix)
+		if !isSet && wildcards[key] != captured {
+			continue
+		}
+
+		first := len(strings.Fields(normalizedInput[:start]))
+		count := len(strings.Fields(captured))
+		if count == 0 || first+count > len(originalWords) {
+			continue
+		}
+		original := originalWords[first : first+count]
+		if NormalizePattern(g.prepareForMatching(strings.Join(original, " "))) == NormalizePattern(captured) {
+			wildcards[key] = g.joinCapture(original)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package golem
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMultiWordSetMembers(t *testing.T) {
+	dir := writeReplayBot(t, `<category><pattern>I LIVE IN <set>cities</set></pattern><template>[<star/>]</template></category>
+<category><pattern>I LIKE <set>flavors</set> *</pattern><template>[<star/>] [<star index="2"/>]</template></category>
+<category><pattern>* IS IN <set>cities</set></pattern><template>[<star/>] [<star index="2"/>]</template></category>
+<category><pattern>I LIVE IN *</pattern><template>Never heard of <star/>.</template></category>`)
+	// Shorter members are listed first on purpose
+	writeResourceFile(t, dir, "cities.set", "york\nnew york\nnew york city\nst. louis\n")
+	writeResourceFile(t, dir, "flavors.set", "ice\nice cream\nvanilla\n")
+	g := loadReplayBot(t, dir)
+	session := g.CreateSession("multiword")
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"I live in New York City.", "[New York City]"},
+		{"i live in new YORK", "[new YORK]"},
+		{"I live in York", "[York]"},
+		// Punctuation is normalized on both sides
+		{"I live in St. Louis!", "[St Louis]"},
+		{"I live in st louis", "[st louis]"},
+		// The longest member is captured before the wildcard
+		{"I like Ice Cream sundaes", "[Ice Cream] [sundaes]"},
+		{"I like ice, thanks", "[ice] [thanks]"},
+		{"The Empire State Building is in New York City", "[The Empire State Building] [New York City]"},
+		{"I live in New Jersey", "Never heard of New Jersey."},
+	}
+	for _, tc := range testCases {
+		if got := askN(t, g, session, tc.input, 1)[0]; got != tc.expected {
+			t.Errorf("%q: got %q, expected %q", tc.input, got, tc.expected)
+		}
+	}
+}
+
+func TestMatcherCapturesWholeSetMember(t *testing.T) {
+	kb := NewAIMLKnowledgeBase()
+	kb.Sets["CITIES"] = []string{"YORK", "NEW YORK", "NEW YORK CITY"}
+
+	original := "Flights to New York City, please"
+	matched, wildcards := matchPatternWithWildcardsAndSetsCasePreserving(NormalizePattern(original), original, "FLIGHTS TO <set>cities</set> *", kb)
+	if !matched {
+		t.Fatal("Expected the pattern to match")
+	}
+	if wildcards["star1"] != "New York City" || !strings.EqualFold(wildcards["star2"], "please") {
+		t.Errorf("Expected the whole member in its original case, got %v", wildcards)
+	}
+}
@@ -0,0 +1,283 @@
+package golem
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SpellingCorrection configures the pre-pass that corrects misspelled input words to words
+// the bot's patterns and sets know before matching
+type SpellingCorrection struct {
+	// MaxDistance is the largest edit distance corrected (default 2). Words under seven letters
+	// are corrected by at most one edit.
+	MaxDistance int
+	// MinWordLength is the length below which words are never corrected (default 4)
+	MinWordLength int
+}
+
+// WordCorrection is one input word the spelling pre-pass replaced
+type WordCorrection struct {
+	Original  string `json:"original"`
+	Corrected string `json:"corrected"`
+	Distance  int    `json:"distance"`
+}
+
+// spellingVocabulary holds the words of the patterns and set members of one knowledge base
+type spellingVocabulary struct {
+	kb         *AIMLKnowledgeBase
+	categories int
+	sets       int
+	words      map[string]bool
+	byLength   map[int][]string
+}
+
+// WithSpellingCorrection corrects misspelled input words before matching
+func WithSpellingCorrection(correction SpellingCorrection) Option {
+	return func(g *Golem) {
+		g.SetSpellingCorrection(&correction)
+	}
+}
+
+// SetSpellingCorrection enables the spelling pre-pass; nil disables it
+func (g *Golem) SetSpellingCorrection(correction *SpellingCorrection) {
+	if correction != nil {
+		settings := *correction
+		if settings.MaxDistance <= 0 {
+			settings.MaxDistance = 2
+		}
+		if settings.MinWordLength <= 0 {
+			settings.MinWordLength = 4
+		}
+		correction = &settings
+	}
+	g.spellingCorrection = correction
+}
+
+// GetSpellingCorrection returns the spelling pre-pass settings, nil when it is disabled
+func (g *Golem) GetSpellingCorrection() *SpellingCorrection {
+	return g.spellingCorrection
+}
+
+// vocabularyKey turns a word into the form stored in the vocabulary
+func (g *Golem) vocabularyKey(word string) string {
+	return strings.ToUpper(g.normalizeUnicode(word))
+}
+
+// isVocabularyWord reports whether a pattern or set word is a plain word worth adding to the vocabulary
+func isVocabularyWord(word string) bool {
+	for _, r := range word {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return word != ""
+}
+
+// spellingVocabulary returns the vocabulary of the current knowledge base, rebuilding it after loads
+func (g *Golem) spellingVocabulary() *spellingVocabulary {
+	kb := g.aimlKB
+	if v := g.vocabulary; v != nil && v.kb == kb && v.categories == len(kb.Categories) && v.sets == len(kb.Sets) {
+		return v
+	}
+
+	v := &spellingVocabulary{kb: kb, categories: len(kb.Categories), sets: len(kb.Sets), words: make(map[string]bool), byLength: make(map[int][]string)}
+	add := func(text string) {
+		for _, word := range strings.Fields(text) {
+			key := g.vocabularyKey(word)
+			if isVocabularyWord(key) && !v.words[key] {
+				v.words[key] = true
+				length := utf8.RuneCountInString(key)
+				v.byLength[length] = append(v.byLength[length], key)
+			}
+		}
+	}
+	for _, category := range kb.Categories {
+		add(patternMarkupRegex.ReplaceAllString(category.Pattern, " "))
+	}
+	for _, members := range kb.Sets {
+		for _, member := range members {
+			add(member)
+		}
+	}
+	for length := range v.byLength {
+		sort.Strings(v.byLength[length])
+	}
+	g.vocabulary = v
+	return v
+}
+
+// splitTokenWord splits a whitespace-separated token into leading punctuation, the word and
+// trailing punctuation; word is empty when the token has no word characters
+func splitTokenWord(token string) (string, string, string) {
+	start := strings.IndexFunc(token, isSubstitutionWordRune)
+	if start < 0 {
+		return token, "", ""
+	}
+	end := strings.LastIndexFunc(token, isSubstitutionWordRune)
+	_, size := utf8.DecodeRuneInString(token[end:])
+	return token[:start], token[start : end+size], token[end+size:]
+}
+
+// correctSpelling replaces misspelled words of input with the closest vocabulary word, keeping
+// punctuation and the case of the word as typed
+func (g *Golem) correctSpelling(input string) (string, []WordCorrection) {
+	if g.spellingCorrection == nil || g.aimlKB == nil {
+		return input, nil
+	}
+	vocabulary := g.spellingVocabulary()
+	if len(vocabulary.words) == 0 {
+		return input, nil
+	}
+
+	var corrections []WordCorrection
+	tokens := strings.Fields(input)
+	for i, token := range tokens {
+		prefix, word, suffix := splitTokenWord(token)
+		key := g.vocabularyKey(word)
+		if !isVocabularyWord(key) || vocabulary.words[key] || utf8.RuneCountInString(key) < g.spellingCorrection.MinWordLength {
+			continue
+		}
+
+		corrected, distance := g.closestVocabularyWord(key, vocabulary)
+		if corrected == "" {
+			continue
+		}
+		replacement := matchWordCase(word, strings.ToLower(corrected))
+		tokens[i] = prefix + replacement + suffix
+		corrections = append(corrections, WordCorrection{Original: word, Corrected: replacement, Distance: distance})
+	}
+	if len(corrections) == 0 {
+		return input, nil
+	}
+	g.LogInfo("Spelling corrections: %v", corrections)
+	return strings.Join(tokens, " "), corrections
+}
+
+// patternSetNames finds the set names of a pattern
+var patternSetNames = regexp.MustCompile(`<set>([^<]*)</set>`)
+
+// restoreCorrections undoes the corrections the matched pattern doesn't use. A corrected word
+// that is neither a word of the pattern nor a member of one of its sets was only captured by a
+// wildcard, so its wildcard gets the word as typed back ("my name is Dave" keeps Dave, not
+// "have"). It returns the corrections that helped the match.
+func (g *Golem) restoreCorrections(category *Category, wildcards map[string]string, corrections []WordCorrection) []WordCorrection {
+	if category == nil || len(corrections) == 0 {
+		return corrections
+	}
+	used := make(map[string]bool)
+	for _, word := range strings.Fields(patternMarkupRegex.ReplaceAllString(category.Pattern, " ")) {
+		used[g.vocabularyKey(word)] = true
+	}
+	for _, match := range patternSetNames.FindAllStringSubmatch(category.Pattern, -1) {
+		for _, member := range g.aimlKB.Sets[strings.ToUpper(strings.TrimSpace(match[1]))] {
+			for _, word := range strings.Fields(member) {
+				used[g.vocabularyKey(word)] = true
+			}
+		}
+	}
+
+	var kept, unused []WordCorrection
+	for _, correction := range corrections {
+		if used[g.vocabularyKey(correction.Corrected)] {
+			kept = append(kept, correction)
+		} else {
+			unused = append(unused, correction)
+		}
+	}
+	if len(unused) == 0 {
+		return corrections
+	}
+
+	// Wildcards hold the words left to right, so each correction is undone once, in input order
+	keys := make([]string, 0, len(wildcards))
+	for key := range wildcards {
+		if strings.HasPrefix(key, "star") && !strings.Contains(key, "_") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) < len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	for _, key := range keys {
+		tokens := strings.Fields(wildcards[key])
+		restored := false
+		for i, token := range tokens {
+			prefix, word, suffix := splitTokenWord(token)
+			if len(unused) > 0 && strings.EqualFold(word, unused[0].Corrected) {
+				tokens[i] = prefix + unused[0].Original + suffix
+				unused = unused[1:]
+				restored = true
+			}
+		}
+		if restored {
+			wildcards[key] = strings.Join(tokens, " ")
+		}
+	}
+	return kept
+}
+
+// closestVocabularyWord finds the vocabulary word nearest to key within the allowed distance.
+// Ties go to the word that sounds alike (Soundex), then to the alphabetically first.
+func (g *Golem) closestVocabularyWord(key string, vocabulary *spellingVocabulary) (string, int) {
+	length := utf8.RuneCountInString(key)
+	maxDistance := g.spellingCorrection.MaxDistance
+	if length < 7 && maxDistance > 1 {
+		maxDistance = 1
+	}
+
+	if g.fuzzyMatcher == nil {
+		g.fuzzyMatcher = NewFuzzyContextMatcher()
+	}
+	keySound := g.fuzzyMatcher.soundex(key)
+
+	best, bestDistance, bestSoundsAlike := "", maxDistance+1, false
+	for candidateLength := length - maxDistance; candidateLength <= length+maxDistance; candidateLength++ {
+		for _, candidate := range vocabulary.byLength[candidateLength] {
+			distance := runeEditDistance(key, candidate)
+			if distance > maxDistance || distance > bestDistance {
+				continue
+			}
+			soundsAlike := g.fuzzyMatcher.soundex(candidate) == keySound
+			if distance < bestDistance || (soundsAlike && !bestSoundsAlike) || (soundsAlike == bestSoundsAlike && candidate < best) {
+				best, bestDistance, bestSoundsAlike = candidate, distance, soundsAlike
+			}
+		}
+	}
+	if best == "" {
+		return "", 0
+	}
+	return best, bestDistance
+}
+
+// runeEditDistance is the edit distance between two strings counted in characters, where swapping
+// two adjacent characters ("teh" for "the") counts as one edit
+func runeEditDistance(a, b string) int {
+	s1, s2 := []rune(a), []rune(b)
+	matrix := make([][]int, len(s1)+1)
+	for i := range matrix {
+		matrix[i] = make([]int, len(s2)+1)
+		matrix[i][0] = i
+	}
+	for j := range matrix[0] {
+		matrix[0][j] = j
+	}
+	for i := 1; i <= len(s1); i++ {
+		for j := 1; j <= len(s2); j++ {
+			cost := 1
+			if s1[i-1] == s2[j-1] {
+				cost = 0
+			}
+			matrix[i][j] = minInt(matrix[i-1][j]+1, minInt(matrix[i][j-1]+1, matrix[i-1][j-1]+cost))
+			if i > 1 && j > 1 && s1[i-1] == s2[j-2] && s1[i-2] == s2[j-1] {
+				matrix[i][j] = minInt(matrix[i][j], matrix[i-2][j-2]+1)
+			}
+		}
+	}
+	return matrix[len(s1)][len(s2)]
+}
@@ -0,0 +1,115 @@
+package golem
+
+import (
+	"testing"
+)
+
+const spellingTestAIML = `<aiml version="2.0">
+<category><pattern>WHAT IS THE WEATHER IN *</pattern><template>Sunny in <star/>.</template></category>
+<category><pattern>I WANT A <set>fruits</set></pattern><template>One <star/> coming up.</template></category>
+<category><pattern>RESTAURANT RECOMMENDATION</pattern><template>Try the bistro.</template></category>
+<category><pattern>*</pattern><template>Pardon?</template></category>
+</aiml>`
+
+func TestSpellingCorrection(t *testing.T) {
+	g := newTestBot(t, spellingTestAIML, WithSpellingCorrection(SpellingCorrection{}))
+	g.aimlKB.Sets["FRUITS"] = []string{"banana", "apple"}
+	session := g.CreateSession("spelling")
+
+	testCases := []struct {
+		input       string
+		expected    string
+		corrections []WordCorrection
+	}{
+		{"Waht is the weather in Paris", "Sunny in Paris.", []WordCorrection{{"Waht", "What", 1}}},
+		// Set members are in the vocabulary
+		{"i want a bananna", "One banana coming up.", []WordCorrection{{"bananna", "banana", 1}}},
+		// Long words allow two edits
+		{"resturant recomendation", "Try the bistro.", []WordCorrection{{"resturant", "restaurant", 1}, {"recomendation", "recommendation", 1}}},
+		// Short and unrelated words are left alone
+		{"teh zebra", "Pardon?", nil},
+	}
+	for _, tc := range testCases {
+		response, metadata, err := g.ProcessInputWithMetadata(tc.input, session)
+		if err != nil {
+			t.Fatalf("ProcessInputWithMetadata failed: %v", err)
+		}
+		if response != tc.expected {
+			t.Errorf("%q: got %q, expected %q", tc.input, response, tc.expected)
+		}
+		if len(metadata.Corrections) != len(tc.corrections) {
+			t.Errorf("%q: got corrections %v, expected %v", tc.input, metadata.Corrections, tc.corrections)
+			continue
+		}
+		for i, correction := range tc.corrections {
+			if metadata.Corrections[i] != correction {
+				t.Errorf("%q: got correction %v, expected %v", tc.input, metadata.Corrections[i], correction)
+			}
+		}
+		// The request history behind <input/> keeps the text as typed
+		if metadata.Input != tc.input || session.RequestHistory[len(session.RequestHistory)-1] != tc.input {
+			t.Errorf("Expected input %q to be kept, got %q", tc.input, metadata.Input)
+		}
+	}
+}
+
+func TestSpellingCorrectionKeepsWildcardWords(t *testing.T) {
+	g := newTestBot(t, `<aiml version="2.0">
+<category><pattern>MY NAME IS *</pattern><template>Hi <star/>.</template></category>
+<category><pattern>I HAVE A CAT</pattern><template>Nice cat.</template></category>
+</aiml>`, WithSpellingCorrection(SpellingCorrection{}))
+	session := g.CreateSession("spelling")
+
+	// "Dave" is one edit from "have", but only the wildcard takes it
+	response, metadata, err := g.ProcessInputWithMetadata("my naem is Dave", session)
+	if err != nil {
+		t.Fatalf("ProcessInputWithMetadata failed: %v", err)
+	}
+	if response != "Hi Dave." {
+		t.Errorf("Expected the name as typed, got %q", response)
+	}
+	if len(metadata.Corrections) != 1 || metadata.Corrections[0] != (WordCorrection{"naem", "name", 1}) {
+		t.Errorf("Expected only the pattern word to be corrected, got %v", metadata.Corrections)
+	}
+}
+
+func TestSpellingCorrectionDisabledByDefault(t *testing.T) {
+	g := newTestBot(t, spellingTestAIML)
+	g.aimlKB.Sets["FRUITS"] = []string{"banana", "apple"}
+	session := g.CreateSession("spelling")
+	response, metadata, err := g.ProcessInputWithMetadata("i want a bananna", session)
+	if err != nil {
+		t.Fatalf("ProcessInputWithMetadata failed: %v", err)
+	}
+	if response != "Pardon?" || len(metadata.Corrections) != 0 {
+		t.Errorf("Expected no correction without the option, got %q with %v", response, metadata.Corrections)
+	}
+
+	// The vocabulary follows later loads
+	g.SetSpellingCorrection(&SpellingCorrection{MaxDistance: 2})
+	if err := g.LoadAIMLFromString(`<aiml><category><pattern>GOODBYE</pattern><template>Bye!</template></category></aiml>`); err != nil {
+		t.Fatalf("LoadAIMLFromString failed: %v", err)
+	}
+	if got := askN(t, g, session, "goodbey", 1)[0]; got != "Bye!" {
+		t.Errorf("Expected correction to a newly loaded word, got %q", got)
+	}
+}
+
+func TestRuneEditDistance(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"KITTEN", "SITTING", 3},
+		{"CAFÉ", "CAFE", 1},
+		{"", "ABC", 3},
+		{"SAME", "SAME", 0},
+		// Adjacent swaps are one edit
+		{"WAHT", "WHAT", 1},
+	}
+	for _, tc := range testCases {
+		if got := runeEditDistance(tc.a, tc.b); got != tc.expected {
+			t.Errorf("runeEditDistance(%q, %q) = %d, expected %d", tc.a, tc.b, got, tc.expected)
+		}
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -41,25 +42,61 @@ type SRAIXConfig struct {
 	FallbackResponse string `json:"fallback_response"`
 	// Whether to include wildcards in the request
 	IncludeWildcards bool `json:"include_wildcards"`
+	// Retries after network failures and 5xx responses, and after 429 responses (0 disables)
+	ServerErrorRetries int `json:"server_error_retries,omitempty"`
+	RateLimitRetries   int `json:"rate_limit_retries,omitempty"`
+	// First retry delay in milliseconds (default 200), doubled per retry up to RetryMaxDelay
+	// (default 5000); a random half of each delay is kept as jitter
+	RetryBaseDelay int `json:"retry_base_delay_ms,omitempty"`
+	RetryMaxDelay  int `json:"retry_max_delay_ms,omitempty"`
+	// Consecutive failures that open the circuit breaker (0 disables it), and seconds an open
+	// breaker waits before letting a probe request through (default 30)
+	BreakerThreshold int `json:"breaker_threshold,omitempty"`
+	BreakerCooldown  int `json:"breaker_cooldown,omitempty"`
+	// Requests per second the service allows (0 for no limit), retries included, and how many may
+	// come at once (default: the rate rounded up, at least 1)
+	RateLimit float64 `json:"rate_limit,omitempty"`
+	RateBurst int     `json:"rate_burst,omitempty"`
 }
 
+// LocalSRAIXService answers SRAIX requests in-process; params holds the hint, botid, host, lat
+// and lon request parameters
+type LocalSRAIXService func(input string, params map[string]string) (string, error)
+
 // SRAIXManager manages external service configurations and HTTP client
 type SRAIXManager struct {
-	configs map[string]*SRAIXConfig
-	client  *http.Client
-	logger  *log.Logger
-	verbose bool
+	configs       map[string]*SRAIXConfig
+	localServices map[string]LocalSRAIXService
+	client        *http.Client
+	logger        *log.Logger
+	verbose       bool
+	// Circuit breaker and rate limit state per service, shared with managers from withLocalServices
+	states      map[string]*sraixServiceState
+	statesMutex *sync.Mutex
+	// parent is the manager whose local services this one falls back to
+	parent *SRAIXManager
+	// Time, waiting and jitter sources of the reliability features, replaceable in tests; New
+	// reads the time from the bot's clock
+	now    func() time.Time
+	sleep  func(time.Duration)
+	jitter func(int64) int64
 }
 
 // NewSRAIXManager creates a new SRAIX manager
 func NewSRAIXManager(logger *log.Logger, verbose bool) *SRAIXManager {
 	return &SRAIXManager{
-		configs: make(map[string]*SRAIXConfig),
+		configs:       make(map[string]*SRAIXConfig),
+		localServices: make(map[string]LocalSRAIXService),
 		client: &http.Client{
 			Timeout: 30 * time.Second, // Default timeout
 		},
-		logger:  logger,
-		verbose: verbose,
+		logger:      logger,
+		verbose:     verbose,
+		states:      make(map[string]*sraixServiceState),
+		statesMutex: &sync.Mutex{},
+		now:         time.Now,
+		sleep:       time.Sleep,
+		jitter:      defaultSRAIXJitter,
 	}
 }
 
@@ -85,6 +122,7 @@ func (sm *SRAIXManager) AddConfig(config *SRAIXConfig) error {
 	}
 
 	sm.configs[config.Name] = config
+	sm.resetServiceState(config.Name)
 	if sm.verbose {
 		url := config.BaseURL
 		if url == "" {
@@ -106,16 +144,55 @@ func (sm *SRAIXManager) ListConfigs() map[string]*SRAIXConfig {
 	return sm.configs
 }
 
-// ProcessSRAIX processes a SRAIX tag by making an external HTTP request
+// RegisterLocalService registers an in-process service, used when no HTTP service has that name
+func (sm *SRAIXManager) RegisterLocalService(name string, service LocalSRAIXService) {
+	sm.localServices[name] = service
+	if sm.verbose {
+		sm.logger.Printf("Registered local SRAIX service: %s", name)
+	}
+}
+
+// GetLocalService retrieves a local SRAIX service
+func (sm *SRAIXManager) GetLocalService(name string) (LocalSRAIXService, bool) {
+	service, exists := sm.localServices[name]
+	if !exists && sm.parent != nil {
+		return sm.parent.GetLocalService(name)
+	}
+	return service, exists
+}
+
+// withLocalServices returns a manager that shares this manager's HTTP services, client and
+// reliability state but registers its own local services, falling back to this manager's
+func (sm *SRAIXManager) withLocalServices() *SRAIXManager {
+	return &SRAIXManager{
+		configs:       sm.configs,
+		localServices: make(map[string]LocalSRAIXService),
+		client:        sm.client,
+		logger:        sm.logger,
+		verbose:       sm.verbose,
+		states:        sm.states,
+		statesMutex:   sm.statesMutex,
+		parent:        sm,
+		now:           sm.now,
+		sleep:         sm.sleep,
+		jitter:        sm.jitter,
+	}
+}
+
+// ProcessSRAIX processes a SRAIX tag by making an external HTTP request, or by calling the local
+// service of that name when no HTTP service is configured
 func (sm *SRAIXManager) ProcessSRAIX(serviceName, input string, wildcards map[string]string) (string, error) {
 	config, exists := sm.GetConfig(serviceName)
 	if !exists {
+		if service, local := sm.GetLocalService(serviceName); local {
+			return service(input, wildcards)
+		}
 		return "", fmt.Errorf("SRAIX service '%s' not configured", serviceName)
 	}
 
 	// Prepare the request
 	var url string
-	var body io.Reader
+	var requestBody []byte
 	var contentType string
 
 	// Check if URL template is configured
@@ -167,35 +244,28 @@ func (sm *SRAIXManager) ProcessSRAIX(serviceName, input string, wildcards map[st
 		if err != nil {
 			return "", fmt.Errorf("failed to marshal request data: %v", err)
 		}
-		body = bytes.NewBuffer(jsonData)
+		requestBody = jsonData
 		contentType = "application/json"
 	}
 
-	// Create HTTP request
-	req, err := http.NewRequest(config.Method, url, body)
-	if err != nil {
-		return "", fmt.Errorf("failed to create HTTP request: %v", err)
-	}
-
-	// Set headers
-	if contentType != "" {
-		req.Header.Set("Content-Type", contentType)
-	}
-	for key, value := range config.Headers {
-		req.Header.Set(key, value)
+	// Rejected by the circuit breaker or rate limit, the request is not made at all
+	if err := sm.acquire(config); err != nil {
+		if sm.verbose {
+			sm.logger.Printf("SRAIX request not sent: %v", err)
+		}
+		if config.FallbackResponse != "" {
+			return config.FallbackResponse, nil
+		}
+		return "", err
 	}
 
-	// Set timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.Timeout)*time.Second)
-	defer cancel()
-	req = req.WithContext(ctx)
-
-	// Make the request
+	// Make the request, retrying as configured
 	if sm.verbose {
 		sm.logger.Printf("SRAIX request to %s: %s %s", serviceName, config.Method, url)
 	}
-
-	resp, err := sm.client.Do(req)
+	status, responseBody, err := sm.sendWithRetries(config, func() (int, http.Header, []byte, error) {
+		return sm.send(config, url, requestBody, contentType)
+	})
 	if err != nil {
 		if sm.verbose {
 			sm.logger.Printf("SRAIX request failed: %v", err)
@@ -206,24 +276,17 @@ func (sm *SRAIXManager) ProcessSRAIX(serviceName, input string, wildcards map[st
 		}
 		return "", fmt.Errorf("SRAIX request failed: %v", err)
 	}
-	defer resp.Body.Close()
-
-	// Read response body
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %v", err)
-	}
 
 	// Check for HTTP errors
-	if resp.StatusCode >= 400 {
+	if status >= 400 {
 		if sm.verbose {
-			sm.logger.Printf("SRAIX request returned status %d: %s", resp.StatusCode, string(responseBody))
+			sm.logger.Printf("SRAIX request returned status %d: %s", status, string(responseBody))
 		}
 		// Return fallback response if configured
 		if config.FallbackResponse != "" {
 			return config.FallbackResponse, nil
 		}
-		return "", fmt.Errorf("SRAIX request failed with status %d: %s", resp.StatusCode, string(responseBody))
+		return "", fmt.Errorf("SRAIX request failed with status %d: %s", status, string(responseBody))
 	}
 
 	// Process response based on format
@@ -255,6 +318,46 @@ func (sm *SRAIXManager) ProcessSRAIX(serviceName, input string, wildcards map[st
 	return strings.TrimSpace(response), nil
 }
 
+// send makes one HTTP request to a service and returns the response status, headers and body
+func (sm *SRAIXManager) send(config *SRAIXConfig, url string, requestBody []byte, contentType string) (int, http.Header, []byte, error) {
+	var body io.Reader
+	if requestBody != nil {
+		body = bytes.NewReader(requestBody)
+	}
+
+	// Create HTTP request
+	req, err := http.NewRequest(config.Method, url, body)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+
+	// Set headers
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for key, value := range config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	// Set timeout
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.Timeout)*time.Second)
+	defer cancel()
+	req = req.WithContext(ctx)
+
+	resp, err := sm.client.Do(req)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+	return resp.StatusCode, resp.Header, responseBody, nil
+}
+
 // substituteURLTemplate replaces placeholders in URL template with actual values
 // Supported placeholders:
 //   {input} - the SRAIX input text
@@ -445,6 +548,9 @@ func listFiles(dirPath, extension string) ([]string, error) {
 //   sraix.servicename.responseformat = json
 //   sraix.servicename.responsepath = data.response
 //   sraix.servicename.fallback = Service unavailable
+//   sraix.servicename.serverretries = 3 (also ratelimitretries, retrydelay, retrymaxdelay)
+//   sraix.servicename.breakerthreshold = 5 (also breakercooldown)
+//   sraix.servicename.ratelimit = 2.5 (also rateburst)
 //   sraix.servicename.header.Authorization = Bearer TOKEN
 //   sraix.servicename.header.Content-Type = application/json
 func (sm *SRAIXManager) ConfigureFromProperties(properties map[string]string) error {
@@ -549,6 +655,7 @@ func (sm *SRAIXManager) buildConfigFromProperties(serviceName string, props map[
 			if headerName != "" {
 				config.Headers[headerName] = value
 			}
+		case sm.setReliabilityProperty(config, key, value):
 		default:
 			sm.logger.Printf("Warning: Unknown SRAIX property for service '%s': %s", serviceName, key)
 		}
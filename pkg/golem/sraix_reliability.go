@@ -0,0 +1,360 @@
+package golem
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a SRAIX service's circuit breaker
+type BreakerState string
+
+const (
+	// BreakerClosed lets requests through while counting consecutive failures
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen rejects requests until the cooldown has passed
+	BreakerOpen BreakerState = "open"
+	// BreakerHalfOpen lets one probe request through; its outcome closes or reopens the breaker
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// Reliability defaults, used when a setting that needs them is enabled but left at zero
+const (
+	defaultSRAIXRetryBaseDelay  = 200 * time.Millisecond
+	defaultSRAIXRetryMaxDelay   = 5 * time.Second
+	defaultSRAIXBreakerCooldown = 30 * time.Second
+)
+
+// SRAIXServiceStatus reports the reliability state of a SRAIX service
+type SRAIXServiceStatus struct {
+	Breaker BreakerState `json:"breaker"`
+	// Failures counts the consecutive failed requests while the breaker is closed
+	Failures int `json:"failures"`
+	// RetryAt is when an open breaker lets a probe through
+	RetryAt time.Time `json:"retry_at,omitempty"`
+	// Tokens is the number of requests the rate limit allows right now
+	Tokens float64 `json:"tokens"`
+}
+
+// sraixServiceState is the circuit breaker and token bucket of one service
+type sraixServiceState struct {
+	mutex    sync.Mutex
+	breaker  BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+	tokens   float64
+	refilled time.Time
+}
+
+// sraixOutcome classifies a finished request for the circuit breaker
+type sraixOutcome int
+
+const (
+	sraixSucceeded sraixOutcome = iota
+	sraixFailed
+	// sraixRejected requests (429 and other 4xx) say nothing about the service's health
+	sraixRejected
+)
+
+// retryBaseDelay returns the delay before the first retry
+func (config *SRAIXConfig) retryBaseDelay() time.Duration {
+	if config.RetryBaseDelay > 0 {
+		return time.Duration(config.RetryBaseDelay) * time.Millisecond
+	}
+	return defaultSRAIXRetryBaseDelay
+}
+
+// retryMaxDelay returns the longest delay between retries
+func (config *SRAIXConfig) retryMaxDelay() time.Duration {
+	if config.RetryMaxDelay > 0 {
+		return time.Duration(config.RetryMaxDelay) * time.Millisecond
+	}
+	return defaultSRAIXRetryMaxDelay
+}
+
+// breakerCooldown returns how long an open breaker waits before a probe
+func (config *SRAIXConfig) breakerCooldown() time.Duration {
+	if config.BreakerCooldown > 0 {
+		return time.Duration(config.BreakerCooldown) * time.Second
+	}
+	return defaultSRAIXBreakerCooldown
+}
+
+// rateBurst returns the capacity of the token bucket: RateBurst, else the rate rounded up, at least 1
+func (config *SRAIXConfig) rateBurst() float64 {
+	if config.RateBurst > 0 {
+		return float64(config.RateBurst)
+	}
+	if config.RateLimit > 1 {
+		return float64(int(config.RateLimit + 0.999))
+	}
+	return 1
+}
+
+// serviceState returns the reliability state of a service, creating it on first use
+func (sm *SRAIXManager) serviceState(config *SRAIXConfig) *sraixServiceState {
+	sm.statesMutex.Lock()
+	defer sm.statesMutex.Unlock()
+	state, exists := sm.states[config.Name]
+	if !exists {
+		state = &sraixServiceState{breaker: BreakerClosed, tokens: config.rateBurst(), refilled: sm.now()}
+		sm.states[config.Name] = state
+	}
+	return state
+}
+
+// resetServiceState forgets the breaker and rate limit state of a service
+func (sm *SRAIXManager) resetServiceState(name string) {
+	sm.statesMutex.Lock()
+	delete(sm.states, name)
+	sm.statesMutex.Unlock()
+}
+
+// refill adds the tokens earned since the last refill, up to the bucket's capacity
+func (state *sraixServiceState) refill(config *SRAIXConfig, now time.Time) {
+	if config.RateLimit <= 0 {
+		return
+	}
+	state.tokens += now.Sub(state.refilled).Seconds() * config.RateLimit
+	if burst := config.rateBurst(); state.tokens > burst {
+		state.tokens = burst
+	}
+	state.refilled = now
+}
+
+// take uses up a token of the rate limit, failing when none is left
+func (state *sraixServiceState) take(config *SRAIXConfig, now time.Time) error {
+	if config.RateLimit <= 0 {
+		return nil
+	}
+	state.refill(config, now)
+	if state.tokens < 1 {
+		return fmt.Errorf("SRAIX service '%s' rate limit exceeded", config.Name)
+	}
+	state.tokens--
+	return nil
+}
+
+// acquireRetry admits a retry of an admitted request, which needs a token of its own
+func (sm *SRAIXManager) acquireRetry(config *SRAIXConfig) error {
+	state := sm.serviceState(config)
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	return state.take(config, sm.now())
+}
+
+// acquire admits a request: an open breaker rejects it until the cooldown has passed, a half-open
+// breaker admits one probe at a time, and the rate limit needs a token. Rejected requests fail at
+// once rather than wait, so the template gets the fallback response.
+func (sm *SRAIXManager) acquire(config *SRAIXConfig) error {
+	state := sm.serviceState(config)
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	now := sm.now()
+
+	if config.BreakerThreshold > 0 {
+		if state.breaker == BreakerOpen {
+			if now.Sub(state.openedAt) < config.breakerCooldown() {
+				return fmt.Errorf("SRAIX service '%s' is unavailable (circuit breaker open)", config.Name)
+			}
+			state.breaker = BreakerHalfOpen
+			state.probing = false
+		}
+		if state.breaker == BreakerHalfOpen && state.probing {
+			return fmt.Errorf("SRAIX service '%s' is unavailable (circuit breaker half-open)", config.Name)
+		}
+	}
+
+	if err := state.take(config, now); err != nil {
+		return err
+	}
+
+	if state.breaker == BreakerHalfOpen {
+		state.probing = true
+	}
+	return nil
+}
+
+// record updates the circuit breaker with the outcome of an admitted request
+func (sm *SRAIXManager) record(config *SRAIXConfig, outcome sraixOutcome) {
+	if config.BreakerThreshold <= 0 {
+		return
+	}
+	state := sm.serviceState(config)
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	switch {
+	case outcome == sraixSucceeded:
+		if state.breaker != BreakerClosed && sm.verbose {
+			sm.logger.Printf("SRAIX service %s recovered, circuit breaker closed", config.Name)
+		}
+		state.breaker = BreakerClosed
+		state.failures = 0
+		state.probing = false
+	case outcome == sraixRejected:
+		// The probe didn't show whether the service is healthy, so the next request probes again
+		state.probing = false
+	case state.breaker == BreakerHalfOpen:
+		state.breaker = BreakerOpen
+		state.openedAt = sm.now()
+		state.probing = false
+		if sm.verbose {
+			sm.logger.Printf("SRAIX service %s probe failed, circuit breaker reopened", config.Name)
+		}
+	default:
+		state.failures++
+		if state.failures >= config.BreakerThreshold {
+			state.breaker = BreakerOpen
+			state.openedAt = sm.now()
+			if sm.verbose {
+				sm.logger.Printf("SRAIX service %s failed %d times, circuit breaker open", config.Name, state.failures)
+			}
+		}
+	}
+}
+
+// ServiceStatus returns the circuit breaker and rate limit state of a configured service
+func (sm *SRAIXManager) ServiceStatus(name string) (SRAIXServiceStatus, bool) {
+	config, exists := sm.GetConfig(name)
+	if !exists {
+		return SRAIXServiceStatus{}, false
+	}
+	state := sm.serviceState(config)
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	now := sm.now()
+	state.refill(config, now)
+	status := SRAIXServiceStatus{Breaker: state.breaker, Failures: state.failures, Tokens: state.tokens}
+	if state.breaker == BreakerOpen {
+		status.RetryAt = state.openedAt.Add(config.breakerCooldown())
+		if !now.Before(status.RetryAt) {
+			// The next request is the probe
+			status.Breaker = BreakerHalfOpen
+		}
+	}
+	return status, true
+}
+
+// retryDelay returns the wait before retry number attempt (1 for the first): the base delay doubled
+// per attempt up to the maximum, of which a random half is kept so clients retrying together spread
+// out. A Retry-After header of a 429 response is used instead when it is shorter than the maximum.
+func (sm *SRAIXManager) retryDelay(config *SRAIXConfig, attempt int, header http.Header) time.Duration {
+	maxDelay := config.retryMaxDelay()
+	if header != nil {
+		if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds >= 0 {
+			if delay := time.Duration(seconds) * time.Second; delay <= maxDelay {
+				return delay
+			}
+			return maxDelay
+		}
+	}
+
+	delay := config.retryBaseDelay()
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay/2 + time.Duration(sm.jitter(int64(delay/2)+1))
+}
+
+// sraixSender makes one HTTP request and returns its status, headers and body
+type sraixSender func() (int, http.Header, []byte, error)
+
+// sendWithRetries makes the request, retrying network failures and 5xx responses up to
+// ServerErrorRetries times and 429 responses up to RateLimitRetries times, and records the outcome
+// with the circuit breaker. Each retry takes a token of the rate limit; without one the request
+// fails, so the template gets the fallback response.
+func (sm *SRAIXManager) sendWithRetries(config *SRAIXConfig, send sraixSender) (int, []byte, error) {
+	serverRetries, rateLimitRetries := 0, 0
+	for {
+		status, header, body, err := send()
+
+		var attempt int
+		var outcome sraixOutcome
+		switch {
+		case err != nil || status >= 500:
+			if serverRetries >= config.ServerErrorRetries {
+				sm.record(config, sraixFailed)
+				return status, body, err
+			}
+			serverRetries++
+			attempt = serverRetries
+			outcome = sraixFailed
+			header = nil
+		case status == http.StatusTooManyRequests:
+			if rateLimitRetries >= config.RateLimitRetries {
+				sm.record(config, sraixRejected)
+				return status, body, nil
+			}
+			rateLimitRetries++
+			attempt = rateLimitRetries
+			outcome = sraixRejected
+		case status >= 400:
+			sm.record(config, sraixRejected)
+			return status, body, nil
+		default:
+			sm.record(config, sraixSucceeded)
+			return status, body, nil
+		}
+
+		delay := sm.retryDelay(config, attempt, header)
+		if sm.verbose {
+			if err != nil {
+				sm.logger.Printf("SRAIX request to %s failed (%v), retrying in %v", config.Name, err, delay)
+			} else {
+				sm.logger.Printf("SRAIX request to %s returned status %d, retrying in %v", config.Name, status, delay)
+			}
+		}
+		sm.sleep(delay)
+		if err := sm.acquireRetry(config); err != nil {
+			sm.record(config, outcome)
+			return 0, nil, err
+		}
+	}
+}
+
+// setReliabilityProperty applies a retry, circuit breaker or rate limit property
+// (sraix.servicename.serverretries and so on) and reports whether key was one of them
+func (sm *SRAIXManager) setReliabilityProperty(config *SRAIXConfig, key, value string) bool {
+	settings := map[string]*int{
+		"serverretries":    &config.ServerErrorRetries,
+		"ratelimitretries": &config.RateLimitRetries,
+		"retrydelay":       &config.RetryBaseDelay,
+		"retrymaxdelay":    &config.RetryMaxDelay,
+		"breakerthreshold": &config.BreakerThreshold,
+		"breakercooldown":  &config.BreakerCooldown,
+		"rateburst":        &config.RateBurst,
+	}
+	if setting, exists := settings[key]; exists {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			sm.logger.Printf("Warning: Invalid %s value for service '%s': %s", key, config.Name, value)
+		} else {
+			*setting = n
+		}
+		return true
+	}
+	if key == "ratelimit" {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate < 0 {
+			sm.logger.Printf("Warning: Invalid ratelimit value for service '%s': %s", config.Name, value)
+		} else {
+			config.RateLimit = rate
+		}
+		return true
+	}
+	return false
+}
+
+// defaultSRAIXJitter draws the random part of retry delays
+func defaultSRAIXJitter(n int64) int64 {
+	return rand.Int63n(n)
+}
@@ -0,0 +1,227 @@
+package golem
+
+import (
+	"log"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newReliabilityTestManager returns a manager on a fake clock whose sleeps advance the clock and
+// are recorded, with jitter that keeps the whole delay
+func newReliabilityTestManager(start time.Time) (*SRAIXManager, *time.Time, *[]time.Duration) {
+	sm := NewSRAIXManager(log.New(os.Stdout, "[TEST] ", log.LstdFlags), false)
+	now := start
+	var sleeps []time.Duration
+	sm.now = func() time.Time { return now }
+	sm.sleep = func(d time.Duration) {
+		sleeps = append(sleeps, d)
+		now = now.Add(d)
+	}
+	sm.jitter = func(n int64) int64 { return n - 1 }
+	return sm, &now, &sleeps
+}
+
+// statusServer answers each request with the next status code, then 200 "ok"
+func statusServer(t *testing.T, statuses ...int) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&requests, 1))
+		if n <= len(statuses) && statuses[n-1] != http.StatusOK {
+			if statuses[n-1] == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "2")
+			}
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestSRAIXRetries(t *testing.T) {
+	sm, _, sleeps := newReliabilityTestManager(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+	server, requests := statusServer(t, 503, 502, 429, 200)
+	sm.AddConfig(&SRAIXConfig{
+		Name:               "flaky",
+		BaseURL:            server.URL,
+		ServerErrorRetries: 2,
+		RateLimitRetries:   1,
+		RetryBaseDelay:     100,
+		FallbackResponse:   "fallback",
+	})
+
+	response, err := sm.ProcessSRAIX("flaky", "hello", nil)
+	if err != nil || response != "ok" {
+		t.Fatalf("ProcessSRAIX = %q, %v; expected ok after retries", response, err)
+	}
+	if *requests != 4 {
+		t.Errorf("Expected 4 requests, got %d", *requests)
+	}
+	// Server errors back off exponentially; the 429 waits as long as Retry-After says
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 2 * time.Second}
+	if len(*sleeps) != len(expected) {
+		t.Fatalf("Expected delays %v, got %v", expected, *sleeps)
+	}
+	for i := range expected {
+		if (*sleeps)[i] != expected[i] {
+			t.Errorf("Expected delays %v, got %v", expected, *sleeps)
+			break
+		}
+	}
+
+	// Retries of each kind are limited separately
+	server, requests = statusServer(t, 500, 500, 500)
+	sm.AddConfig(&SRAIXConfig{Name: "down", BaseURL: server.URL, ServerErrorRetries: 1, RateLimitRetries: 5, FallbackResponse: "fallback"})
+	if response, _ := sm.ProcessSRAIX("down", "hello", nil); response != "fallback" || *requests != 2 {
+		t.Errorf("Expected the fallback after one retry, got %q after %d requests", response, *requests)
+	}
+}
+
+func TestSRAIXRetryDelay(t *testing.T) {
+	sm, _, _ := newReliabilityTestManager(time.Now())
+	sm.jitter = func(n int64) int64 { return 0 }
+	config := &SRAIXConfig{RetryBaseDelay: 100, RetryMaxDelay: 1000}
+	for attempt, expected := range []time.Duration{50, 100, 200, 400, 500, 500} {
+		if delay := sm.retryDelay(config, attempt+1, nil); delay != expected*time.Millisecond {
+			t.Errorf("Attempt %d: expected %v, got %v", attempt+1, expected*time.Millisecond, delay)
+		}
+	}
+	header := http.Header{"Retry-After": []string{"60"}}
+	if delay := sm.retryDelay(config, 1, header); delay != time.Second {
+		t.Errorf("Expected Retry-After capped at the maximum delay, got %v", delay)
+	}
+}
+
+func TestSRAIXCircuitBreaker(t *testing.T) {
+	sm, now, _ := newReliabilityTestManager(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+	healthy := int32(0)
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	sm.AddConfig(&SRAIXConfig{Name: "svc", BaseURL: server.URL, BreakerThreshold: 2, BreakerCooldown: 10, FallbackResponse: "fallback"})
+
+	for i := 0; i < 3; i++ {
+		sm.ProcessSRAIX("svc", "hello", nil)
+	}
+	if requests != 2 {
+		t.Errorf("Expected the open breaker to stop the third request, got %d requests", requests)
+	}
+	if status, _ := sm.ServiceStatus("svc"); status.Breaker != BreakerOpen || !status.RetryAt.Equal(now.Add(10*time.Second)) {
+		t.Errorf("Expected an open breaker, got %+v", status)
+	}
+
+	// After the cooldown one probe goes through; failing it reopens the breaker
+	*now = now.Add(10 * time.Second)
+	if status, _ := sm.ServiceStatus("svc"); status.Breaker != BreakerHalfOpen {
+		t.Errorf("Expected a half-open breaker after the cooldown, got %+v", status)
+	}
+	sm.ProcessSRAIX("svc", "hello", nil)
+	sm.ProcessSRAIX("svc", "hello", nil)
+	if requests != 3 {
+		t.Errorf("Expected a single probe, got %d requests", requests)
+	}
+
+	// A successful probe closes it
+	*now = now.Add(10 * time.Second)
+	atomic.StoreInt32(&healthy, 1)
+	if response, _ := sm.ProcessSRAIX("svc", "hello", nil); response != "ok" {
+		t.Errorf("Expected the probe to answer, got %q", response)
+	}
+	if status, _ := sm.ServiceStatus("svc"); status.Breaker != BreakerClosed || status.Failures != 0 {
+		t.Errorf("Expected a closed breaker, got %+v", status)
+	}
+}
+
+func TestSRAIXRateLimit(t *testing.T) {
+	sm, now, _ := newReliabilityTestManager(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+	server, requests := statusServer(t)
+	sm.AddConfig(&SRAIXConfig{Name: "quota", BaseURL: server.URL, RateLimit: 2, RateBurst: 3, FallbackResponse: "busy"})
+
+	var responses []string
+	for i := 0; i < 4; i++ {
+		response, _ := sm.ProcessSRAIX("quota", "hello", nil)
+		responses = append(responses, response)
+	}
+	if *requests != 3 || responses[3] != "busy" {
+		t.Errorf("Expected a burst of 3 then the fallback, got %v after %d requests", responses, *requests)
+	}
+
+	// Two tokens a second refill the bucket
+	*now = now.Add(500 * time.Millisecond)
+	if response, _ := sm.ProcessSRAIX("quota", "hello", nil); response != "ok" {
+		t.Errorf("Expected a refilled token, got %q", response)
+	}
+
+	// Every retry takes a token too
+	server, requests = statusServer(t, 503, 503, 503)
+	sm.AddConfig(&SRAIXConfig{Name: "strict", BaseURL: server.URL, RateLimit: 0.001, RateBurst: 2,
+		ServerErrorRetries: 3, RetryBaseDelay: 100, FallbackResponse: "busy"})
+	if response, _ := sm.ProcessSRAIX("strict", "hello", nil); response != "busy" || *requests != 2 {
+		t.Errorf("Expected the fallback once the tokens ran out, got %q after %d requests", response, *requests)
+	}
+}
+
+func TestSRAIXReliabilityUsesBotClock(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	g := New(false, WithClock(NewFixedClock(start)))
+	server, _ := statusServer(t, 500)
+	g.sraixMgr.AddConfig(&SRAIXConfig{Name: "svc", BaseURL: server.URL, BreakerThreshold: 1, BreakerCooldown: 10, FallbackResponse: "fallback"})
+
+	g.sraixMgr.ProcessSRAIX("svc", "hello", nil)
+	if status, _ := g.sraixMgr.ServiceStatus("svc"); status.Breaker != BreakerOpen || !status.RetryAt.Equal(start.Add(10*time.Second)) {
+		t.Errorf("Expected the breaker to open at the bot's time, got %+v", status)
+	}
+	g.SetClock(NewFixedClock(start.Add(10 * time.Second)))
+	if status, _ := g.sraixMgr.ServiceStatus("svc"); status.Breaker != BreakerHalfOpen {
+		t.Errorf("Expected the cooldown to follow the bot's clock, got %+v", status)
+	}
+}
+
+func TestSRAIXRetryJitterUsesRandomSource(t *testing.T) {
+	g := New(false, WithRand(rand.New(rand.NewSource(11))))
+	source := rand.New(rand.NewSource(11))
+	config := &SRAIXConfig{RetryBaseDelay: 100, RetryMaxDelay: 1000}
+	for attempt, full := range []time.Duration{100, 200, 400, 800} {
+		half := full * time.Millisecond / 2
+		expected := half + time.Duration(source.Int63n(int64(half)+1))
+		if delay := g.sraixMgr.retryDelay(config, attempt+1, nil); delay != expected {
+			t.Errorf("Attempt %d: expected %v from the bot's random source, got %v", attempt+1, expected, delay)
+		}
+	}
+}
+
+func TestSRAIXReliabilityProperties(t *testing.T) {
+	sm := NewSRAIXManager(log.New(os.Stdout, "[TEST] ", log.LstdFlags), false)
+	err := sm.ConfigureFromProperties(map[string]string{
+		"sraix.api.baseurl":          "https://api.example.com",
+		"sraix.api.serverretries":    "3",
+		"sraix.api.ratelimitretries": "1",
+		"sraix.api.retrydelay":       "50",
+		"sraix.api.breakerthreshold": "5",
+		"sraix.api.breakercooldown":  "60",
+		"sraix.api.ratelimit":        "0.5",
+		"sraix.api.rateburst":        "2",
+	})
+	if err != nil {
+		t.Fatalf("ConfigureFromProperties failed: %v", err)
+	}
+	config, _ := sm.GetConfig("api")
+	if config.ServerErrorRetries != 3 || config.RateLimitRetries != 1 || config.RetryBaseDelay != 50 ||
+		config.BreakerThreshold != 5 || config.BreakerCooldown != 60 || config.RateLimit != 0.5 || config.RateBurst != 2 {
+		t.Errorf("Unexpected config %+v", config)
+	}
+}
@@ -0,0 +1,212 @@
+package golem
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Substitution file names (without the .substitution extension) that drive template tags
+const (
+	SubstitutionPerson   = "person"   // <person>
+	SubstitutionPerson2  = "person2"  // <person2>
+	SubstitutionGender   = "gender"   // <gender>
+	SubstitutionNormal   = "normal"   // <normalize> and input normalization
+	SubstitutionDenormal = "denormal" // <denormalize>
+)
+
+// isTagSubstitution reports whether a substitution file only applies to its template tag.
+// All other files (normal and custom ones) are applied to user input.
+func isTagSubstitution(name string) bool {
+	switch name {
+	case SubstitutionPerson, SubstitutionPerson2, SubstitutionGender, SubstitutionDenormal:
+		return true
+	}
+	return false
+}
+
+// loadedSubstitution returns the named substitution file, or nil when it is not loaded or empty
+func (g *Golem) loadedSubstitution(name string) map[string]string {
+	if g.aimlKB == nil || len(g.aimlKB.Substitutions[name]) == 0 {
+		return nil
+	}
+	return g.aimlKB.Substitutions[name]
+}
+
+// substitutionRule is one pattern/replacement pair from a substitution file
+type substitutionRule struct {
+	pattern     string
+	replacement string
+}
+
+// substitutionRules collects the rules of the given maps with fold applied to both sides,
+// longest pattern first so "I AM" wins over "I"
+func substitutionRules(fold func(string) string, maps ...map[string]string) []substitutionRule {
+	var rules []substitutionRule
+	for _, substitutionMap := range maps {
+		for pattern, replacement := range substitutionMap {
+			rules = append(rules, substitutionRule{pattern: fold(pattern), replacement: fold(replacement)})
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if len(rules[i].pattern) != len(rules[j].pattern) {
+			return len(rules[i].pattern) > len(rules[j].pattern)
+		}
+		return rules[i].pattern < rules[j].pattern
+	})
+	return rules
+}
+
+// applySubstitutionRules replaces rule patterns in text until nothing changes.
+// The text is padded with a space on each side so patterns written as " WANNA " also
+// match the first and last word.
+func (g *Golem) applySubstitutionRules(text string, rules []substitutionRule) string {
+	if len(rules) == 0 {
+		return text
+	}
+
+	originalText := text
+	text = " " + text + " "
+
+	// Apply substitutions iteratively until no more changes occur
+	maxIterations := 10 // Prevent infinite loops
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		prevText := text
+		result := strings.Builder{}
+		result.Grow(len(text))
+
+		// Apply substitutions in a single pass
+		i := 0
+		for i < len(text) {
+			matched := false
+
+			// Try to match the longest pattern first
+			for _, sub := range rules {
+				if sub.pattern != "" && strings.HasPrefix(text[i:], sub.pattern) {
+					result.WriteString(sub.replacement)
+					i += len(sub.pattern)
+					matched = true
+					g.LogDebug("Applied substitution: '%s' -> '%s'", sub.pattern, sub.replacement)
+					break
+				}
+			}
+
+			if !matched {
+				// No match, copy the character as-is
+				result.WriteByte(text[i])
+				i++
+			}
+		}
+
+		text = result.String()
+
+		// If no changes were made, we're done
+		if text == prevText {
+			break
+		}
+	}
+
+	text = strings.TrimPrefix(strings.TrimSuffix(text, " "), " ")
+	if originalText != text {
+		g.LogDebug("Substitutions applied: '%s' -> '%s'", originalText, text)
+	}
+	return text
+}
+
+// isSubstitutionWordRune reports whether r is part of a word for whole-word substitutions
+func isSubstitutionWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\''
+}
+
+// applyWordSubstitutions swaps whole words and phrases in a single left-to-right pass, so
+// pairs such as "I"/"you" in person.substitution do not undo each other. Matching ignores
+// case; an all-caps match gives an all-caps replacement and a capitalized first word stays capitalized.
+func applyWordSubstitutions(text string, substitutions map[string]string) string {
+	type wordRule struct {
+		pattern     []rune
+		replacement string
+	}
+	var rules []wordRule
+	for pattern, replacement := range substitutions {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		rules = append(rules, wordRule{pattern: []rune(strings.ToLower(pattern)), replacement: strings.TrimSpace(replacement)})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if len(rules[i].pattern) != len(rules[j].pattern) {
+			return len(rules[i].pattern) > len(rules[j].pattern)
+		}
+		return string(rules[i].pattern) < string(rules[j].pattern)
+	})
+
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	var result strings.Builder
+	for i := 0; i < len(runes); {
+		matched := false
+		if i == 0 || !isSubstitutionWordRune(runes[i-1]) {
+			for _, rule := range rules {
+				end := i + len(rule.pattern)
+				if end > len(runes) || string(lower[i:end]) != string(rule.pattern) {
+					continue
+				}
+				if end < len(runes) && isSubstitutionWordRune(runes[end]) && isSubstitutionWordRune(rule.pattern[len(rule.pattern)-1]) {
+					continue
+				}
+				result.WriteString(matchSubstitutionCase(string(runes[i:end]), rule.replacement, atSentenceStart(runes, i)))
+				i = end
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			result.WriteRune(runes[i])
+			i++
+		}
+	}
+	return result.String()
+}
+
+// atSentenceStart reports whether position i starts a sentence
+func atSentenceStart(runes []rune, i int) bool {
+	for j := i - 1; j >= 0; j-- {
+		if unicode.IsSpace(runes[j]) {
+			continue
+		}
+		return runes[j] == '.' || runes[j] == '!' || runes[j] == '?'
+	}
+	return true
+}
+
+// matchSubstitutionCase adapts a replacement to the case of the text it replaces
+func matchSubstitutionCase(original, replacement string, sentenceStart bool) string {
+	letters := 0
+	allUpper := true
+	for _, r := range original {
+		if unicode.IsLetter(r) {
+			letters++
+			if !unicode.IsUpper(r) {
+				allUpper = false
+			}
+		}
+	}
+	if letters > 1 && allUpper {
+		return strings.ToUpper(replacement)
+	}
+
+	first := []rune(original)
+	if sentenceStart && len(first) > 0 && unicode.IsUpper(first[0]) {
+		replacementRunes := []rune(replacement)
+		if len(replacementRunes) > 0 {
+			replacementRunes[0] = unicode.ToUpper(replacementRunes[0])
+			return string(replacementRunes)
+		}
+	}
+	return replacement
+}
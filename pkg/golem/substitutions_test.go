@@ -0,0 +1,90 @@
+package golem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeSubstitutionBot writes a bot directory with substitution files and categories using every substitution tag
+func writeSubstitutionBot(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := writeReplayBot(t, `<category><pattern>ECHO *</pattern><template><person><star/></person></template></category>
+<category><pattern>TELL *</pattern><template><person2><star/></person2></template></category>
+<category><pattern>SWAP *</pattern><template><gender><star/></gender></template></category>
+<category><pattern>NORM *</pattern><template><normalize><star/></normalize></template></category>
+<category><pattern>DENORM *</pattern><template><denormalize><star/></denormalize></template></category>
+<category><pattern>I WANT TO GO</pattern><template>Where to?</template></category>
+<category><pattern>YO SOY *</pattern><template>Hola</template></category>`)
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestSubstitutionFilesDriveTags(t *testing.T) {
+	g := loadReplayBot(t, writeSubstitutionBot(t, map[string]string{
+		"person.substitution":   `[["yo soy", "tú eres"], ["tú eres", "yo soy"], ["yo", "tú"], ["tú", "yo"], ["mi", "tu"], ["tu", "mi"]]`,
+		"person2.substitution":  `[["yo", "él"], ["mi", "su"]]`,
+		"gender.substitution":   `[["él", "ella"], ["ella", "él"]]`,
+		"normal.substitution":   `[[" wanna ", " want to "]]`,
+		"denormal.substitution": `[[" dot com ", ".com "]]`,
+	}))
+	session := g.CreateSession("subs")
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		// Swaps happen in a single pass, so tú/yo do not undo each other
+		{"echo yo soy tu amigo y tú eres mi amigo", "tú eres mi amigo y yo soy tu amigo"},
+		{"tell yo perdí mi libro", "él perdí su libro"},
+		{"swap ella dijo que él vendría", "él dijo que ella vendría"},
+		{"denorm visit example dot com today", "Visit example.com today."},
+		// normal.substitution applies to input matching and to <normalize>
+		{"i wanna go", "Where to?"},
+		{"norm we wanna dance", "WE WANT TO DANCE"},
+		// person.substitution is not applied to input, so YO SOY still matches
+		{"yo soy Ana", "Hola"},
+	}
+	for _, tc := range testCases {
+		if got := askN(t, g, session, tc.input, 1)[0]; got != tc.expected {
+			t.Errorf("%q: got %q, expected %q", tc.input, got, tc.expected)
+		}
+	}
+}
+
+func TestBuiltInSubstitutionsRemainDefaults(t *testing.T) {
+	g := loadReplayBot(t, writeSubstitutionBot(t, nil))
+	session := g.CreateSession("builtin")
+
+	if got := askN(t, g, session, "echo I am happy", 1)[0]; got != "you are happy" {
+		t.Errorf("Expected built-in person table, got %q", got)
+	}
+	if got := askN(t, g, session, "swap he lost his keys", 1)[0]; got != "she lost her keys" {
+		t.Errorf("Expected built-in gender table, got %q", got)
+	}
+}
+
+func TestApplyWordSubstitutions(t *testing.T) {
+	substitutions := map[string]string{"i": "you", "you": "I", "my": "your", "i am": "you are"}
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"I am sure you know my name", "You are sure I know your name"},
+		{"Tell me what I think", "Tell me what you think"},
+		{"MY CAR", "YOUR CAR"},
+		// Whole words only
+		{"mine is bigger, isn't it", "mine is bigger, isn't it"},
+		{"I'm here", "I'm here"},
+	}
+	for _, tc := range testCases {
+		if got := applyWordSubstitutions(tc.input, substitutions); got != tc.expected {
+			t.Errorf("%q: got %q, expected %q", tc.input, got, tc.expected)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package golem
 
 import (
-	"math/rand"
 	"regexp"
 	"strings"
 	"unicode"
@@ -358,7 +357,7 @@ func (tp *TextProcessing) ShuffleText(input string) string {
 
 	// Fisher-Yates shuffle
 	for i := len(words) - 1; i > 0; i-- {
-		j := rand.Intn(i + 1)
+		j := tp.golem.randomIntn(nil, i+1)
 		words[i], words[j] = words[j], words[i]
 	}
 
@@ -483,7 +482,7 @@ func (tp *TextProcessing) RandomInt(max int) int {
 	if max <= 0 {
 		return 0
 	}
-	return rand.Intn(max)
+	return tp.golem.randomIntn(nil, max)
 }
 
 // NormalizeTextForOutput normalizes text for output
@@ -0,0 +1,194 @@
+package golem
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokenizer splits text into the words that patterns and wildcards work on.
+// The default is whitespace splitting; languages written without spaces need a segmenter.
+type Tokenizer interface {
+	Tokenize(text string) []string
+}
+
+// WhitespaceTokenizer splits text at whitespace
+type WhitespaceTokenizer struct{}
+
+// Tokenize splits text at whitespace
+func (WhitespaceTokenizer) Tokenize(text string) []string {
+	return strings.Fields(text)
+}
+
+// DictionaryTokenizer segments Chinese, Japanese, Thai and other text written without spaces by
+// matching the longest dictionary word at each position. Characters not starting any dictionary
+// word become tokens of their own. Other text is split at whitespace and script changes.
+type DictionaryTokenizer struct {
+	words    map[string]bool
+	maxRunes int
+}
+
+// NewDictionaryTokenizer creates a segmenter with the given dictionary words
+func NewDictionaryTokenizer(words []string) *DictionaryTokenizer {
+	t := &DictionaryTokenizer{words: make(map[string]bool, len(words))}
+	for _, word := range words {
+		t.AddWord(word)
+	}
+	return t
+}
+
+// LoadDictionaryTokenizer creates a segmenter from a dictionary file with one word per line.
+// Anything after the first space or tab (such as a frequency or part of speech) is ignored, and
+// lines starting with '#' are comments.
+func LoadDictionaryTokenizer(filename string) (*DictionaryTokenizer, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dictionary %s: %v", filename, err)
+	}
+
+	t := NewDictionaryTokenizer(nil)
+	for _, line := range strings.Split(strings.TrimPrefix(string(content), "\xef\xbb\xbf"), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		t.AddWord(fields[0])
+	}
+	if len(t.words) == 0 {
+		return nil, fmt.Errorf("dictionary %s has no words", filename)
+	}
+	return t, nil
+}
+
+// AddWord adds a word to the dictionary
+func (t *DictionaryTokenizer) AddWord(word string) {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return
+	}
+	t.words[word] = true
+	if n := utf8.RuneCountInString(word); n > t.maxRunes {
+		t.maxRunes = n
+	}
+}
+
+// Size returns the number of dictionary words
+func (t *DictionaryTokenizer) Size() int {
+	return len(t.words)
+}
+
+// Tokenize splits text into whitespace-separated words, segmenting runs of unspaced scripts
+func (t *DictionaryTokenizer) Tokenize(text string) []string {
+	var tokens []string
+	for _, field := range strings.Fields(text) {
+		runes := []rune(field)
+		for start := 0; start < len(runes); {
+			end := start + 1
+			if !isUnspacedScript(runes[start]) {
+				for end < len(runes) && !isUnspacedScript(runes[end]) {
+					end++
+				}
+				tokens = append(tokens, string(runes[start:end]))
+				start = end
+				continue
+			}
+
+			// Longest dictionary word at this position, or a single character
+			for length := t.maxRunes; length > 1; length-- {
+				if start+length <= len(runes) && t.words[string(runes[start:start+length])] {
+					end = start + length
+					break
+				}
+			}
+			tokens = append(tokens, string(runes[start:end]))
+			start = end
+		}
+	}
+	return tokens
+}
+
+// isUnspacedScript reports whether r belongs to a script written without spaces between words
+func isUnspacedScript(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar) ||
+		r == 'ー' || r == '々'
+}
+
+// joinTokens joins tokens with spaces, except between two tokens of unspaced scripts
+func joinTokens(tokens []string) string {
+	var result strings.Builder
+	for i, token := range tokens {
+		if i > 0 {
+			last, _ := utf8.DecodeLastRuneInString(tokens[i-1])
+			first, _ := utf8.DecodeRuneInString(token)
+			if !isUnspacedScript(last) || !isUnspacedScript(first) {
+				result.WriteByte(' ')
+			}
+		}
+		result.WriteString(token)
+	}
+	return result.String()
+}
+
+// WithTokenizer makes patterns and input use the given tokenizer
+func WithTokenizer(tokenizer Tokenizer) Option {
+	return func(g *Golem) {
+		g.SetTokenizer(tokenizer)
+	}
+}
+
+// SetTokenizer sets the tokenizer for patterns and input; nil restores whitespace splitting.
+// Patterns are tokenized when they are loaded, so set it before loading AIML.
+func (g *Golem) SetTokenizer(tokenizer Tokenizer) {
+	g.tokenizer = tokenizer
+	if g.wordBoundaryDetector != nil {
+		g.wordBoundaryDetector.tokenizer = tokenizer
+	}
+}
+
+// GetTokenizer returns the tokenizer for patterns and input, nil for whitespace splitting
+func (g *Golem) GetTokenizer() Tokenizer {
+	return g.tokenizer
+}
+
+// segmentText puts a space between the tokens of text
+func (g *Golem) segmentText(text string) string {
+	if g == nil || g.tokenizer == nil {
+		return text
+	}
+	return strings.Join(g.tokenizer.Tokenize(text), " ")
+}
+
+// patternMarkupRegex finds the parts of a pattern that are not tokenized
+var patternMarkupRegex = regexp.MustCompile(`<set>[^<]*</set>|<topic>[^<]*</topic>|<[^>]*>`)
+
+// segmentPattern tokenizes the text of a pattern, leaving set references and other markup intact
+func (g *Golem) segmentPattern(pattern string) string {
+	if g == nil || g.tokenizer == nil {
+		return pattern
+	}
+	var result []string
+	last := 0
+	for _, loc := range patternMarkupRegex.FindAllStringIndex(pattern, -1) {
+		result = append(result, g.tokenizer.Tokenize(pattern[last:loc[0]])...)
+		result = append(result, pattern[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	result = append(result, g.tokenizer.Tokenize(pattern[last:])...)
+	return strings.Join(result, " ")
+}
+
+// prepareForMatching applies the Unicode stage and the tokenizer to input or set members
+func (g *Golem) prepareForMatching(text string) string {
+	return g.segmentText(g.normalizeUnicode(text))
+}
+
+// joinCapture turns the tokens of a capture back into text, without the spaces the tokenizer added
+func (g *Golem) joinCapture(tokens []string) string {
+	if g == nil || g.tokenizer == nil {
+		return strings.Join(tokens, " ")
+	}
+	return joinTokens(tokens)
+}
@@ -0,0 +1,101 @@
+package golem
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const tokenizerTestAIML = `<aiml version="2.0">
+<category><pattern>我喜欢*</pattern><template>你喜欢<star/>。</template></category>
+<category><pattern>我住在<set>cities</set></pattern><template>欢迎来到<star/>。</template></category>
+<category><pattern>*は好きですか</pattern><template><star/>が大好きです。</template></category>
+<category><pattern>HELLO *</pattern><template>Hi <star/>.</template></category>
+</aiml>`
+
+func TestDictionaryTokenizer(t *testing.T) {
+	tokenizer := NewDictionaryTokenizer([]string{"我", "喜欢", "北京", "北京大学", "大学", "寿司", "好き", "です"})
+
+	testCases := []struct {
+		input    string
+		expected []string
+	}{
+		// The longest dictionary word wins
+		{"我喜欢北京大学", []string{"我", "喜欢", "北京大学"}},
+		{"我喜欢北京", []string{"我", "喜欢", "北京"}},
+		// Unknown characters are tokens of their own
+		{"我喜欢猫", []string{"我", "喜欢", "猫"}},
+		{"寿司は好きです", []string{"寿司", "は", "好き", "です"}},
+		// Other scripts split at spaces and script changes
+		{"我喜欢iPhone 15", []string{"我", "喜欢", "iPhone", "15"}},
+		{"hello world", []string{"hello", "world"}},
+	}
+	for _, tc := range testCases {
+		if got := tokenizer.Tokenize(tc.input); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("Tokenize(%q) = %q, expected %q", tc.input, got, tc.expected)
+		}
+	}
+}
+
+func TestLoadDictionaryTokenizer(t *testing.T) {
+	dir := t.TempDir()
+	writeResourceFile(t, dir, "dict.txt", "# word frequency tag\n北京大学 2053 nt\n喜欢 1000 v\n\n我\n")
+	tokenizer, err := LoadDictionaryTokenizer(filepath.Join(dir, "dict.txt"))
+	if err != nil {
+		t.Fatalf("LoadDictionaryTokenizer failed: %v", err)
+	}
+	if tokenizer.Size() != 3 {
+		t.Errorf("Expected 3 words, got %d", tokenizer.Size())
+	}
+	if got := tokenizer.Tokenize("我喜欢北京大学"); !reflect.DeepEqual(got, []string{"我", "喜欢", "北京大学"}) {
+		t.Errorf("Unexpected tokens %q", got)
+	}
+
+	writeResourceFile(t, dir, "empty.txt", "# nothing\n")
+	if _, err := LoadDictionaryTokenizer(filepath.Join(dir, "empty.txt")); err == nil {
+		t.Error("Expected an error for a dictionary without words")
+	}
+}
+
+func TestTokenizerWildcards(t *testing.T) {
+	tokenizer := NewDictionaryTokenizer([]string{"我", "喜欢", "住在", "北京", "大学", "北京大学", "上海", "寿司", "好き", "です", "か"})
+	g := New(false, WithTokenizer(tokenizer))
+	if err := g.LoadAIMLFromString(tokenizerTestAIML); err != nil {
+		t.Fatalf("LoadAIMLFromString failed: %v", err)
+	}
+	g.aimlKB.Sets["CITIES"] = []string{"北京", "上海"}
+	session := g.CreateSession("cjk")
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		// * binds to whole tokens and keeps the text without added spaces
+		{"我喜欢北京大学", "你喜欢北京大学。"},
+		{"我喜欢猫", "你喜欢猫。"},
+		{"我住在上海", "欢迎来到上海。"},
+		{"寿司は好きですか", "寿司が大好きです。"},
+		// Spaced languages are unaffected
+		{"hello there world", "Hi there world."},
+	}
+	for _, tc := range testCases {
+		if got := askN(t, g, session, tc.input, 1)[0]; got != tc.expected {
+			t.Errorf("%q: got %q, expected %q", tc.input, got, tc.expected)
+		}
+	}
+}
+
+func TestWithoutTokenizerUnspacedTextIsOneWord(t *testing.T) {
+	g := New(false)
+	if err := g.LoadAIMLFromString(tokenizerTestAIML); err != nil {
+		t.Fatalf("LoadAIMLFromString failed: %v", err)
+	}
+	if got := g.wordBoundaryDetector.SplitWords("我喜欢北京"); len(got) != 1 {
+		t.Errorf("Expected one word without a tokenizer, got %q", got)
+	}
+
+	g.SetTokenizer(NewDictionaryTokenizer([]string{"喜欢", "北京"}))
+	if got := g.wordBoundaryDetector.SplitWords("我喜欢北京!"); !reflect.DeepEqual(got, []string{"我", "喜欢", "北京", "!"}) {
+		t.Errorf("Expected SplitWords to use the tokenizer, got %q", got)
+	}
+}
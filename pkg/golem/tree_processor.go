@@ -252,7 +252,7 @@ func (tp *TreeProcessor) processTag(node *ASTNode) string {
 	// For those tags, skip pre-processing children
 	skipChildProcessing := false
 	switch node.TagName {
-	case "random", "condition", "learn", "learnf":
+	case "random", "condition", "learn", "learnf", "interval", "schedule":
 		skipChildProcessing = true
 	}
 
@@ -293,6 +293,12 @@ func (tp *TreeProcessor) processTag(node *ASTNode) string {
 		return tp.processSRAITag(node, content)
 	case "sraix":
 		return tp.processSRAIXTag(node, content)
+	case "faq":
+		return tp.processFAQTag(node, content)
+	case "form":
+		return tp.processFormTag(node, content)
+	case "schedule":
+		return tp.processScheduleTag(node)
 	case "think":
 		return tp.processThinkTag(node, content)
 	case "set":
@@ -401,6 +407,8 @@ func (tp *TreeProcessor) processTag(node *ASTNode) string {
 		return tp.processDateTag(node, content)
 	case "time":
 		return tp.processTimeTag(node, content)
+	case "interval":
+		return tp.processIntervalTag(node, content)
 	case "subj":
 		return tp.processSubjTag(node, content)
 	case "pred":
@@ -516,6 +524,8 @@ func (tp *TreeProcessor) processSelfClosingTag(node *ASTNode) string {
 		return tp.processRepeatTag(node, "")
 	case "topic":
 		return tp.processTopicTag(node, "")
+	case "form":
+		return tp.processFormTag(node, "")
 	default:
 		// Unknown self-closing tag, return as-is
 		attrStr := ""
@@ -551,11 +561,13 @@ func (tp *TreeProcessor) processSRAITag(node *ASTNode, content string) string {
 
 	// Try to match the SRAI content as a new AIML pattern
 	if tp.golem.aimlKB != nil {
-		category, wildcards, err := tp.golem.aimlKB.MatchPattern(sraiContent)
+		category, wildcards, _, err := tp.golem.matchWithEntities(sraiContent, tp.golem.aimlKB.MatchPattern)
 		tp.golem.LogInfo("SRAI pattern match: content='%s', err=%v, category=%v, wildcards=%v",
 			sraiContent, err, category != nil, wildcards)
 
 		if err == nil && category != nil {
+			tp.golem.recordCategoryHit(category)
+
 			// Create a new context with incremented recursion depth
 			// Preserve all context except increment recursion depth
 			newCtx := &VariableContext{
@@ -701,6 +713,109 @@ func (tp *TreeProcessor) processSRAIXTag(node *ASTNode, content string) string {
 	return response
 }
 
+// processFAQTag answers with the FAQ passage best matching the content. The title, section and
+// score of the passage are stored in the faq_title, faq_section and faq_score predicates, and the
+// hint attribute returns one of them instead of the passage. Without a match the default attribute
+// (or nothing) is returned and the predicates are cleared.
+func (tp *TreeProcessor) processFAQTag(node *ASTNode, content string) string {
+	tp.trackMetric("data") // Track data processor usage
+
+	query := strings.TrimSpace(content)
+	results := tp.golem.SearchFAQ(query, 1)
+
+	var result FAQResult
+	if len(results) > 0 {
+		result = results[0]
+		tp.golem.LogInfo("FAQ: '%s' -> '%s' (score %.2f)", query, result.Title, result.Score)
+	}
+	if tp.ctx != nil && tp.ctx.Session != nil {
+		if tp.ctx.Session.Variables == nil {
+			tp.ctx.Session.Variables = make(map[string]string)
+		}
+		tp.ctx.Session.Variables["faq_title"] = result.Title
+		tp.ctx.Session.Variables["faq_section"] = result.Section
+		tp.ctx.Session.Variables["faq_score"] = ""
+		if len(results) > 0 {
+			tp.ctx.Session.Variables["faq_score"] = faqResultField(result, "score")
+		}
+	}
+
+	if len(results) == 0 {
+		tp.golem.LogInfo("FAQ: no passage matches '%s'", query)
+		if defaultResponse, exists := node.Attributes["default"]; exists {
+			return tp.evaluateAttributeValue(defaultResponse)
+		}
+		return ""
+	}
+	if hint, exists := node.Attributes["hint"]; exists {
+		return faqResultField(result, tp.evaluateAttributeValue(hint))
+	}
+	return result.Passage
+}
+
+// processFormTag handles <form name="book"/>, which starts (or resumes) a form and asks for its
+// first missing slot. action="cancel" abandons the form and action="prompt" asks the current
+// question again if the form is active.
+func (tp *TreeProcessor) processFormTag(node *ASTNode, content string) string {
+	tp.trackMetric("data") // Track data processor usage
+
+	name := tp.evaluateAttributeValue(node.Attributes["name"])
+	if name == "" {
+		name = strings.TrimSpace(content)
+	}
+	if tp.ctx == nil || tp.ctx.Session == nil {
+		tp.golem.LogWarn("Form %s needs a session", name)
+		return ""
+	}
+
+	var prompt string
+	switch action := tp.evaluateAttributeValue(node.Attributes["action"]); action {
+	case "cancel":
+		tp.golem.CancelForm(tp.ctx.Session, name)
+		return ""
+	case "prompt":
+		prompt = tp.golem.formPrompt(tp.ctx.Session, name)
+	case "", "start":
+		var err error
+		if prompt, err = tp.golem.StartForm(tp.ctx.Session, name); err != nil {
+			tp.golem.LogWarn("Form: %v", err)
+			return ""
+		}
+	default:
+		tp.golem.LogWarn("Form %s: unknown action '%s'", name, action)
+		return ""
+	}
+	if prompt == "" {
+		return ""
+	}
+	return tp.golem.processTemplateWithContext(prompt, tp.ctx.Wildcards, tp.ctx)
+}
+
+// processScheduleTag handles <schedule in="10m">...</schedule>, which makes the bot send its
+// content to the session after the delay. The content is kept unprocessed and processed when the
+// message is due, so it sees the predicates of that time and the wildcards of this match.
+func (tp *TreeProcessor) processScheduleTag(node *ASTNode) string {
+	tp.trackMetric("data") // Track data processor usage
+
+	if tp.ctx == nil || tp.ctx.Session == nil {
+		tp.golem.LogWarn("Schedule needs a session")
+		return ""
+	}
+	delay, err := ParseScheduleDelay(tp.evaluateAttributeValue(node.Attributes["in"]))
+	if err != nil {
+		tp.golem.LogWarn("Schedule: %v", err)
+		return ""
+	}
+	var template strings.Builder
+	for _, child := range node.Children {
+		template.WriteString(child.String())
+	}
+	if _, err := tp.golem.schedule(tp.ctx.Session, strings.TrimSpace(template.String()), tp.ctx.Wildcards, delay); err != nil {
+		tp.golem.LogWarn("Schedule: %v", err)
+	}
+	return ""
+}
+
 // generateSRAIXFallback generates an intelligent fallback response when SRAIX services are unavailable
 func (tp *TreeProcessor) generateSRAIXFallback(query, serviceName, botName string) string {
 	queryUpper := strings.ToUpper(query)
@@ -1078,6 +1193,24 @@ func (tp *TreeProcessor) processBotTag(node *ASTNode, content string) string {
 	return ""
 }
 
+// normalizedStar returns the normalized value of an entity captured by the star named key
+func (tp *TreeProcessor) normalizedStar(key string) (string, bool) {
+	if tp.ctx == nil {
+		return "", false
+	}
+	if tp.ctx.Session != nil {
+		if value, exists := tp.ctx.Session.Variables[key+"_normalized"]; exists {
+			return value, true
+		}
+	}
+	if tp.ctx.Wildcards != nil {
+		if value, exists := tp.ctx.Wildcards[key+"_normalized"]; exists {
+			return value, true
+		}
+	}
+	return "", false
+}
+
 func (tp *TreeProcessor) processStarTag(node *ASTNode, content string) string {
 	// Process star tag - wildcard reference
 	// <star/> without index always refers to star1 (first wildcard)
@@ -1092,6 +1225,12 @@ func (tp *TreeProcessor) processStarTag(node *ASTNode, content string) string {
 	}
 
 	key := fmt.Sprintf("star%d", index)
+	// normalized="true" reads the normalized value of an entity set capture, such as an ISO date
+	if normalized, exists := node.Attributes["normalized"]; exists && strings.EqualFold(normalized, "true") {
+		if value, ok := tp.normalizedStar(key); ok {
+			return value
+		}
+	}
 
 	// Get wildcard value - check session variables first, then wildcards in context
 	if tp.ctx != nil {
@@ -1173,6 +1312,7 @@ func (tp *TreeProcessor) processSRTag(node *ASTNode, content string) string {
 	}
 
 	tp.golem.LogDebug("SR tag: found matching pattern for '%s'", starContent)
+	tp.golem.recordCategoryHit(category)
 
 	// Check recursion depth to prevent infinite loops
 	if tp.ctx.RecursionDepth >= 100 {
@@ -1426,7 +1566,7 @@ func (tp *TreeProcessor) processRandomTag(node *ASTNode, content string) string
 	}
 
 	// Select random item
-	index := tp.golem.randomIntTree(len(items))
+	index := tp.golem.randomIntTree(tp.ctx.Session, len(items))
 	return items[index]
 }
 
@@ -1604,7 +1744,7 @@ func (tp *TreeProcessor) processMapTag(node *ASTNode, content string) string {
 		// Check if map contains key
 		contains := false
 		if key != "" {
-			_, contains = tp.ctx.KnowledgeBase.Maps[name][key]
+			_, contains = tp.golem.lookupMapValue(tp.ctx.KnowledgeBase, name, key)
 		}
 		result := "false"
 		if contains {
@@ -1649,7 +1789,7 @@ func (tp *TreeProcessor) processMapTag(node *ASTNode, content string) string {
 	case "get", "":
 		// Get value by key (original functionality)
 		if key != "" {
-			if value, exists := tp.ctx.KnowledgeBase.Maps[name][key]; exists {
+			if value, exists := tp.golem.lookupMapValue(tp.ctx.KnowledgeBase, name, key); exists {
 				tp.golem.LogInfo("Mapped '%s' -> '%s'", key, value)
 				return value
 			} else {
@@ -1664,7 +1804,7 @@ func (tp *TreeProcessor) processMapTag(node *ASTNode, content string) string {
 		// Unknown operation, treat as get
 		tp.golem.LogInfo("Unknown operation '%s', treating as get", operation)
 		if key != "" {
-			if value, exists := tp.ctx.KnowledgeBase.Maps[name][key]; exists {
+			if value, exists := tp.golem.lookupMapValue(tp.ctx.KnowledgeBase, name, key); exists {
 				return value
 			}
 			return key
@@ -2714,18 +2854,26 @@ func (tp *TreeProcessor) processWordTag(node *ASTNode, content string) string {
 func (tp *TreeProcessor) processDateTag(node *ASTNode, content string) string {
 	// Date tag - current date
 	tp.trackMetric("data") // Track data processor usage
+	now, locale := tp.golem.localizedNow(node.Attributes, tp.ctx.Session)
+	if jformat := node.Attributes["jformat"]; jformat != "" {
+		return formatJavaDate(now, jformat, locale)
+	}
 	format := "Monday, January 2, 2006"
 	if f, exists := node.Attributes["format"]; exists {
 		format = f
 	}
 	// Convert C-style or alternative formats to Go time format
 	goFormat := tp.golem.convertToGoTimeFormat(format)
-	return time.Now().Format(goFormat)
+	return formatLocalizedLayout(now, goFormat, locale)
 }
 
 func (tp *TreeProcessor) processTimeTag(node *ASTNode, content string) string {
 	// Time tag - current time
 	tp.trackMetric("data") // Track data processor usage
+	now, locale := tp.golem.localizedNow(node.Attributes, tp.ctx.Session)
+	if jformat := node.Attributes["jformat"]; jformat != "" {
+		return formatJavaDate(now, jformat, locale)
+	}
 	defaultFormat := "3:04 PM"
 	format := defaultFormat
 	if f, exists := node.Attributes["format"]; exists {
@@ -2740,7 +2888,41 @@ func (tp *TreeProcessor) processTimeTag(node *ASTNode, content string) string {
 		goFormat = defaultFormat
 	}
 
-	return time.Now().Format(goFormat)
+	return formatLocalizedLayout(now, goFormat, locale)
+}
+
+// processIntervalTag computes the time between <from> and <to> in the unit named by <style>
+func (tp *TreeProcessor) processIntervalTag(node *ASTNode, content string) string {
+	tp.trackMetric("data") // Track data processor usage
+
+	// Children may also be given as attributes
+	parts := map[string]string{}
+	for _, name := range []string{"jformat", "style", "from", "to"} {
+		if value, exists := node.Attributes[name]; exists {
+			parts[name] = value
+		}
+	}
+	for _, child := range node.Children {
+		if child.Type != NodeTypeTag && child.Type != NodeTypeSelfClosingTag {
+			continue
+		}
+		switch child.TagName {
+		case "jformat", "style", "from", "to":
+			var value strings.Builder
+			for _, grandchild := range child.Children {
+				value.WriteString(tp.processNode(grandchild))
+			}
+			parts[child.TagName] = strings.TrimSpace(value.String())
+		}
+	}
+
+	now, _ := tp.golem.localizedNow(node.Attributes, tp.ctx.Session)
+	result, err := tp.golem.computeInterval(parts["jformat"], parts["style"], parts["from"], parts["to"], now)
+	if err != nil {
+		tp.golem.LogWarn("Interval: %v", err)
+		return "unknown"
+	}
+	return result
 }
 
 // System tags
@@ -2945,8 +3127,10 @@ func (tp *TreeProcessor) processUniqTag(node *ASTNode, content string) string {
 }
 
 // Helper method for random number generation
-func (g *Golem) randomIntTree(max int) int {
-	// This would use the existing random number generation from the Golem instance
-	// For now, return a simple implementation
-	return int(time.Now().UnixNano() % int64(max))
+func (g *Golem) randomIntTree(session *ChatSession, max int) int {
+	// A session or instance random source makes selection reproducible
+	if source := g.randomSourceFor(session); source != nil {
+		return source.Intn(max)
+	}
+	return int(g.now().UnixNano() % int64(max))
 }
@@ -0,0 +1,184 @@
+package golem
+
+import (
+	"strings"
+	"unicode"
+)
+
+// UnicodeNormalization configures the Unicode stage applied to patterns when AIML is loaded and
+// to user input before matching. <star/> and <input/> still see the text the user typed.
+type UnicodeNormalization struct {
+	// Compatibility maps compatibility characters to their standard forms, like NFKC: full-width
+	// letters, ligatures and special spaces, and combining accents joined to their letter
+	Compatibility bool
+	// FoldCase applies full case folding, so "straße" matches STRASSE
+	FoldCase bool
+	// StripDiacritics removes accents, so "café" matches CAFE
+	StripDiacritics bool
+}
+
+// DefaultUnicodeNormalization returns the recommended stage: compatibility forms and case folding,
+// keeping diacritics. Bots normalize nothing unless a stage is set with WithUnicodeNormalization.
+func DefaultUnicodeNormalization() UnicodeNormalization {
+	return UnicodeNormalization{Compatibility: true, FoldCase: true}
+}
+
+// WithUnicodeNormalization sets the Unicode normalization stage
+func WithUnicodeNormalization(normalization UnicodeNormalization) Option {
+	return func(g *Golem) {
+		g.SetUnicodeNormalization(normalization)
+	}
+}
+
+// SetUnicodeNormalization sets the Unicode normalization stage. Patterns are normalized when they
+// are loaded, so set it before loading AIML.
+func (g *Golem) SetUnicodeNormalization(normalization UnicodeNormalization) {
+	g.unicodeNormalization = normalization
+}
+
+// GetUnicodeNormalization returns the Unicode normalization stage
+func (g *Golem) GetUnicodeNormalization() UnicodeNormalization {
+	return g.unicodeNormalization
+}
+
+// normalizeUnicode applies the configured Unicode stage to a pattern or to user input
+func (g *Golem) normalizeUnicode(text string) string {
+	if g == nil {
+		return text
+	}
+	return g.unicodeNormalization.Apply(text)
+}
+
+// Apply normalizes text with the enabled steps
+func (n UnicodeNormalization) Apply(text string) string {
+	if isASCII(text) {
+		return text
+	}
+	if n.Compatibility {
+		text = mapCompatibilityForms(text)
+	}
+	if n.StripDiacritics {
+		text = stripDiacritics(text)
+	} else if n.Compatibility {
+		text = composeLatin(text)
+	}
+	if n.FoldCase {
+		text = foldSpecialCases(text)
+	}
+	return text
+}
+
+// isASCII reports whether text only has ASCII characters, which no step changes
+func isASCII(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// compatibilityExpansions are compatibility characters that map to several characters
+var compatibilityExpansions = map[rune]string{
+	'ﬀ': "ff", 'ﬁ': "fi", 'ﬂ': "fl", 'ﬃ': "ffi", 'ﬄ': "ffl", 'ﬅ': "st", 'ﬆ': "st",
+	'Ĳ': "IJ", 'ĳ': "ij", 'Ŀ': "L·", 'ŀ': "l·", 'ŉ': "ʼn",
+	'…': "...", '‥': "..", '¼': "1/4", '½': "1/2", '¾': "3/4",
+	'™': "TM", '℠': "SM", '№': "No",
+}
+
+// compatibilityRunes are compatibility characters that map to a single character
+var compatibilityRunes = map[rune]rune{
+	'ſ': 's', 'µ': 'μ', 'ª': 'a', 'º': 'o',
+	'¹': '1', '²': '2', '³': '3', '⁰': '0', '⁴': '4', '⁵': '5', '⁶': '6', '⁷': '7', '⁸': '8', '⁹': '9',
+	'₀': '0', '₁': '1', '₂': '2', '₃': '3', '₄': '4', '₅': '5', '₆': '6', '₇': '7', '₈': '8', '₉': '9',
+}
+
+// mapCompatibilityForms replaces full-width forms, ligatures, special spaces and other
+// compatibility characters with their standard equivalents
+func mapCompatibilityForms(text string) string {
+	var result strings.Builder
+	result.Grow(len(text))
+	for _, r := range text {
+		switch {
+		case r >= 0xFF01 && r <= 0xFF5E:
+			// Full-width ASCII
+			result.WriteRune(r - 0xFEE0)
+		case r == 0x3000 || r == 0x00A0 || r == 0x202F || r == 0x205F || (r >= 0x2000 && r <= 0x200A):
+			// Ideographic, no-break and typographic spaces
+			result.WriteByte(' ')
+		default:
+			if expansion, exists := compatibilityExpansions[r]; exists {
+				result.WriteString(expansion)
+			} else if mapped, exists := compatibilityRunes[r]; exists {
+				result.WriteRune(mapped)
+			} else {
+				result.WriteRune(r)
+			}
+		}
+	}
+	return result.String()
+}
+
+// latinCompositions is the reverse of latinDecompositions
+var latinCompositions = func() map[[2]rune]rune {
+	compositions := make(map[[2]rune]rune, len(latinDecompositions))
+	for composed, parts := range latinDecompositions {
+		compositions[parts] = composed
+	}
+	return compositions
+}()
+
+// composeLatin joins a letter and the combining marks after it into one precomposed letter where
+// one exists, so "e" + U+0301 matches "é"
+func composeLatin(text string) string {
+	runes := []rune(text)
+	result := make([]rune, 0, len(runes))
+	for _, r := range runes {
+		if unicode.Is(unicode.Mn, r) && len(result) > 0 {
+			if composed, exists := latinCompositions[[2]rune{result[len(result)-1], r}]; exists {
+				result[len(result)-1] = composed
+				continue
+			}
+		}
+		result = append(result, r)
+	}
+	return string(result)
+}
+
+// diacriticFreeLetters are letters whose accents are not combining marks
+var diacriticFreeLetters = map[rune]string{
+	'Ø': "O", 'ø': "o", 'Đ': "D", 'đ': "d", 'Ł': "L", 'ł': "l", 'Ħ': "H", 'ħ': "h",
+	'Æ': "AE", 'æ': "ae", 'Œ': "OE", 'œ': "oe", 'ı': "i",
+}
+
+// stripDiacritics removes accents and other combining marks from letters
+func stripDiacritics(text string) string {
+	var result strings.Builder
+	result.Grow(len(text))
+	for _, r := range text {
+		for {
+			parts, exists := latinDecompositions[r]
+			if !exists {
+				break
+			}
+			r = parts[0]
+		}
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if replacement, exists := diacriticFreeLetters[r]; exists {
+			result.WriteString(replacement)
+			continue
+		}
+		result.WriteRune(r)
+	}
+	return result.String()
+}
+
+// foldSpecialCases applies the case foldings that upper-casing alone misses
+func foldSpecialCases(text string) string {
+	if !strings.ContainsAny(text, "ßẞſς") {
+		return text
+	}
+	return strings.NewReplacer("ß", "ss", "ẞ", "SS", "ſ", "s", "ς", "σ").Replace(text)
+}
@@ -0,0 +1,112 @@
+package golem
+
+import "testing"
+
+const unicodeTestAIML = `<aiml version="2.0">
+<category><pattern>CAFE</pattern><template>Coffee</template></category>
+<category><pattern>CRÈME BRÛLÉE</pattern><template>Dessert</template></category>
+<category><pattern>STRASSE</pattern><template>Street</template></category>
+<category><pattern>I LIKE *</pattern><template>You like <star/>.</template></category>
+<category><pattern>I LIVE IN <set>cities</set></pattern><template>Welcome to <star/>.</template></category>
+</aiml>`
+
+func TestUnicodeNormalizationDefaults(t *testing.T) {
+	g := newTestBot(t, unicodeTestAIML, WithUnicodeNormalization(DefaultUnicodeNormalization()))
+	g.aimlKB.Sets["CITIES"] = []string{"São Paulo", "Zürich"}
+	session := g.CreateSession("unicode")
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		// Full-width letters and decomposed accents are compatibility forms
+		{"ｃａｆｅ", "Coffee"},
+		{"cre\u0300me bru\u0302le\u0301e", "Dessert"},
+		{"crème brûlée", "Dessert"},
+		{"straße", "Street"},
+		// Diacritics are kept by default
+		{"I like café", "You like café."},
+		{"I live in zürich", "Welcome to zürich."},
+	}
+	for _, tc := range testCases {
+		if got := askN(t, g, session, tc.input, 1)[0]; got != tc.expected {
+			t.Errorf("%q: got %q, expected %q", tc.input, got, tc.expected)
+		}
+	}
+}
+
+func TestUnicodeNormalizationThatAndTopic(t *testing.T) {
+	aiml := "<aiml version=\"2.0\">" +
+		"<category><pattern>DESSERT</pattern><template>Would you like cre\u0300me bru\u0302le\u0301e?</template></category>" +
+		"<category><pattern>YES</pattern><that>WOULD YOU LIKE CRÈME BRÛLÉE</that><template>Coming up.</template></category>" +
+		"<category><pattern>WALK</pattern><template><think><set name=\"topic\">straße</set></think>Which way?</template></category>" +
+		"<category><pattern>LEFT</pattern><topic>STRAẞE</topic><template>Down the street.</template></category>" +
+		"</aiml>"
+	g := newTestBot(t, aiml, WithUnicodeNormalization(DefaultUnicodeNormalization()))
+	session := g.CreateSession("unicode-context")
+
+	got := askN(t, g, session, "dessert", 2)
+	if got[0] != "Would you like cre\u0300me bru\u0302le\u0301e?" {
+		t.Fatalf("Unexpected response %q", got[0])
+	}
+	if got := askN(t, g, session, "yes", 1)[0]; got != "Coming up." {
+		t.Errorf("Expected the that pattern to match the decomposed response, got %q", got)
+	}
+	askN(t, g, session, "walk", 1)
+	if got := askN(t, g, session, "left", 1)[0]; got != "Down the street." {
+		t.Errorf("Expected the topic to match after case folding, got %q", got)
+	}
+}
+
+func TestUnicodeNormalizationIsOptIn(t *testing.T) {
+	if got := New(false).GetUnicodeNormalization(); got != (UnicodeNormalization{}) {
+		t.Errorf("Expected no Unicode stage by default, got %+v", got)
+	}
+}
+
+func TestUnicodeNormalizationStripDiacritics(t *testing.T) {
+	g := newTestBot(t, unicodeTestAIML, WithUnicodeNormalization(UnicodeNormalization{Compatibility: true, FoldCase: true, StripDiacritics: true}))
+	g.aimlKB.Sets["CITIES"] = []string{"São Paulo", "Zürich"}
+	session := g.CreateSession("unicode")
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"café", "Coffee"},
+		{"CAFÉ", "Coffee"},
+		{"creme brulee", "Dessert"},
+		{"Crème Brûlée", "Dessert"},
+		// Wildcards and set members keep what the user typed
+		{"I like Crème Fraîche", "You like Crème Fraîche."},
+		{"I live in Sao Paulo", "Welcome to Sao Paulo."},
+		{"I live in São Paulo", "Welcome to São Paulo."},
+	}
+	for _, tc := range testCases {
+		if got := askN(t, g, session, tc.input, 1)[0]; got != tc.expected {
+			t.Errorf("%q: got %q, expected %q", tc.input, got, tc.expected)
+		}
+	}
+}
+
+func TestUnicodeNormalizationApply(t *testing.T) {
+	strip := UnicodeNormalization{Compatibility: true, FoldCase: true, StripDiacritics: true}
+	testCases := []struct {
+		normalization UnicodeNormalization
+		input         string
+		expected      string
+	}{
+		{DefaultUnicodeNormalization(), "Ｈｅｌｌｏ　ｗｏｒｌｄ！", "Hello world!"},
+		{DefaultUnicodeNormalization(), "ﬁne ﬂour", "fine flour"},
+		{DefaultUnicodeNormalization(), "Ǖ", "Ǖ"},
+		{DefaultUnicodeNormalization(), "U\u0308\u0304", "Ǖ"},
+		{strip, "Ǖ Ørsted Łódź Æsir", "U Orsted Lodz AEsir"},
+		{strip, "Việt Nam", "Viet Nam"},
+		{UnicodeNormalization{}, "ｃａｆé", "ｃａｆé"},
+	}
+	for _, tc := range testCases {
+		if got := tc.normalization.Apply(tc.input); got != tc.expected {
+			t.Errorf("%+v.Apply(%q) = %q, expected %q", tc.normalization, tc.input, got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package golem
+
+// latinDecompositions maps precomposed Latin letters to their base letter and combining mark
+// (Latin-1 Supplement, Latin Extended-A and B, and Latin Extended Additional). Letters such as
+// U+01D6 decompose to another precomposed letter, so lookups may need to be repeated.
+var latinDecompositions = map[rune][2]rune{
+	'À': {'A', 0x0300}, 'Á': {'A', 0x0301}, 'Â': {'A', 0x0302}, 'Ã': {'A', 0x0303}, 'Ä': {'A', 0x0308}, 'Å': {'A', 0x030A},
+	'Ç': {'C', 0x0327}, 'È': {'E', 0x0300}, 'É': {'E', 0x0301}, 'Ê': {'E', 0x0302}, 'Ë': {'E', 0x0308}, 'Ì': {'I', 0x0300},
+	'Í': {'I', 0x0301}, 'Î': {'I', 0x0302}, 'Ï': {'I', 0x0308}, 'Ñ': {'N', 0x0303}, 'Ò': {'O', 0x0300}, 'Ó': {'O', 0x0301},
+	'Ô': {'O', 0x0302}, 'Õ': {'O', 0x0303}, 'Ö': {'O', 0x0308}, 'Ù': {'U', 0x0300}, 'Ú': {'U', 0x0301}, 'Û': {'U', 0x0302},
+	'Ü': {'U', 0x0308}, 'Ý': {'Y', 0x0301}, 'à': {'a', 0x0300}, 'á': {'a', 0x0301}, 'â': {'a', 0x0302}, 'ã': {'a', 0x0303},
+	'ä': {'a', 0x0308}, 'å': {'a', 0x030A}, 'ç': {'c', 0x0327}, 'è': {'e', 0x0300}, 'é': {'e', 0x0301}, 'ê': {'e', 0x0302},
+	'ë': {'e', 0x0308}, 'ì': {'i', 0x0300}, 'í': {'i', 0x0301}, 'î': {'i', 0x0302}, 'ï': {'i', 0x0308}, 'ñ': {'n', 0x0303},
+	'ò': {'o', 0x0300}, 'ó': {'o', 0x0301}, 'ô': {'o', 0x0302}, 'õ': {'o', 0x0303}, 'ö': {'o', 0x0308}, 'ù': {'u', 0x0300},
+	'ú': {'u', 0x0301}, 'û': {'u', 0x0302}, 'ü': {'u', 0x0308}, 'ý': {'y', 0x0301}, 'ÿ': {'y', 0x0308}, 'Ā': {'A', 0x0304},
+	'ā': {'a', 0x0304}, 'Ă': {'A', 0x0306}, 'ă': {'a', 0x0306}, 'Ą': {'A', 0x0328}, 'ą': {'a', 0x0328}, 'Ć': {'C', 0x0301},
+	'ć': {'c', 0x0301}, 'Ĉ': {'C', 0x0302}, 'ĉ': {'c', 0x0302}, 'Ċ': {'C', 0x0307}, 'ċ': {'c', 0x0307}, 'Č': {'C', 0x030C},
+	'č': {'c', 0x030C}, 'Ď': {'D', 0x030C}, 'ď': {'d', 0x030C}, 'Ē': {'E', 0x0304}, 'ē': {'e', 0x0304}, 'Ĕ': {'E', 0x0306},
+	'ĕ': {'e', 0x0306}, 'Ė': {'E', 0x0307}, 'ė': {'e', 0x0307}, 'Ę': {'E', 0x0328}, 'ę': {'e', 0x0328}, 'Ě': {'E', 0x030C},
+	'ě': {'e', 0x030C}, 'Ĝ': {'G', 0x0302}, 'ĝ': {'g', 0x0302}, 'Ğ': {'G', 0x0306}, 'ğ': {'g', 0x0306}, 'Ġ': {'G', 0x0307},
+	'ġ': {'g', 0x0307}, 'Ģ': {'G', 0x0327}, 'ģ': {'g', 0x0327}, 'Ĥ': {'H', 0x0302}, 'ĥ': {'h', 0x0302}, 'Ĩ': {'I', 0x0303},
+	'ĩ': {'i', 0x0303}, 'Ī': {'I', 0x0304}, 'ī': {'i', 0x0304}, 'Ĭ': {'I', 0x0306}, 'ĭ': {'i', 0x0306}, 'Į': {'I', 0x0328},
+	'į': {'i', 0x0328}, 'İ': {'I', 0x0307}, 'Ĵ': {'J', 0x0302}, 'ĵ': {'j', 0x0302}, 'Ķ': {'K', 0x0327}, 'ķ': {'k', 0x0327},
+	'Ĺ': {'L', 0x0301}, 'ĺ': {'l', 0x0301}, 'Ļ': {'L', 0x0327}, 'ļ': {'l', 0x0327}, 'Ľ': {'L', 0x030C}, 'ľ': {'l', 0x030C},
+	'Ń': {'N', 0x0301}, 'ń': {'n', 0x0301}, 'Ņ': {'N', 0x0327}, 'ņ': {'n', 0x0327}, 'Ň': {'N', 0x030C}, 'ň': {'n', 0x030C},
+	'Ō': {'O', 0x0304}, 'ō': {'o', 0x0304}, 'Ŏ': {'O', 0x0306}, 'ŏ': {'o', 0x0306}, 'Ő': {'O', 0x030B}, 'ő': {'o', 0x030B},
+	'Ŕ': {'R', 0x0301}, 'ŕ': {'r', 0x0301}, 'Ŗ': {'R', 0x0327}, 'ŗ': {'r', 0x0327}, 'Ř': {'R', 0x030C}, 'ř': {'r', 0x030C},
+	'Ś': {'S', 0x0301}, 'ś': {'s', 0x0301}, 'Ŝ': {'S', 0x0302}, 'ŝ': {'s', 0x0302}, 'Ş': {'S', 0x0327}, 'ş': {'s', 0x0327},
+	'Š': {'S', 0x030C}, 'š': {'s', 0x030C}, 'Ţ': {'T', 0x0327}, 'ţ': {'t', 0x0327}, 'Ť': {'T', 0x030C}, 'ť': {'t', 0x030C},
+	'Ũ': {'U', 0x0303}, 'ũ': {'u', 0x0303}, 'Ū': {'U', 0x0304}, 'ū': {'u', 0x0304}, 'Ŭ': {'U', 0x0306}, 'ŭ': {'u', 0x0306},
+	'Ů': {'U', 0x030A}, 'ů': {'u', 0x030A}, 'Ű': {'U', 0x030B}, 'ű': {'u', 0x030B}, 'Ų': {'U', 0x0328}, 'ų': {'u', 0x0328},
+	'Ŵ': {'W', 0x0302}, 'ŵ': {'w', 0x0302}, 'Ŷ': {'Y', 0x0302}, 'ŷ': {'y', 0x0302}, 'Ÿ': {'Y', 0x0308}, 'Ź': {'Z', 0x0301},
+	'ź': {'z', 0x0301}, 'Ż': {'Z', 0x0307}, 'ż': {'z', 0x0307}, 'Ž': {'Z', 0x030C}, 'ž': {'z', 0x030C}, 'Ơ': {'O', 0x031B},
+	'ơ': {'o', 0x031B}, 'Ư': {'U', 0x031B}, 'ư': {'u', 0x031B}, 'Ǎ': {'A', 0x030C}, 'ǎ': {'a', 0x030C}, 'Ǐ': {'I', 0x030C},
+	'ǐ': {'i', 0x030C}, 'Ǒ': {'O', 0x030C}, 'ǒ': {'o', 0x030C}, 'Ǔ': {'U', 0x030C}, 'ǔ': {'u', 0x030C}, 'Ǖ': {'Ü', 0x0304},
+	'ǖ': {'ü', 0x0304}, 'Ǘ': {'Ü', 0x0301}, 'ǘ': {'ü', 0x0301}, 'Ǚ': {'Ü', 0x030C}, 'ǚ': {'ü', 0x030C}, 'Ǜ': {'Ü', 0x0300},
+	'ǜ': {'ü', 0x0300}, 'Ǟ': {'Ä', 0x0304}, 'ǟ': {'ä', 0x0304}, 'Ǡ': {'Ȧ', 0x0304}, 'ǡ': {'ȧ', 0x0304}, 'Ǣ': {'Æ', 0x0304},
+	'ǣ': {'æ', 0x0304}, 'Ǧ': {'G', 0x030C}, 'ǧ': {'g', 0x030C}, 'Ǩ': {'K', 0x030C}, 'ǩ': {'k', 0x030C}, 'Ǫ': {'O', 0x0328},
+	'ǫ': {'o', 0x0328}, 'Ǭ': {'Ǫ', 0x0304}, 'ǭ': {'ǫ', 0x0304}, 'Ǯ': {'Ʒ', 0x030C}, 'ǯ': {'ʒ', 0x030C}, 'ǰ': {'j', 0x030C},
+	'Ǵ': {'G', 0x0301}, 'ǵ': {'g', 0x0301}, 'Ǹ': {'N', 0x0300}, 'ǹ': {'n', 0x0300}, 'Ǻ': {'Å', 0x0301}, 'ǻ': {'å', 0x0301},
+	'Ǽ': {'Æ', 0x0301}, 'ǽ': {'æ', 0x0301}, 'Ǿ': {'Ø', 0x0301}, 'ǿ': {'ø', 0x0301}, 'Ȁ': {'A', 0x030F}, 'ȁ': {'a', 0x030F},
+	'Ȃ': {'A', 0x0311}, 'ȃ': {'a', 0x0311}, 'Ȅ': {'E', 0x030F}, 'ȅ': {'e', 0x030F}, 'Ȇ': {'E', 0x0311}, 'ȇ': {'e', 0x0311},
+	'Ȉ': {'I', 0x030F}, 'ȉ': {'i', 0x030F}, 'Ȋ': {'I', 0x0311}, 'ȋ': {'i', 0x0311}, 'Ȍ': {'O', 0x030F}, 'ȍ': {'o', 0x030F},
+	'Ȏ': {'O', 0x0311}, 'ȏ': {'o', 0x0311}, 'Ȑ': {'R', 0x030F}, 'ȑ': {'r', 0x030F}, 'Ȓ': {'R', 0x0311}, 'ȓ': {'r', 0x0311},
+	'Ȕ': {'U', 0x030F}, 'ȕ': {'u', 0x030F}, 'Ȗ': {'U', 0x0311}, 'ȗ': {'u', 0x0311}, 'Ș': {'S', 0x0326}, 'ș': {'s', 0x0326},
+	'Ț': {'T', 0x0326}, 'ț': {'t', 0x0326}, 'Ȟ': {'H', 0x030C}, 'ȟ': {'h', 0x030C}, 'Ȧ': {'A', 0x0307}, 'ȧ': {'a', 0x0307},
+	'Ȩ': {'E', 0x0327}, 'ȩ': {'e', 0x0327}, 'Ȫ': {'Ö', 0x0304}, 'ȫ': {'ö', 0x0304}, 'Ȭ': {'Õ', 0x0304}, 'ȭ': {'õ', 0x0304},
+	'Ȯ': {'O', 0x0307}, 'ȯ': {'o', 0x0307}, 'Ȱ': {'Ȯ', 0x0304}, 'ȱ': {'ȯ', 0x0304}, 'Ȳ': {'Y', 0x0304}, 'ȳ': {'y', 0x0304},
+	'Ḁ': {'A', 0x0325}, 'ḁ': {'a', 0x0325}, 'Ḃ': {'B', 0x0307}, 'ḃ': {'b', 0x0307}, 'Ḅ': {'B', 0x0323}, 'ḅ': {'b', 0x0323},
+	'Ḇ': {'B', 0x0331}, 'ḇ': {'b', 0x0331}, 'Ḉ': {'Ç', 0x0301}, 'ḉ': {'ç', 0x0301}, 'Ḋ': {'D', 0x0307}, 'ḋ': {'d', 0x0307},
+	'Ḍ': {'D', 0x0323}, 'ḍ': {'d', 0x0323}, 'Ḏ': {'D', 0x0331}, 'ḏ': {'d', 0x0331}, 'Ḑ': {'D', 0x0327}, 'ḑ': {'d', 0x0327},
+	'Ḓ': {'D', 0x032D}, 'ḓ': {'d', 0x032D}, 'Ḕ': {'Ē', 0x0300}, 'ḕ': {'ē', 0x0300}, 'Ḗ': {'Ē', 0x0301}, 'ḗ': {'ē', 0x0301},
+	'Ḙ': {'E', 0x032D}, 'ḙ': {'e', 0x032D}, 'Ḛ': {'E', 0x0330}, 'ḛ': {'e', 0x0330}, 'Ḝ': {'Ȩ', 0x0306}, 'ḝ': {'ȩ', 0x0306},
+	'Ḟ': {'F', 0x0307}, 'ḟ': {'f', 0x0307}, 'Ḡ': {'G', 0x0304}, 'ḡ': {'g', 0x0304}, 'Ḣ': {'H', 0x0307}, 'ḣ': {'h', 0x0307},
+	'Ḥ': {'H', 0x0323}, 'ḥ': {'h', 0x0323}, 'Ḧ': {'H', 0x0308}, 'ḧ': {'h', 0x0308}, 'Ḩ': {'H', 0x0327}, 'ḩ': {'h', 0x0327},
+	'Ḫ': {'H', 0x032E}, 'ḫ': {'h', 0x032E}, 'Ḭ': {'I', 0x0330}, 'ḭ': {'i', 0x0330}, 'Ḯ': {'Ï', 0x0301}, 'ḯ': {'ï', 0x0301},
+	'Ḱ': {'K', 0x0301}, 'ḱ': {'k', 0x0301}, 'Ḳ': {'K', 0x0323}, 'ḳ': {'k', 0x0323}, 'Ḵ': {'K', 0x0331}, 'ḵ': {'k', 0x0331},
+	'Ḷ': {'L', 0x0323}, 'ḷ': {'l', 0x0323}, 'Ḹ': {'Ḷ', 0x0304}, 'ḹ': {'ḷ', 0x0304}, 'Ḻ': {'L', 0x0331}, 'ḻ': {'l', 0x0331},
+	'Ḽ': {'L', 0x032D}, 'ḽ': {'l', 0x032D}, 'Ḿ': {'M', 0x0301}, 'ḿ': {'m', 0x0301}, 'Ṁ': {'M', 0x0307}, 'ṁ': {'m', 0x0307},
+	'Ṃ': {'M', 0x0323}, 'ṃ': {'m', 0x0323}, 'Ṅ': {'N', 0x0307}, 'ṅ': {'n', 0x0307}, 'Ṇ': {'N', 0x0323}, 'ṇ': {'n', 0x0323},
+	'Ṉ': {'N', 0x0331}, 'ṉ': {'n', 0x0331}, 'Ṋ': {'N', 0x032D}, 'ṋ': {'n', 0x032D}, 'Ṍ': {'Õ', 0x0301}, 'ṍ': {'õ', 0x0301},
+	'Ṏ': {'Õ', 0x0308}, 'ṏ': {'õ', 0x0308}, 'Ṑ': {'Ō', 0x0300}, 'ṑ': {'ō', 0x0300}, 'Ṓ': {'Ō', 0x0301}, 'ṓ': {'ō', 0x0301},
+	'Ṕ': {'P', 0x0301}, 'ṕ': {'p', 0x0301}, 'Ṗ': {'P', 0x0307}, 'ṗ': {'p', 0x0307}, 'Ṙ': {'R', 0x0307}, 'ṙ': {'r', 0x0307},
+	'Ṛ': {'R', 0x0323}, 'ṛ': {'r', 0x0323}, 'Ṝ': {'Ṛ', 0x0304}, 'ṝ': {'ṛ', 0x0304}, 'Ṟ': {'R', 0x0331}, 'ṟ': {'r', 0x0331},
+	'Ṡ': {'S', 0x0307}, 'ṡ': {'s', 0x0307}, 'Ṣ': {'S', 0x0323}, 'ṣ': {'s', 0x0323}, 'Ṥ': {'Ś', 0x0307}, 'ṥ': {'ś', 0x0307},
+	'Ṧ': {'Š', 0x0307}, 'ṧ': {'š', 0x0307}, 'Ṩ': {'Ṣ', 0x0307}, 'ṩ': {'ṣ', 0x0307}, 'Ṫ': {'T', 0x0307}, 'ṫ': {'t', 0x0307},
+	'Ṭ': {'T', 0x0323}, 'ṭ': {'t', 0x0323}, 'Ṯ': {'T', 0x0331}, 'ṯ': {'t', 0x0331}, 'Ṱ': {'T', 0x032D}, 'ṱ': {'t', 0x032D},
+	'Ṳ': {'U', 0x0324}, 'ṳ': {'u', 0x0324}, 'Ṵ': {'U', 0x0330}, 'ṵ': {'u', 0x0330}, 'Ṷ': {'U', 0x032D}, 'ṷ': {'u', 0x032D},
+	'Ṹ': {'Ũ', 0x0301}, 'ṹ': {'ũ', 0x0301}, 'Ṻ': {'Ū', 0x0308}, 'ṻ': {'ū', 0x0308}, 'Ṽ': {'V', 0x0303}, 'ṽ': {'v', 0x0303},
+	'Ṿ': {'V', 0x0323}, 'ṿ': {'v', 0x0323}, 'Ẁ': {'W', 0x0300}, 'ẁ': {'w', 0x0300}, 'Ẃ': {'W', 0x0301}, 'ẃ': {'w', 0x0301},
+	'Ẅ': {'W', 0x0308}, 'ẅ': {'w', 0x0308}, 'Ẇ': {'W', 0x0307}, 'ẇ': {'w', 0x0307}, 'Ẉ': {'W', 0x0323}, 'ẉ': {'w', 0x0323},
+	'Ẋ': {'X', 0x0307}, 'ẋ': {'x', 0x0307}, 'Ẍ': {'X', 0x0308}, 'ẍ': {'x', 0x0308}, 'Ẏ': {'Y', 0x0307}, 'ẏ': {'y', 0x0307},
+	'Ẑ': {'Z', 0x0302}, 'ẑ': {'z', 0x0302}, 'Ẓ': {'Z', 0x0323}, 'ẓ': {'z', 0x0323}, 'Ẕ': {'Z', 0x0331}, 'ẕ': {'z', 0x0331},
+	'ẖ': {'h', 0x0331}, 'ẗ': {'t', 0x0308}, 'ẘ': {'w', 0x030A}, 'ẙ': {'y', 0x030A}, 'ẛ': {'ſ', 0x0307}, 'Ạ': {'A', 0x0323},
+	'ạ': {'a', 0x0323}, 'Ả': {'A', 0x0309}, 'ả': {'a', 0x0309}, 'Ấ': {'Â', 0x0301}, 'ấ': {'â', 0x0301}, 'Ầ': {'Â', 0x0300},
+	'ầ': {'â', 0x0300}, 'Ẩ': {'Â', 0x0309}, 'ẩ': {'â', 0x0309}, 'Ẫ': {'Â', 0x0303}, 'ẫ': {'â', 0x0303}, 'Ậ': {'Ạ', 0x0302},
+	'ậ': {'ạ', 0x0302}, 'Ắ': {'Ă', 0x0301}, 'ắ': {'ă', 0x0301}, 'Ằ': {'Ă', 0x0300}, 'ằ': {'ă', 0x0300}, 'Ẳ': {'Ă', 0x0309},
+	'ẳ': {'ă', 0x0309}, 'Ẵ': {'Ă', 0x0303}, 'ẵ': {'ă', 0x0303}, 'Ặ': {'Ạ', 0x0306}, 'ặ': {'ạ', 0x0306}, 'Ẹ': {'E', 0x0323},
+	'ẹ': {'e', 0x0323}, 'Ẻ': {'E', 0x0309}, 'ẻ': {'e', 0x0309}, 'Ẽ': {'E', 0x0303}, 'ẽ': {'e', 0x0303}, 'Ế': {'Ê', 0x0301},
+	'ế': {'ê', 0x0301}, 'Ề': {'Ê', 0x0300}, 'ề': {'ê', 0x0300}, 'Ể': {'Ê', 0x0309}, 'ể': {'ê', 0x0309}, 'Ễ': {'Ê', 0x0303},
+	'ễ': {'ê', 0x0303}, 'Ệ': {'Ẹ', 0x0302}, 'ệ': {'ẹ', 0x0302}, 'Ỉ': {'I', 0x0309}, 'ỉ': {'i', 0x0309}, 'Ị': {'I', 0x0323},
+	'ị': {'i', 0x0323}, 'Ọ': {'O', 0x0323}, 'ọ': {'o', 0x0323}, 'Ỏ': {'O', 0x0309}, 'ỏ': {'o', 0x0309}, 'Ố': {'Ô', 0x0301},
+	'ố': {'ô', 0x0301}, 'Ồ': {'Ô', 0x0300}, 'ồ': {'ô', 0x0300}, 'Ổ': {'Ô', 0x0309}, 'ổ': {'ô', 0x0309}, 'Ỗ': {'Ô', 0x0303},
+	'ỗ': {'ô', 0x0303}, 'Ộ': {'Ọ', 0x0302}, 'ộ': {'ọ', 0x0302}, 'Ớ': {'Ơ', 0x0301}, 'ớ': {'ơ', 0x0301}, 'Ờ': {'Ơ', 0x0300},
+	'ờ': {'ơ', 0x0300}, 'Ở': {'Ơ', 0x0309}, 'ở': {'ơ', 0x0309}, 'Ỡ': {'Ơ', 0x0303}, 'ỡ': {'ơ', 0x0303}, 'Ợ': {'Ơ', 0x0323},
+	'ợ': {'ơ', 0x0323}, 'Ụ': {'U', 0x0323}, 'ụ': {'u', 0x0323}, 'Ủ': {'U', 0x0309}, 'ủ': {'u', 0x0309}, 'Ứ': {'Ư', 0x0301},
+	'ứ': {'ư', 0x0301}, 'Ừ': {'Ư', 0x0300}, 'ừ': {'ư', 0x0300}, 'Ử': {'Ư', 0x0309}, 'ử': {'ư', 0x0309}, 'Ữ': {'Ư', 0x0303},
+	'ữ': {'ư', 0x0303}, 'Ự': {'Ư', 0x0323}, 'ự': {'ư', 0x0323}, 'Ỳ': {'Y', 0x0300}, 'ỳ': {'y', 0x0300}, 'Ỵ': {'Y', 0x0323},
+	'ỵ': {'y', 0x0323}, 'Ỷ': {'Y', 0x0309}, 'ỷ': {'y', 0x0309}, 'Ỹ': {'Y', 0x0303}, 'ỹ': {'y', 0x0303},
+}
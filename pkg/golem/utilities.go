@@ -2,7 +2,6 @@ package golem
 
 import (
 	"fmt"
-	"math/rand"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -77,12 +76,12 @@ func (u *Utilities) RandomInt(max int) int {
 	if max <= 0 {
 		return 0
 	}
-	return rand.Intn(max)
+	return u.golem.randomIntn(nil, max)
 }
 
 // FormatDate formats a date according to the given format
 func (u *Utilities) FormatDate(format string) string {
-	now := time.Now()
+	now := u.golem.now()
 
 	if format == "" {
 		format = "January 2, 2006"
@@ -96,7 +95,7 @@ func (u *Utilities) FormatDate(format string) string {
 
 // FormatTime formats a time according to the given format
 func (u *Utilities) FormatTime(format string) string {
-	now := time.Now()
+	now := u.golem.now()
 
 	if format == "" {
 		format = "3:04 PM"